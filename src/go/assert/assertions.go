@@ -2,8 +2,13 @@ package assert
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
 )
 
 type Reporter interface {
@@ -66,3 +71,153 @@ func buildEqualsError(expected, actual any, msg string) error {
 	}
 	return fmt.Errorf("%s", reason)
 }
+
+func NodeEquals(expected, actual ast.Node, r Reporter) bool {
+	return NodeEqualsWithMessage(expected, actual, "", r)
+}
+
+func NodeEqualsWithMessage(expected, actual ast.Node, msg string, r Reporter) bool {
+	if tr, ok := r.(*TestReporter); ok {
+		tr.harness.Helper()
+	}
+
+	diffs := ast.Diff(expected, actual)
+	if len(diffs) != 0 {
+		reasons := []string{}
+		for _, d := range diffs {
+			reasons = append(reasons, d.String())
+		}
+		r.Report(buildDiffError(reasons, msg))
+		return false
+	}
+
+	return true
+}
+
+func ObjectEquals(expected, actual object.Object, r Reporter) bool {
+	return ObjectEqualsWithMessage(expected, actual, "", r)
+}
+
+func ObjectEqualsWithMessage(expected, actual object.Object, msg string, r Reporter) bool {
+	if tr, ok := r.(*TestReporter); ok {
+		tr.harness.Helper()
+	}
+
+	reasons := diffObjects(expected, actual, "", nil)
+	if len(reasons) != 0 {
+		r.Report(buildDiffError(reasons, msg))
+		return false
+	}
+
+	return true
+}
+
+func diffObjects(expected, actual object.Object, path string, reasons []string) []string {
+	mismatch := func() []string {
+		return append(reasons, fmt.Sprintf("%sexpected: <%s> but was: <%s>", at(path), inspect(expected), inspect(actual)))
+	}
+
+	if expected == nil || actual == nil {
+		if expected != actual {
+			return mismatch()
+		}
+		return reasons
+	}
+
+	if expected.Type() != actual.Type() {
+		return mismatch()
+	}
+
+	switch expected := expected.(type) {
+	case object.Integer, object.Float, object.Boolean, object.String:
+		if expected != actual {
+			return mismatch()
+		}
+	case *object.BigInt:
+		if expected.Value.Cmp(actual.(*object.BigInt).Value) != 0 {
+			return mismatch()
+		}
+	case *object.Decimal:
+		if expected.Value.Cmp(actual.(*object.Decimal).Value) != 0 {
+			return mismatch()
+		}
+	case *object.Null:
+	case *object.Array:
+		actual := actual.(*object.Array)
+		if len(expected.Elements) != len(actual.Elements) {
+			return append(reasons, fmt.Sprintf("%slen ==> expected: <%d> but was: <%d>", at(path), len(expected.Elements), len(actual.Elements)))
+		}
+		for i := range expected.Elements {
+			reasons = diffObjects(expected.Elements[i], actual.Elements[i], fmt.Sprintf("%s[%d]", path, i), reasons)
+		}
+	case *object.Hash:
+		actual := actual.(*object.Hash)
+		keys := []object.HashKey{}
+		for key := range expected.Pairs {
+			keys = append(keys, key)
+		}
+		for key := range actual.Pairs {
+			if _, ok := expected.Pairs[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Type != keys[j].Type {
+				return keys[i].Type < keys[j].Type
+			}
+			return keys[i].Value < keys[j].Value
+		})
+		for _, key := range keys {
+			e, eok := expected.Pairs[key]
+			a, aok := actual.Pairs[key]
+			switch {
+			case !aok:
+				reasons = append(reasons, fmt.Sprintf("%s[%s] ==> expected: <%s> but was: <missing>", path, e.Key.Inspect(), inspect(e.Value)))
+			case !eok:
+				reasons = append(reasons, fmt.Sprintf("%s[%s] ==> expected: <missing> but was: <%s>", path, a.Key.Inspect(), inspect(a.Value)))
+			default:
+				reasons = diffObjects(e.Value, a.Value, fmt.Sprintf("%s[%s]", path, e.Key.Inspect()), reasons)
+			}
+		}
+	case *object.Function:
+		if !ast.Equal(expected.Literal, actual.(*object.Function).Literal) {
+			return mismatch()
+		}
+	case *object.Macro:
+		if !ast.Equal(expected.Declaration, actual.(*object.Macro).Declaration) {
+			return mismatch()
+		}
+	case *object.Quote:
+		if quote, ok := actual.(*object.Quote); !ok || !ast.Equal(expected.Node, quote.Node) {
+			return mismatch()
+		}
+	default:
+		if expected != actual {
+			return mismatch()
+		}
+	}
+
+	return reasons
+}
+
+func at(path string) string {
+	if path == "" {
+		return ""
+	}
+	return path + " ==> "
+}
+
+func inspect(obj object.Object) string {
+	if obj == nil {
+		return "<nil>"
+	}
+	return obj.Inspect()
+}
+
+func buildDiffError(reasons []string, msg string) error {
+	reason := strings.Join(reasons, "\n")
+	if msg != "" {
+		return fmt.Errorf("%s ==> %s", msg, reason)
+	}
+	return fmt.Errorf("%s", reason)
+}
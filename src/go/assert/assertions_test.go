@@ -0,0 +1,110 @@
+package assert
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+type recorder struct {
+	errors []error
+}
+
+func (rr *recorder) Report(err error) {
+	rr.errors = append(rr.errors, err)
+}
+
+func hash(pairs ...object.Object) *object.Hash {
+	h := &object.Hash{Pairs: map[object.HashKey]object.HashPair{}}
+	for i := 0; i < len(pairs); i += 2 {
+		key := pairs[i].(object.Hashable)
+		h.Pairs[key.HashKey()] = object.HashPair{Key: pairs[i], Value: pairs[i+1]}
+	}
+	return h
+}
+
+func bigInt(s string) *object.BigInt {
+	value, _ := new(big.Int).SetString(s, 10)
+	return &object.BigInt{Value: value}
+}
+
+func decimal(s string) *object.Decimal {
+	value, _ := new(big.Rat).SetString(s)
+	return &object.Decimal{Value: value}
+}
+
+func TestNodeEquals(t *testing.T) {
+	r := &recorder{}
+
+	expected := parser.NewParser("let x = 1 + y;", false).ParseProgram()
+	if !NodeEquals(expected, parser.NewParser("let   x=1+y", false).ParseProgram(), r) {
+		t.Fatalf("NodeEquals() ==> expected: <true> but was: <false> (%v)", r.errors)
+	}
+
+	if NodeEqualsWithMessage(expected, parser.NewParser("let x = 1 - y;", false).ParseProgram(), "program", r) {
+		t.Fatalf("NodeEquals() ==> expected: <false> but was: <true>")
+	}
+
+	message := "program ==> PROGRAM.Statements[0].Value.Operator ==> expected: <+> but was: <->"
+	if len(r.errors) != 1 || r.errors[0].Error() != message {
+		t.Fatalf("Report() ==> expected: <[%s]> but was: <%v>", message, r.errors)
+	}
+}
+
+func TestObjectEquals(t *testing.T) {
+	tests := []struct {
+		expected object.Object
+		actual   object.Object
+		reasons  []string
+	}{
+		{object.Integer(1), object.Integer(1), nil},
+		{object.String("a"), object.String("b"), []string{`expected: <"a"> but was: <"b">`}},
+		{object.Integer(1), object.String("1"), []string{`expected: <1> but was: <"1">`}},
+		{&object.Null{}, &object.Null{}, nil},
+		{bigInt("100000000000000000000"), bigInt("100000000000000000000"), nil},
+		{bigInt("100000000000000000000"), bigInt("100000000000000000001"), []string{"expected: <100000000000000000000> but was: <100000000000000000001>"}},
+		{decimal("12.50"), decimal("12.5"), nil},
+		{decimal("12.50"), decimal("12.05"), []string{"expected: <12.5d> but was: <12.05d>"}},
+		{
+			&object.Array{Elements: []object.Object{object.Integer(1), &object.Array{Elements: []object.Object{object.Boolean(true)}}}},
+			&object.Array{Elements: []object.Object{object.Integer(1), &object.Array{Elements: []object.Object{object.Boolean(false)}}}},
+			[]string{"[1][0] ==> expected: <true> but was: <false>"},
+		},
+		{
+			&object.Array{Elements: []object.Object{object.Integer(1)}},
+			&object.Array{},
+			[]string{"len ==> expected: <1> but was: <0>"},
+		},
+		{
+			hash(object.String("a"), object.Integer(1), object.String("b"), hash(object.Integer(1), object.Integer(2))),
+			hash(object.String("b"), hash(object.Integer(1), object.Integer(2)), object.String("a"), object.Integer(1)),
+			nil,
+		},
+		{
+			hash(object.String("a"), object.Integer(1)),
+			hash(object.String("a"), object.Integer(2), object.String("c"), object.Integer(3)),
+			[]string{`["a"] ==> expected: <1> but was: <2>`, `["c"] ==> expected: <missing> but was: <3>`},
+		},
+	}
+
+	for i, test := range tests {
+		r := &recorder{}
+		ok := ObjectEquals(test.expected, test.actual, r)
+		if ok != (len(test.reasons) == 0) {
+			t.Fatalf("test[%d] - ObjectEquals() ==> expected: <%t> but was: <%t>", i, len(test.reasons) == 0, ok)
+		}
+		if len(test.reasons) == 0 {
+			continue
+		}
+		if len(r.errors) != 1 {
+			t.Fatalf("test[%d] - len(errors) ==> expected: <1> but was: <%d>", i, len(r.errors))
+		}
+		expected := strings.Join(test.reasons, "\n")
+		if expected != r.errors[0].Error() {
+			t.Errorf("test[%d] - Report() ==> expected: <%s> but was: <%s>", i, expected, r.errors[0].Error())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package ast
 
 import (
 	"bytes"
+	"math/big"
 	"strings"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
@@ -26,12 +27,16 @@ const (
 	ASSIGNMENT_EXPRESSION
 	SUBSCRIPT_EXPRESSION
 	IDENTIFIER
-	NUMBER_LITERAL
+	INTEGER_LITERAL
+	FLOAT_LITERAL
+	DECIMAL_LITERAL
 	BOOLEAN_LITERAL
 	STRING_LITERAL
 	ARRAY_LITERAL
 	HASH_LITERAL
 	NULL_LITERAL
+	YIELD_EXPRESSION
+	IMPORT_STATEMENT
 )
 
 type Node interface {
@@ -91,13 +96,15 @@ func (rs *ReturnStatement) statementNode()     {}
 func (es *ExpressionStatement) statementNode() {}
 func (bs *BlockStatement) statementNode()      {}
 func (ms *MacroStatement) statementNode()      {}
+func (is *ImportStatement) statementNode()     {}
 
 func (e *Error) statementNode() {}
 
 type LetDeclaration struct {
-	Token token.Token
-	Name  *Identifier
-	Value Expression
+	Token    token.Token
+	Name     *Identifier
+	Value    Expression
+	Exported bool
 }
 
 func (ls *LetDeclaration) TokenLiteral() string {
@@ -111,6 +118,9 @@ func (ls *LetDeclaration) Type() NodeType {
 func (ls *LetDeclaration) String() string {
 	var out bytes.Buffer
 
+	if ls.Exported {
+		out.WriteString("export ")
+	}
 	out.WriteString(ls.TokenLiteral())
 	out.WriteString(" ")
 	out.WriteString(ls.Name.String())
@@ -201,6 +211,7 @@ type MacroStatement struct {
 	Name       *Identifier
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Exported   bool
 }
 
 func (ms *MacroStatement) TokenLiteral() string {
@@ -219,6 +230,9 @@ func (ms *MacroStatement) String() string {
 		params = append(params, param.Value)
 	}
 
+	if ms.Exported {
+		out.WriteString("export ")
+	}
 	out.WriteString(ms.TokenLiteral())
 	out.WriteString(" ")
 	out.WriteString(ms.Name.String())
@@ -230,6 +244,34 @@ func (ms *MacroStatement) String() string {
 	return out.String()
 }
 
+// ImportStatement binds Name to the namespace of the module at Path.
+type ImportStatement struct {
+	Token token.Token
+	Path  *StringLiteral
+	Name  *Identifier
+}
+
+func (is *ImportStatement) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *ImportStatement) Type() NodeType {
+	return IMPORT_STATEMENT
+}
+
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(is.Path.String())
+	out.WriteString(" as ")
+	out.WriteString(is.Name.String())
+	out.WriteString(";")
+
+	return out.String()
+}
+
 type Expression interface {
 	Node
 	expressionNode()
@@ -237,18 +279,22 @@ type Expression interface {
 
 func (ue *UnaryExpression) expressionNode()       {}
 func (be *BinaryExpression) expressionNode()      {}
+func (le *LogicalExpression) expressionNode()     {}
 func (ce *ConditionalExpression) expressionNode() {}
 func (fl *FunctionLiteral) expressionNode()       {}
 func (ce *CallExpression) expressionNode()        {}
 func (ae *AssignmentExpression) expressionNode()  {}
 func (ie *SubscriptExpression) expressionNode()   {}
 func (i *Identifier) expressionNode()             {}
-func (nl *NumberLiteral) expressionNode()         {}
+func (il *IntegerLiteral) expressionNode()        {}
+func (fl *FloatLiteral) expressionNode()          {}
+func (dl *DecimalLiteral) expressionNode()        {}
 func (bl *BooleanLiteral) expressionNode()        {}
 func (sl *StringLiteral) expressionNode()         {}
 func (al *ArrayLiteral) expressionNode()          {}
 func (hl *HashLiteral) expressionNode()           {}
 func (nl *NullLiteral) expressionNode()           {}
+func (ye *YieldExpression) expressionNode()       {}
 
 func (e *Error) expressionNode() {}
 
@@ -366,6 +412,9 @@ type FunctionLiteral struct {
 	Token      token.Token
 	Parameters []*Identifier
 	Body       *BlockStatement
+
+	// Scope is filled in by the evaluator's resolver.
+	Scope *Scope
 }
 
 func (fl *FunctionLiteral) TokenLiteral() string {
@@ -478,6 +527,9 @@ func (ie *SubscriptExpression) String() string {
 type Identifier struct {
 	Token token.Token
 	Value string
+
+	// Binding is filled in by the evaluator's resolver.
+	Binding Binding
 }
 
 func (i *Identifier) TokenLiteral() string {
@@ -492,21 +544,58 @@ func (i *Identifier) String() string {
 	return i.Value
 }
 
-type NumberLiteral struct {
+type IntegerLiteral struct {
+	Token token.Token
+	Value int64
+
+	// Big holds the value of a literal that does not fit in Value.
+	Big *big.Int
+}
+
+func (il *IntegerLiteral) TokenLiteral() string {
+	return il.Token.Literal
+}
+
+func (il *IntegerLiteral) Type() NodeType {
+	return INTEGER_LITERAL
+}
+
+func (il *IntegerLiteral) String() string {
+	return il.Token.Literal
+}
+
+type FloatLiteral struct {
 	Token token.Token
 	Value float64
 }
 
-func (nl *NumberLiteral) TokenLiteral() string {
-	return nl.Token.Literal
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
 }
 
-func (nl *NumberLiteral) Type() NodeType {
-	return NUMBER_LITERAL
+func (fl *FloatLiteral) Type() NodeType {
+	return FLOAT_LITERAL
 }
 
-func (nl *NumberLiteral) String() string {
-	return nl.Token.Literal
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
+type DecimalLiteral struct {
+	Token token.Token
+	Value *big.Rat
+}
+
+func (dl *DecimalLiteral) TokenLiteral() string {
+	return dl.Token.Literal
+}
+
+func (dl *DecimalLiteral) Type() NodeType {
+	return DECIMAL_LITERAL
+}
+
+func (dl *DecimalLiteral) String() string {
+	return dl.Token.Literal
 }
 
 type BooleanLiteral struct {
@@ -616,6 +705,31 @@ func (nl *NullLiteral) String() string {
 	return nl.Token.Literal
 }
 
+type YieldExpression struct {
+	Token token.Token
+	Value Expression
+}
+
+func (ye *YieldExpression) TokenLiteral() string {
+	return ye.Token.Literal
+}
+
+func (ye *YieldExpression) Type() NodeType {
+	return YIELD_EXPRESSION
+}
+
+func (ye *YieldExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ye.TokenLiteral())
+	out.WriteString(" ")
+	out.WriteString(ye.Value.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 var nodes = [...]string{
 	PROGRAM:                "PROGRAM",
 	ERROR:                  "ERROR",
@@ -633,12 +747,25 @@ var nodes = [...]string{
 	ASSIGNMENT_EXPRESSION:  "ASSIGNMENT_EXPRESSION",
 	SUBSCRIPT_EXPRESSION:   "SUBSCRIPT_EXPRESSION",
 	IDENTIFIER:             "IDENTIFIER",
-	NUMBER_LITERAL:         "NUMBER_LITERAL",
+	INTEGER_LITERAL:        "INTEGER_LITERAL",
+	FLOAT_LITERAL:          "FLOAT_LITERAL",
+	DECIMAL_LITERAL:        "DECIMAL_LITERAL",
 	BOOLEAN_LITERAL:        "BOOLEAN_LITERAL",
 	STRING_LITERAL:         "STRING_LITERAL",
 	ARRAY_LITERAL:          "ARRAY_LITERAL",
 	HASH_LITERAL:           "HASH_LITERAL",
 	NULL_LITERAL:           "NULL_LITERAL",
+	YIELD_EXPRESSION:       "YIELD_EXPRESSION",
+	IMPORT_STATEMENT:       "IMPORT_STATEMENT",
+}
+
+func LookupNodeType(name string) (NodeType, bool) {
+	for nt, node := range nodes {
+		if node == name {
+			return NodeType(nt), true
+		}
+	}
+	return PROGRAM, false
 }
 
 func (nt NodeType) String() string {
@@ -1,24 +1,25 @@
-package ast
+package ast_test
 
 import (
 	"testing"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/assert"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
 )
 
 func TestString(t *testing.T) {
 	r := assert.GetTestReporter(t)
 
-	program := &Program{
-		Statements: []Statement{
-			&LetDeclaration{
+	program := &ast.Program{
+		Statements: []ast.Statement{
+			&ast.LetDeclaration{
 				Token: token.Token{Type: token.LET, Literal: "let"},
-				Name: &Identifier{
+				Name: &ast.Identifier{
 					Token: token.Token{Type: token.IDENT, Literal: "ident"},
 					Value: "ident",
 				},
-				Value: &Identifier{
+				Value: &ast.Identifier{
 					Token: token.Token{Type: token.IDENT, Literal: "value"},
 					Value: "value",
 				},
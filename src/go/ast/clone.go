@@ -0,0 +1,205 @@
+package ast
+
+import "fmt"
+
+func Clone(node Node) Node {
+	if isNil(node) {
+		return node
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		return &Program{Statements: cloneStatements(node.Statements)}
+	case *Error:
+		clone := *node
+		return &clone
+	case *LetDeclaration:
+		return &LetDeclaration{
+			Token:    node.Token,
+			Name:     cloneIdentifier(node.Name),
+			Value:    cloneExpression(node.Value),
+			Exported: node.Exported,
+		}
+	case *ReturnStatement:
+		return &ReturnStatement{
+			Token:       node.Token,
+			ReturnValue: cloneExpression(node.ReturnValue),
+		}
+	case *ExpressionStatement:
+		return &ExpressionStatement{
+			Token:      node.Token,
+			Expression: cloneExpression(node.Expression),
+		}
+	case *BlockStatement:
+		return cloneBlock(node)
+	case *MacroStatement:
+		return &MacroStatement{
+			Token:      node.Token,
+			Name:       cloneIdentifier(node.Name),
+			Parameters: cloneIdentifiers(node.Parameters),
+			Body:       cloneBlock(node.Body),
+			Exported:   node.Exported,
+		}
+	case *ImportStatement:
+		return &ImportStatement{
+			Token: node.Token,
+			Path:  Clone(node.Path).(*StringLiteral),
+			Name:  cloneIdentifier(node.Name),
+		}
+	case *UnaryExpression:
+		return &UnaryExpression{
+			Token:    node.Token,
+			Operator: node.Operator,
+			Right:    cloneExpression(node.Right),
+		}
+	case *BinaryExpression:
+		return &BinaryExpression{
+			Token:    node.Token,
+			Left:     cloneExpression(node.Left),
+			Operator: node.Operator,
+			Right:    cloneExpression(node.Right),
+		}
+	case *LogicalExpression:
+		return &LogicalExpression{
+			Token:    node.Token,
+			Left:     cloneExpression(node.Left),
+			Operator: node.Operator,
+			Right:    cloneExpression(node.Right),
+		}
+	case *ConditionalExpression:
+		return &ConditionalExpression{
+			Token:       node.Token,
+			Condition:   cloneExpression(node.Condition),
+			Consequence: cloneBlock(node.Consequence),
+			Alternative: cloneBlock(node.Alternative),
+		}
+	case *FunctionLiteral:
+		return &FunctionLiteral{
+			Token:      node.Token,
+			Parameters: cloneIdentifiers(node.Parameters),
+			Body:       cloneBlock(node.Body),
+		}
+	case *CallExpression:
+		return &CallExpression{
+			Token:     node.Token,
+			Callee:    cloneExpression(node.Callee),
+			Arguments: cloneExpressions(node.Arguments),
+		}
+	case *AssignmentExpression:
+		return &AssignmentExpression{
+			Token:  node.Token,
+			LValue: cloneExpression(node.LValue),
+			RValue: cloneExpression(node.RValue),
+		}
+	case *SubscriptExpression:
+		return &SubscriptExpression{
+			Token:     node.Token,
+			Base:      cloneExpression(node.Base),
+			Subscript: cloneExpression(node.Subscript),
+		}
+	case *Identifier:
+		return cloneIdentifier(node)
+	case *IntegerLiteral:
+		clone := *node
+		return &clone
+	case *DecimalLiteral:
+		clone := *node
+		return &clone
+	case *FloatLiteral:
+		clone := *node
+		return &clone
+	case *BooleanLiteral:
+		clone := *node
+		return &clone
+	case *StringLiteral:
+		clone := *node
+		return &clone
+	case *ArrayLiteral:
+		return &ArrayLiteral{
+			Token:    node.Token,
+			Elements: cloneExpressions(node.Elements),
+		}
+	case *HashLiteral:
+		clone := &HashLiteral{
+			Token: node.Token,
+			Keys:  make([]Expression, 0, len(node.Keys)),
+			Pairs: make(map[Expression]Expression, len(node.Pairs)),
+		}
+		for _, key := range node.Keys {
+			ckey := cloneExpression(key)
+			clone.Keys = append(clone.Keys, ckey)
+			clone.Pairs[ckey] = cloneExpression(node.Pairs[key])
+		}
+		return clone
+	case *NullLiteral:
+		clone := *node
+		return &clone
+	case *YieldExpression:
+		return &YieldExpression{
+			Token: node.Token,
+			Value: cloneExpression(node.Value),
+		}
+	default:
+		panic(fmt.Errorf("unexpected node type: %T", node))
+	}
+}
+
+func cloneStatements(stmts []Statement) []Statement {
+	if stmts == nil {
+		return nil
+	}
+	clones := make([]Statement, len(stmts))
+	for i, stmt := range stmts {
+		if !isNil(stmt) {
+			clones[i] = Clone(stmt).(Statement)
+		}
+	}
+	return clones
+}
+
+func cloneExpressions(exprs []Expression) []Expression {
+	if exprs == nil {
+		return nil
+	}
+	clones := make([]Expression, len(exprs))
+	for i, expr := range exprs {
+		clones[i] = cloneExpression(expr)
+	}
+	return clones
+}
+
+func cloneIdentifiers(idents []*Identifier) []*Identifier {
+	if idents == nil {
+		return nil
+	}
+	clones := make([]*Identifier, len(idents))
+	for i, ident := range idents {
+		clones[i] = cloneIdentifier(ident)
+	}
+	return clones
+}
+
+func cloneExpression(expr Expression) Expression {
+	if isNil(expr) {
+		return nil
+	}
+	return Clone(expr).(Expression)
+}
+
+func cloneBlock(block *BlockStatement) *BlockStatement {
+	if block == nil {
+		return nil
+	}
+	return &BlockStatement{
+		Token:      block.Token,
+		Statements: cloneStatements(block.Statements),
+	}
+}
+
+func cloneIdentifier(ident *Identifier) *Identifier {
+	if ident == nil {
+		return nil
+	}
+	clone := *ident
+	return &clone
+}
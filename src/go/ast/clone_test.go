@@ -0,0 +1,83 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+func TestClone(t *testing.T) {
+	original := &Program{
+		Statements: []Statement{
+			&LetDeclaration{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  ident("f"),
+				Value: &FunctionLiteral{
+					Token:      token.Token{Type: token.FN, Literal: "fn"},
+					Parameters: []*Identifier{ident("x")},
+					Body: &BlockStatement{
+						Token: token.Token{Type: token.LBRACE, Literal: "{"},
+						Statements: []Statement{
+							&ExpressionStatement{
+								Token: token.Token{Type: token.IDENT, Literal: "x"},
+								Expression: &BinaryExpression{
+									Token:    token.Token{Type: token.PLUS, Literal: "+"},
+									Left:     ident("x"),
+									Operator: "+",
+									Right:    one(),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	clone := Clone(original).(*Program)
+	if original.String() != clone.String() {
+		t.Fatalf("Clone() ==> expected: <%s> but was: <%s>", original.String(), clone.String())
+	}
+
+	originals := []Node{}
+	Inspect(original, func(node Node) bool {
+		originals = append(originals, node)
+		return true
+	})
+
+	i := 0
+	Inspect(clone, func(node Node) bool {
+		if originals[i] == node {
+			t.Errorf("Clone() ==> node <%s> is shared with the original", node.String())
+		}
+		i += 1
+		return true
+	})
+
+	Modify(clone, toTwo)
+	if "let f=fn(x){(x+1);};" != original.String() {
+		t.Fatalf("original.String() ==> expected: <%s> but was: <%s>", "let f=fn(x){(x+1);};", original.String())
+	}
+}
+
+func TestCloneHashLiteral(t *testing.T) {
+	key := &StringLiteral{Token: token.Token{Type: token.STRING, Literal: `"a"`}, Value: "a"}
+	original := &HashLiteral{
+		Token: token.Token{Type: token.LBRACE, Literal: "{"},
+		Keys:  []Expression{key},
+		Pairs: map[Expression]Expression{key: one()},
+	}
+
+	clone := Clone(original).(*HashLiteral)
+	if clone.Keys[0] == key {
+		t.Fatalf("Clone() ==> expected hash key to be copied")
+	}
+
+	if _, ok := clone.Pairs[clone.Keys[0]]; !ok {
+		t.Fatalf("Clone() ==> expected pairs to be keyed by the copied keys")
+	}
+
+	if original.String() != clone.String() {
+		t.Fatalf("Clone() ==> expected: <%s> but was: <%s>", original.String(), clone.String())
+	}
+}
@@ -0,0 +1,51 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+func EncodeDOT(node Node) string {
+	var out strings.Builder
+	out.WriteString("digraph AST {\n")
+	out.WriteString("\tnode [shape=box, fontname=\"monospace\"];\n")
+
+	next := 0
+	var visit func(node Node) int
+	visit = func(node Node) int {
+		id := next
+		next += 1
+		fmt.Fprintf(&out, "\tn%d [label=\"%s\"];\n", id, escapeDOT(label(node)))
+		for _, child := range Children(node) {
+			childID := visit(child.Node)
+			fmt.Fprintf(&out, "\tn%d -> n%d [label=\"%s\"];\n", id, childID, escapeDOT(child.Field))
+		}
+		return id
+	}
+	if !isNil(node) {
+		visit(node)
+	}
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func label(node Node) string {
+	if detail := detail(node); detail != "" {
+		return node.Type().String() + "\n" + detail
+	}
+	return node.Type().String()
+}
+
+func detail(node Node) string {
+	for _, attr := range []string{"Operator", "Value", "Message"} {
+		if value, ok := attribute(node, attr); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func escapeDOT(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,132 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type Difference struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+func (d Difference) String() string {
+	return fmt.Sprintf("%s ==> expected: <%s> but was: <%s>", d.Path, d.Expected, d.Actual)
+}
+
+// Equal reports whether a and b have the same structure, ignoring tokens and
+// their positions.
+func Equal(a, b Node) bool {
+	return len(Diff(a, b)) == 0
+}
+
+func Diff(expected, actual Node) []Difference {
+	return diff(expected, actual, "", nil)
+}
+
+func diff(expected, actual Node, path string, diffs []Difference) []Difference {
+	if isNil(expected) || isNil(actual) {
+		if isNil(expected) != isNil(actual) {
+			diffs = append(diffs, Difference{Path: root(path, expected, actual), Expected: describe(expected), Actual: describe(actual)})
+		}
+		return diffs
+	}
+
+	if expected.Type() != actual.Type() {
+		return append(diffs, Difference{Path: root(path, expected, actual), Expected: describe(expected), Actual: describe(actual)})
+	}
+
+	path = root(path, expected, actual)
+	for _, attr := range []string{"Operator", "Value", "Message", "Exported"} {
+		e, eok := attribute(expected, attr)
+		a, aok := attribute(actual, attr)
+		if eok && aok && e != a {
+			diffs = append(diffs, Difference{Path: path + "." + attr, Expected: e, Actual: a})
+		}
+	}
+
+	expectedChildren := Children(expected)
+	actualChildren := Children(actual)
+	actualByField := make(map[string]Node, len(actualChildren))
+	for _, child := range actualChildren {
+		actualByField[child.Field] = child.Node
+	}
+
+	seen := make(map[string]bool, len(expectedChildren))
+	for _, child := range expectedChildren {
+		seen[child.Field] = true
+		diffs = diff(child.Node, actualByField[child.Field], path+"."+child.Field, diffs)
+	}
+	for _, child := range actualChildren {
+		if !seen[child.Field] {
+			diffs = diff(nil, child.Node, path+"."+child.Field, diffs)
+		}
+	}
+
+	return diffs
+}
+
+func root(path string, expected, actual Node) string {
+	if path != "" {
+		return path
+	}
+	if !isNil(expected) {
+		return expected.Type().String()
+	}
+	if !isNil(actual) {
+		return actual.Type().String()
+	}
+	return "<nil>"
+}
+
+func attribute(node Node, name string) (string, bool) {
+	switch name {
+	case "Operator":
+		switch node := node.(type) {
+		case *UnaryExpression:
+			return node.Operator, true
+		case *BinaryExpression:
+			return node.Operator, true
+		case *LogicalExpression:
+			return node.Operator, true
+		}
+	case "Value":
+		switch node := node.(type) {
+		case *Identifier:
+			return node.Value, true
+		case *IntegerLiteral:
+			if node.Big != nil {
+				return node.Big.String(), true
+			}
+			return strconv.FormatInt(node.Value, 10), true
+		case *DecimalLiteral:
+			return node.Value.RatString(), true
+		case *FloatLiteral:
+			return strconv.FormatFloat(node.Value, 'g', -1, 64), true
+		case *BooleanLiteral:
+			return strconv.FormatBool(node.Value), true
+		case *StringLiteral:
+			return strconv.Quote(node.Value), true
+		}
+	case "Message":
+		if node, ok := node.(*Error); ok {
+			return node.Message, true
+		}
+	case "Exported":
+		switch node := node.(type) {
+		case *LetDeclaration:
+			return strconv.FormatBool(node.Exported), true
+		case *MacroStatement:
+			return strconv.FormatBool(node.Exported), true
+		}
+	}
+	return "", false
+}
+
+func describe(node Node) string {
+	if isNil(node) {
+		return "<nil>"
+	}
+	return node.Type().String() + " " + node.String()
+}
@@ -0,0 +1,83 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+func TestEqual(t *testing.T) {
+	moved := walkProgram()
+	Inspect(moved, func(node Node) bool {
+		if id, ok := node.(*Identifier); ok {
+			id.Token.Position = token.Position{Offset: 42, Line: 7, Column: 3}
+		}
+		return true
+	})
+
+	if !Equal(walkProgram(), moved) {
+		t.Fatalf("Equal() ==> expected: <true> but was: <false>")
+	}
+
+	if !Equal(walkProgram(), Clone(walkProgram())) {
+		t.Fatalf("Equal(Clone()) ==> expected: <true> but was: <false>")
+	}
+
+	if Equal(walkProgram(), &Program{}) {
+		t.Fatalf("Equal() ==> expected: <false> but was: <true>")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	changed := walkProgram()
+	let := changed.Statements[1].(*LetDeclaration)
+	let.Value.(*BinaryExpression).Operator = "-"
+	let.Value.(*BinaryExpression).Right = one()
+	exported := walkProgram()
+	exported.Statements[1].(*LetDeclaration).Exported = true
+
+	tests := []struct {
+		expected Node
+		actual   Node
+		diffs    []string
+	}{
+		{walkProgram(), walkProgram(), []string{}},
+		{
+			walkProgram(),
+			changed,
+			[]string{
+				"PROGRAM.Statements[1].Value.Operator ==> expected: <+> but was: <->",
+				"PROGRAM.Statements[1].Value.Right ==> expected: <IDENTIFIER y> but was: <INTEGER_LITERAL 1>",
+			},
+		},
+		{
+			&Program{Statements: []Statement{&Error{Message: "oops"}}},
+			&Program{},
+			[]string{"PROGRAM.Statements[0] ==> expected: <ERROR oops> but was: <<nil>>"},
+		},
+		{
+			walkProgram(),
+			exported,
+			[]string{"PROGRAM.Statements[1].Exported ==> expected: <false> but was: <true>"},
+		},
+		{
+			&MacroStatement{Name: ident("m"), Exported: true},
+			&MacroStatement{Name: ident("m")},
+			[]string{"MACRO_STATEMENT.Exported ==> expected: <true> but was: <false>"},
+		},
+		{ident("x"), ident("y"), []string{"IDENTIFIER.Value ==> expected: <x> but was: <y>"}},
+		{ident("x"), nil, []string{"IDENTIFIER ==> expected: <IDENTIFIER x> but was: <<nil>>"}},
+	}
+
+	for i, test := range tests {
+		diffs := Diff(test.expected, test.actual)
+		if len(test.diffs) != len(diffs) {
+			t.Fatalf("test[%d] - len(Diff()) ==> expected: <%d> but was: <%d> (%v)", i, len(test.diffs), len(diffs), diffs)
+		}
+		for j, diff := range diffs {
+			if test.diffs[j] != diff.String() {
+				t.Errorf("test[%d] - Diff()[%d] ==> expected: <%s> but was: <%s>", i, j, test.diffs[j], diff.String())
+			}
+		}
+	}
+}
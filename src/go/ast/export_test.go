@@ -0,0 +1,67 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestEncodeSExpr(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"", "(PROGRAM)\n"},
+		{
+			"let x = -1 + y;",
+			`(PROGRAM
+  :Statements[0] (LET_DECLARATION
+    :Name (IDENTIFIER x)
+    :Value (BINARY_EXPRESSION +
+      :Left (UNARY_EXPRESSION -
+        :Right (INTEGER_LITERAL 1))
+      :Right (IDENTIFIER y))))
+`,
+		},
+		{
+			`f("a", [true]);`,
+			`(PROGRAM
+  :Statements[0] (EXPRESSION_STATEMENT
+    :Expression (CALL_EXPRESSION
+      :Callee (IDENTIFIER f)
+      :Arguments[0] (STRING_LITERAL "a")
+      :Arguments[1] (ARRAY_LITERAL
+        :Elements[0] (BOOLEAN_LITERAL true)))))
+`,
+		},
+	}
+
+	for i, test := range tests {
+		actual := ast.EncodeSExpr(parser.NewParser(test.input, false).ParseProgram())
+		if test.expected != actual {
+			t.Errorf("test[%d] - EncodeSExpr() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+}
+
+func TestEncodeDOT(t *testing.T) {
+	expected := `digraph AST {
+	node [shape=box, fontname="monospace"];
+	n0 [label="PROGRAM"];
+	n1 [label="EXPRESSION_STATEMENT"];
+	n2 [label="HASH_LITERAL"];
+	n3 [label="STRING_LITERAL\n\"k\""];
+	n2 -> n3 [label="Keys[0]"];
+	n4 [label="IDENTIFIER\nv"];
+	n2 -> n4 [label="Pairs[0]"];
+	n1 -> n2 [label="Expression"];
+	n0 -> n1 [label="Statements[0]"];
+}
+`
+
+	actual := ast.EncodeDOT(parser.NewParser(`{"k": v}`, false).ParseProgram())
+	if expected != actual {
+		t.Fatalf("EncodeDOT() ==> expected: <%s> but was: <%s>", expected, actual)
+	}
+}
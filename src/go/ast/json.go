@@ -0,0 +1,477 @@
+package ast
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+type jsonToken struct {
+	Type    string `json:"type"`
+	Literal string `json:"literal"`
+	Offset  int    `json:"offset"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+}
+
+type jsonPair struct {
+	Key   *jsonNode `json:"key"`
+	Value *jsonNode `json:"value"`
+}
+
+type jsonNode struct {
+	Kind        string          `json:"kind"`
+	Token       *jsonToken      `json:"token,omitempty"`
+	Message     string          `json:"message,omitempty"`
+	Operator    string          `json:"operator,omitempty"`
+	Name        *jsonNode       `json:"name,omitempty"`
+	Parameters  []*jsonNode     `json:"parameters,omitempty"`
+	Value       json.RawMessage `json:"value,omitempty"`
+	ReturnValue *jsonNode       `json:"returnValue,omitempty"`
+	Expression  *jsonNode       `json:"expression,omitempty"`
+	Statements  []*jsonNode     `json:"statements,omitempty"`
+	Body        *jsonNode       `json:"body,omitempty"`
+	Left        *jsonNode       `json:"left,omitempty"`
+	Right       *jsonNode       `json:"right,omitempty"`
+	Condition   *jsonNode       `json:"condition,omitempty"`
+	Consequence *jsonNode       `json:"consequence,omitempty"`
+	Alternative *jsonNode       `json:"alternative,omitempty"`
+	Callee      *jsonNode       `json:"callee,omitempty"`
+	Arguments   []*jsonNode     `json:"arguments,omitempty"`
+	LValue      *jsonNode       `json:"lvalue,omitempty"`
+	RValue      *jsonNode       `json:"rvalue,omitempty"`
+	Base        *jsonNode       `json:"base,omitempty"`
+	Subscript   *jsonNode       `json:"subscript,omitempty"`
+	Elements    []*jsonNode     `json:"elements,omitempty"`
+	Pairs       []jsonPair      `json:"pairs,omitempty"`
+	Path        *jsonNode       `json:"path,omitempty"`
+	Exported    bool            `json:"exported,omitempty"`
+}
+
+func EncodeJSON(node Node) ([]byte, error) {
+	encoded, err := encodeNode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encoded)
+}
+
+func EncodeJSONIndent(node Node, indent string) ([]byte, error) {
+	encoded, err := encodeNode(node)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(encoded, "", indent)
+}
+
+func DecodeJSON(data []byte) (Node, error) {
+	var decoded *jsonNode
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, err
+	}
+	return decodeNode(decoded)
+}
+
+func encodeNode(node Node) (*jsonNode, error) {
+	if isNil(node) {
+		return nil, nil
+	}
+
+	encoded := &jsonNode{Kind: node.Type().String()}
+
+	var err error
+	encode := func(node Node) *jsonNode {
+		if err != nil {
+			return nil
+		}
+		var result *jsonNode
+		result, err = encodeNode(node)
+		return result
+	}
+	encodeList := func(n int, get func(int) Node) []*jsonNode {
+		list := make([]*jsonNode, 0, n)
+		for i := range n {
+			list = append(list, encode(get(i)))
+		}
+		return list
+	}
+	encodeValue := func(value any) json.RawMessage {
+		if err != nil {
+			return nil
+		}
+		var raw json.RawMessage
+		raw, err = json.Marshal(value)
+		return raw
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		encoded.Statements = encodeList(len(node.Statements), func(i int) Node { return node.Statements[i] })
+	case *Error:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Message = node.Message
+	case *LetDeclaration:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Name = encode(node.Name)
+		encoded.Expression = encode(node.Value)
+		encoded.Exported = node.Exported
+	case *ReturnStatement:
+		encoded.Token = encodeToken(node.Token)
+		encoded.ReturnValue = encode(node.ReturnValue)
+	case *ExpressionStatement:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Expression = encode(node.Expression)
+	case *BlockStatement:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Statements = encodeList(len(node.Statements), func(i int) Node { return node.Statements[i] })
+	case *MacroStatement:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Name = encode(node.Name)
+		encoded.Parameters = encodeList(len(node.Parameters), func(i int) Node { return node.Parameters[i] })
+		encoded.Body = encode(node.Body)
+		encoded.Exported = node.Exported
+	case *ImportStatement:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Path = encode(node.Path)
+		encoded.Name = encode(node.Name)
+	case *UnaryExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Operator = node.Operator
+		encoded.Right = encode(node.Right)
+	case *BinaryExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Operator = node.Operator
+		encoded.Left = encode(node.Left)
+		encoded.Right = encode(node.Right)
+	case *LogicalExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Operator = node.Operator
+		encoded.Left = encode(node.Left)
+		encoded.Right = encode(node.Right)
+	case *ConditionalExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Condition = encode(node.Condition)
+		encoded.Consequence = encode(node.Consequence)
+		encoded.Alternative = encode(node.Alternative)
+	case *FunctionLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Parameters = encodeList(len(node.Parameters), func(i int) Node { return node.Parameters[i] })
+		encoded.Body = encode(node.Body)
+	case *CallExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Callee = encode(node.Callee)
+		encoded.Arguments = encodeList(len(node.Arguments), func(i int) Node { return node.Arguments[i] })
+	case *AssignmentExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.LValue = encode(node.LValue)
+		encoded.RValue = encode(node.RValue)
+	case *SubscriptExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Base = encode(node.Base)
+		encoded.Subscript = encode(node.Subscript)
+	case *Identifier:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Value = encodeValue(node.Value)
+	case *IntegerLiteral:
+		encoded.Token = encodeToken(node.Token)
+		if node.Big != nil {
+			encoded.Value = encodeValue(node.Big)
+		} else {
+			encoded.Value = encodeValue(node.Value)
+		}
+	case *DecimalLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Value = encodeValue(node.Value)
+	case *FloatLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Value = encodeValue(node.Value)
+	case *BooleanLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Value = encodeValue(node.Value)
+	case *StringLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Value = encodeValue(node.Value)
+	case *ArrayLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Elements = encodeList(len(node.Elements), func(i int) Node { return node.Elements[i] })
+	case *HashLiteral:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Pairs = make([]jsonPair, 0, len(node.Keys))
+		for _, key := range node.Keys {
+			encoded.Pairs = append(encoded.Pairs, jsonPair{Key: encode(key), Value: encode(node.Pairs[key])})
+		}
+	case *NullLiteral:
+		encoded.Token = encodeToken(node.Token)
+	case *YieldExpression:
+		encoded.Token = encodeToken(node.Token)
+		encoded.Expression = encode(node.Value)
+	default:
+		return nil, fmt.Errorf("unexpected node type: %T", node)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return encoded, nil
+}
+
+func encodeToken(tok token.Token) *jsonToken {
+	return &jsonToken{
+		Type:    tok.Type.String(),
+		Literal: tok.Literal,
+		Offset:  tok.Position.Offset,
+		Line:    tok.Position.Line,
+		Column:  tok.Position.Column,
+	}
+}
+
+func decodeNode(encoded *jsonNode) (Node, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+
+	kind, ok := LookupNodeType(encoded.Kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown node kind: %q", encoded.Kind)
+	}
+
+	tok, err := decodeToken(encoded.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	decode := func(encoded *jsonNode) Node {
+		if err != nil {
+			return nil
+		}
+		var node Node
+		node, err = decodeNode(encoded)
+		return node
+	}
+	decodeExpression := func(encoded *jsonNode) Expression {
+		node := decode(encoded)
+		if node == nil || err != nil {
+			return nil
+		}
+		expr, ok := node.(Expression)
+		if !ok {
+			err = fmt.Errorf("expected expression but was %s", node.Type())
+		}
+		return expr
+	}
+	decodeStatement := func(encoded *jsonNode) Statement {
+		node := decode(encoded)
+		if node == nil || err != nil {
+			return nil
+		}
+		stmt, ok := node.(Statement)
+		if !ok {
+			err = fmt.Errorf("expected statement but was %s", node.Type())
+		}
+		return stmt
+	}
+	decodeIdentifier := func(encoded *jsonNode) *Identifier {
+		node := decode(encoded)
+		if node == nil || err != nil {
+			return nil
+		}
+		ident, ok := node.(*Identifier)
+		if !ok {
+			err = fmt.Errorf("expected identifier but was %s", node.Type())
+		}
+		return ident
+	}
+	decodeBlock := func(encoded *jsonNode) *BlockStatement {
+		node := decode(encoded)
+		if node == nil || err != nil {
+			return nil
+		}
+		block, ok := node.(*BlockStatement)
+		if !ok {
+			err = fmt.Errorf("expected block but was %s", node.Type())
+		}
+		return block
+	}
+	decodeStatements := func(list []*jsonNode) []Statement {
+		stmts := []Statement{}
+		for _, encoded := range list {
+			stmts = append(stmts, decodeStatement(encoded))
+		}
+		return stmts
+	}
+	decodeExpressions := func(list []*jsonNode) []Expression {
+		exprs := []Expression{}
+		for _, encoded := range list {
+			exprs = append(exprs, decodeExpression(encoded))
+		}
+		return exprs
+	}
+	decodeIdentifiers := func(list []*jsonNode) []*Identifier {
+		idents := []*Identifier{}
+		for _, encoded := range list {
+			idents = append(idents, decodeIdentifier(encoded))
+		}
+		return idents
+	}
+	decodeValue := func(target any) {
+		if err == nil {
+			err = json.Unmarshal(encoded.Value, target)
+		}
+	}
+
+	var node Node
+	switch kind {
+	case PROGRAM:
+		node = &Program{Statements: decodeStatements(encoded.Statements)}
+	case ERROR:
+		node = &Error{Token: tok, Message: encoded.Message}
+	case LET_DECLARATION:
+		node = &LetDeclaration{
+			Token:    tok,
+			Name:     decodeIdentifier(encoded.Name),
+			Value:    decodeExpression(encoded.Expression),
+			Exported: encoded.Exported,
+		}
+	case RETURN_STATEMENT:
+		node = &ReturnStatement{Token: tok, ReturnValue: decodeExpression(encoded.ReturnValue)}
+	case EXPRESSION_STATEMENT:
+		node = &ExpressionStatement{Token: tok, Expression: decodeExpression(encoded.Expression)}
+	case BLOCK_STATEMENT:
+		node = &BlockStatement{Token: tok, Statements: decodeStatements(encoded.Statements)}
+	case MACRO_STATEMENT:
+		node = &MacroStatement{
+			Token:      tok,
+			Name:       decodeIdentifier(encoded.Name),
+			Parameters: decodeIdentifiers(encoded.Parameters),
+			Body:       decodeBlock(encoded.Body),
+			Exported:   encoded.Exported,
+		}
+	case IMPORT_STATEMENT:
+		path, ok := decodeExpression(encoded.Path).(*StringLiteral)
+		if !ok && err == nil {
+			err = fmt.Errorf("expected string literal path of %s", kind)
+		}
+		node = &ImportStatement{Token: tok, Path: path, Name: decodeIdentifier(encoded.Name)}
+	case UNARY_EXPRESSION:
+		node = &UnaryExpression{Token: tok, Operator: encoded.Operator, Right: decodeExpression(encoded.Right)}
+	case BINARY_EXPRESSION:
+		node = &BinaryExpression{
+			Token:    tok,
+			Left:     decodeExpression(encoded.Left),
+			Operator: encoded.Operator,
+			Right:    decodeExpression(encoded.Right),
+		}
+	case LOGICAL_EXPRESSION:
+		node = &LogicalExpression{
+			Token:    tok,
+			Left:     decodeExpression(encoded.Left),
+			Operator: encoded.Operator,
+			Right:    decodeExpression(encoded.Right),
+		}
+	case CONDITIONAL_EXPRESSION:
+		node = &ConditionalExpression{
+			Token:       tok,
+			Condition:   decodeExpression(encoded.Condition),
+			Consequence: decodeBlock(encoded.Consequence),
+			Alternative: decodeBlock(encoded.Alternative),
+		}
+	case FUNCTION_LITERAL:
+		node = &FunctionLiteral{
+			Token:      tok,
+			Parameters: decodeIdentifiers(encoded.Parameters),
+			Body:       decodeBlock(encoded.Body),
+		}
+	case CALL_EXPRESSION:
+		node = &CallExpression{
+			Token:     tok,
+			Callee:    decodeExpression(encoded.Callee),
+			Arguments: decodeExpressions(encoded.Arguments),
+		}
+	case ASSIGNMENT_EXPRESSION:
+		node = &AssignmentExpression{
+			Token:  tok,
+			LValue: decodeExpression(encoded.LValue),
+			RValue: decodeExpression(encoded.RValue),
+		}
+	case SUBSCRIPT_EXPRESSION:
+		node = &SubscriptExpression{
+			Token:     tok,
+			Base:      decodeExpression(encoded.Base),
+			Subscript: decodeExpression(encoded.Subscript),
+		}
+	case IDENTIFIER:
+		ident := &Identifier{Token: tok}
+		decodeValue(&ident.Value)
+		node = ident
+	case INTEGER_LITERAL:
+		literal := &IntegerLiteral{Token: tok}
+		value := new(big.Int)
+		decodeValue(value)
+		if value.IsInt64() {
+			literal.Value = value.Int64()
+		} else {
+			literal.Big = value
+		}
+		node = literal
+	case DECIMAL_LITERAL:
+		literal := &DecimalLiteral{Token: tok, Value: new(big.Rat)}
+		decodeValue(literal.Value)
+		node = literal
+	case FLOAT_LITERAL:
+		literal := &FloatLiteral{Token: tok}
+		decodeValue(&literal.Value)
+		node = literal
+	case BOOLEAN_LITERAL:
+		literal := &BooleanLiteral{Token: tok}
+		decodeValue(&literal.Value)
+		node = literal
+	case STRING_LITERAL:
+		literal := &StringLiteral{Token: tok}
+		decodeValue(&literal.Value)
+		node = literal
+	case ARRAY_LITERAL:
+		node = &ArrayLiteral{Token: tok, Elements: decodeExpressions(encoded.Elements)}
+	case HASH_LITERAL:
+		hash := &HashLiteral{Token: tok, Keys: []Expression{}, Pairs: map[Expression]Expression{}}
+		for _, pair := range encoded.Pairs {
+			key := decodeExpression(pair.Key)
+			hash.Keys = append(hash.Keys, key)
+			hash.Pairs[key] = decodeExpression(pair.Value)
+		}
+		node = hash
+	case NULL_LITERAL:
+		node = &NullLiteral{Token: tok}
+	case YIELD_EXPRESSION:
+		node = &YieldExpression{Token: tok, Value: decodeExpression(encoded.Expression)}
+	default:
+		return nil, fmt.Errorf("unexpected node kind: %s", kind)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func decodeToken(encoded *jsonToken) (token.Token, error) {
+	if encoded == nil {
+		return token.Token{}, nil
+	}
+
+	ttype, ok := token.Lookup(encoded.Type)
+	if !ok {
+		return token.Token{}, fmt.Errorf("unknown token type: %q", encoded.Type)
+	}
+
+	return token.Token{
+		Type:    ttype,
+		Literal: encoded.Literal,
+		Position: token.Position{
+			Offset: encoded.Offset,
+			Line:   encoded.Line,
+			Column: encoded.Column,
+		},
+	}, nil
+}
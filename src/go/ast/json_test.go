@@ -0,0 +1,141 @@
+package ast_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []string{
+		`let x = 5; return x;`,
+		`let add = fn(a, b) { return a + b; }; add(1, 2 * 3);`,
+		`if (x > 1) { x = x - 1; } else { -x; }`,
+		`let h = {"one": 1, true: [1, 2.5, null], 3: !false}; h["one"];`,
+		`macro unless(c, a, b) { quote(if (!(unquote(c))) { unquote(a) } else { unquote(b) }) };`,
+		`let big = 100000000000000000000; let price = 12.50d; big * price;`,
+		`let count = fn(n) { yield n; let next = yield n + 1; count(next) };`,
+		`import "lib/strings.mk" as s; export let up = s["upper"]; export macro id(x) { x };`,
+	}
+
+	for i, input := range tests {
+		p := parser.NewParser(input, false)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("test[%d] - p.Errors() ==> %v", i, p.Errors())
+		}
+
+		data, err := ast.EncodeJSON(program)
+		if err != nil {
+			t.Fatalf("test[%d] - EncodeJSON() ==> %v", i, err)
+		}
+
+		decoded, err := ast.DecodeJSON(data)
+		if err != nil {
+			t.Fatalf("test[%d] - DecodeJSON() ==> %v", i, err)
+		}
+
+		if program.String() != decoded.String() {
+			t.Errorf("test[%d] - decoded.String() ==> expected: <%s> but was: <%s>", i, program.String(), decoded.String())
+		}
+
+		again, err := ast.EncodeJSON(decoded)
+		if err != nil {
+			t.Fatalf("test[%d] - EncodeJSON() ==> %v", i, err)
+		}
+
+		if string(data) != string(again) {
+			t.Errorf("test[%d] - EncodeJSON(decoded) ==> expected: <%s> but was: <%s>", i, data, again)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+	p := parser.NewParser("let x = 1 + y;", false)
+	program := p.ParseProgram()
+
+	data, err := ast.EncodeJSON(program.Statements[0])
+	if err != nil {
+		t.Fatalf("EncodeJSON() ==> %v", err)
+	}
+
+	var schema struct {
+		Kind  string
+		Token struct {
+			Type   string
+			Line   int
+			Column int
+		}
+		Value      json.RawMessage
+		Expression struct {
+			Kind     string
+			Operator string
+			Right    struct {
+				Kind  string
+				Value string
+				Token struct{ Column int }
+			}
+		}
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("json.Unmarshal() ==> %v", err)
+	}
+
+	if schema.Kind != "LET_DECLARATION" || schema.Token.Type != "LET" || schema.Token.Line != 1 || schema.Token.Column != 1 {
+		t.Errorf("EncodeJSON() ==> unexpected statement encoding: %s", data)
+	}
+
+	if schema.Value != nil || schema.Expression.Kind != "BINARY_EXPRESSION" || schema.Expression.Operator != "+" {
+		t.Errorf("EncodeJSON() ==> unexpected value encoding: %s", data)
+	}
+
+	if schema.Expression.Right.Kind != "IDENTIFIER" || schema.Expression.Right.Value != "y" || schema.Expression.Right.Token.Column != 13 {
+		t.Errorf("EncodeJSON() ==> unexpected identifier encoding: %s", data)
+	}
+}
+
+func TestJSONHashKeyOrder(t *testing.T) {
+	p := parser.NewParser(`{"c": 1, "a": 2, "b": 3}`, false)
+	program := p.ParseProgram()
+
+	data, err := ast.EncodeJSON(program)
+	if err != nil {
+		t.Fatalf("EncodeJSON() ==> %v", err)
+	}
+
+	decoded, err := ast.DecodeJSON(data)
+	if err != nil {
+		t.Fatalf("DecodeJSON() ==> %v", err)
+	}
+
+	hash := decoded.(*ast.Program).Statements[0].(*ast.ExpressionStatement).Expression.(*ast.HashLiteral)
+	keys := []string{}
+	for _, key := range hash.Keys {
+		keys = append(keys, key.String())
+	}
+
+	if `"c" "a" "b"` != strings.Join(keys, " ") {
+		t.Fatalf("hash.Keys ==> expected: <%s> but was: <%s>", `"c" "a" "b"`, strings.Join(keys, " "))
+	}
+}
+
+func TestJSONDecodeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		error string
+	}{
+		{`{"kind": "NOPE"}`, `unknown node kind: "NOPE"`},
+		{`{"kind": "IDENTIFIER", "token": {"type": "NOPE"}}`, `unknown token type: "NOPE"`},
+		{`{"kind": "EXPRESSION_STATEMENT", "expression": {"kind": "PROGRAM"}}`, `expected expression but was PROGRAM`},
+	}
+
+	for i, test := range tests {
+		_, err := ast.DecodeJSON([]byte(test.input))
+		if err == nil || err.Error() != test.error {
+			t.Errorf("test[%d] - DecodeJSON() ==> expected: <%s> but was: <%v>", i, test.error, err)
+		}
+	}
+}
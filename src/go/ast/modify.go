@@ -162,6 +162,12 @@ func Modify(node Node, modifier func(Node) Node) Node {
 			pairs[mkey] = mvalue
 		}
 		node.Pairs = pairs
+	case *YieldExpression:
+		modified, ok := Modify(node.Value, modifier).(Expression)
+		if !ok {
+			return toErrorNode(Expression(nil), modified)
+		}
+		node.Value = modified
 	}
 
 	return modifier(node)
@@ -175,3 +181,342 @@ func toErrorNode(expected, actual Node) Node {
 		Message: fmt.Sprintf("Modify() ==> unexpected type, expected: <%T> but was: <%T>", expected, actual),
 	}
 }
+
+func Rewrite(node Node, modifier func(Node) Node) Node {
+	if isNil(node) {
+		return modifier(node)
+	}
+
+	var result Node = node
+	switch node := node.(type) {
+	case *Program:
+		stmts, err := rewriteStatements(node.Statements, modifier)
+		if err != nil {
+			return err
+		}
+		if stmts != nil {
+			result = &Program{Statements: stmts}
+		}
+	case *LetDeclaration:
+		name, err := rewriteIdentifier(node.Name, modifier)
+		if err != nil {
+			return err
+		}
+		value, err := rewriteExpression(node.Value, modifier)
+		if err != nil {
+			return err
+		}
+		if name != node.Name || value != node.Value {
+			clone := *node
+			clone.Name, clone.Value = name, value
+			result = &clone
+		}
+	case *ReturnStatement:
+		value, err := rewriteExpression(node.ReturnValue, modifier)
+		if err != nil {
+			return err
+		}
+		if value != node.ReturnValue {
+			clone := *node
+			clone.ReturnValue = value
+			result = &clone
+		}
+	case *ExpressionStatement:
+		expr, err := rewriteExpression(node.Expression, modifier)
+		if err != nil {
+			return err
+		}
+		if expr != node.Expression {
+			clone := *node
+			clone.Expression = expr
+			result = &clone
+		}
+	case *BlockStatement:
+		stmts, err := rewriteStatements(node.Statements, modifier)
+		if err != nil {
+			return err
+		}
+		if stmts != nil {
+			result = &BlockStatement{Token: node.Token, Statements: stmts}
+		}
+	case *MacroStatement:
+		name, err := rewriteIdentifier(node.Name, modifier)
+		if err != nil {
+			return err
+		}
+		params, err := rewriteIdentifiers(node.Parameters, modifier)
+		if err != nil {
+			return err
+		}
+		body, err := rewriteBlock(node.Body, modifier)
+		if err != nil {
+			return err
+		}
+		if name != node.Name || params != nil || body != node.Body {
+			clone := *node
+			clone.Name, clone.Body = name, body
+			if params != nil {
+				clone.Parameters = params
+			}
+			result = &clone
+		}
+	case *ImportStatement:
+		name, err := rewriteIdentifier(node.Name, modifier)
+		if err != nil {
+			return err
+		}
+		if name != node.Name {
+			clone := *node
+			clone.Name = name
+			result = &clone
+		}
+	case *UnaryExpression:
+		right, err := rewriteExpression(node.Right, modifier)
+		if err != nil {
+			return err
+		}
+		if right != node.Right {
+			clone := *node
+			clone.Right = right
+			result = &clone
+		}
+	case *BinaryExpression:
+		left, err := rewriteExpression(node.Left, modifier)
+		if err != nil {
+			return err
+		}
+		right, err := rewriteExpression(node.Right, modifier)
+		if err != nil {
+			return err
+		}
+		if left != node.Left || right != node.Right {
+			clone := *node
+			clone.Left, clone.Right = left, right
+			result = &clone
+		}
+	case *LogicalExpression:
+		left, err := rewriteExpression(node.Left, modifier)
+		if err != nil {
+			return err
+		}
+		right, err := rewriteExpression(node.Right, modifier)
+		if err != nil {
+			return err
+		}
+		if left != node.Left || right != node.Right {
+			clone := *node
+			clone.Left, clone.Right = left, right
+			result = &clone
+		}
+	case *ConditionalExpression:
+		condition, err := rewriteExpression(node.Condition, modifier)
+		if err != nil {
+			return err
+		}
+		consequence, err := rewriteBlock(node.Consequence, modifier)
+		if err != nil {
+			return err
+		}
+		alternative, err := rewriteBlock(node.Alternative, modifier)
+		if err != nil {
+			return err
+		}
+		if condition != node.Condition || consequence != node.Consequence || alternative != node.Alternative {
+			clone := *node
+			clone.Condition, clone.Consequence, clone.Alternative = condition, consequence, alternative
+			result = &clone
+		}
+	case *FunctionLiteral:
+		params, err := rewriteIdentifiers(node.Parameters, modifier)
+		if err != nil {
+			return err
+		}
+		body, err := rewriteBlock(node.Body, modifier)
+		if err != nil {
+			return err
+		}
+		if params != nil || body != node.Body {
+			clone := *node
+			if params != nil {
+				clone.Parameters = params
+			}
+			clone.Body = body
+			result = &clone
+		}
+	case *AssignmentExpression:
+		lvalue, err := rewriteExpression(node.LValue, modifier)
+		if err != nil {
+			return err
+		}
+		rvalue, err := rewriteExpression(node.RValue, modifier)
+		if err != nil {
+			return err
+		}
+		if lvalue != node.LValue || rvalue != node.RValue {
+			clone := *node
+			clone.LValue, clone.RValue = lvalue, rvalue
+			result = &clone
+		}
+	case *CallExpression:
+		callee, err := rewriteExpression(node.Callee, modifier)
+		if err != nil {
+			return err
+		}
+		args, err := rewriteExpressions(node.Arguments, modifier)
+		if err != nil {
+			return err
+		}
+		if callee != node.Callee || args != nil {
+			clone := *node
+			clone.Callee = callee
+			if args != nil {
+				clone.Arguments = args
+			}
+			result = &clone
+		}
+	case *SubscriptExpression:
+		base, err := rewriteExpression(node.Base, modifier)
+		if err != nil {
+			return err
+		}
+		subscript, err := rewriteExpression(node.Subscript, modifier)
+		if err != nil {
+			return err
+		}
+		if base != node.Base || subscript != node.Subscript {
+			clone := *node
+			clone.Base, clone.Subscript = base, subscript
+			result = &clone
+		}
+	case *ArrayLiteral:
+		elems, err := rewriteExpressions(node.Elements, modifier)
+		if err != nil {
+			return err
+		}
+		if elems != nil {
+			result = &ArrayLiteral{Token: node.Token, Elements: elems}
+		}
+	case *HashLiteral:
+		changed := false
+		keys := make([]Expression, len(node.Keys))
+		pairs := make(map[Expression]Expression, len(node.Pairs))
+		for i, key := range node.Keys {
+			mkey, err := rewriteExpression(key, modifier)
+			if err != nil {
+				return err
+			}
+			mvalue, err := rewriteExpression(node.Pairs[key], modifier)
+			if err != nil {
+				return err
+			}
+			changed = changed || mkey != key || mvalue != node.Pairs[key]
+			keys[i] = mkey
+			pairs[mkey] = mvalue
+		}
+		if changed {
+			result = &HashLiteral{Token: node.Token, Keys: keys, Pairs: pairs}
+		}
+	case *YieldExpression:
+		value, err := rewriteExpression(node.Value, modifier)
+		if err != nil {
+			return err
+		}
+		if value != node.Value {
+			clone := *node
+			clone.Value = value
+			result = &clone
+		}
+	}
+
+	return modifier(result)
+}
+
+func rewriteStatements(stmts []Statement, modifier func(Node) Node) ([]Statement, Node) {
+	var rewritten []Statement
+	for i, stmt := range stmts {
+		if isNil(stmt) {
+			continue
+		}
+		modified, ok := Rewrite(stmt, modifier).(Statement)
+		if !ok {
+			return nil, toErrorNode(Statement(nil), modified)
+		}
+		if modified != stmt && rewritten == nil {
+			rewritten = make([]Statement, len(stmts))
+			copy(rewritten, stmts)
+		}
+		if rewritten != nil {
+			rewritten[i] = modified
+		}
+	}
+	return rewritten, nil
+}
+
+func rewriteExpressions(exprs []Expression, modifier func(Node) Node) ([]Expression, Node) {
+	var rewritten []Expression
+	for i, expr := range exprs {
+		modified, err := rewriteExpression(expr, modifier)
+		if err != nil {
+			return nil, err
+		}
+		if modified != expr && rewritten == nil {
+			rewritten = make([]Expression, len(exprs))
+			copy(rewritten, exprs)
+		}
+		if rewritten != nil {
+			rewritten[i] = modified
+		}
+	}
+	return rewritten, nil
+}
+
+func rewriteIdentifiers(idents []*Identifier, modifier func(Node) Node) ([]*Identifier, Node) {
+	var rewritten []*Identifier
+	for i, ident := range idents {
+		modified, err := rewriteIdentifier(ident, modifier)
+		if err != nil {
+			return nil, err
+		}
+		if modified != ident && rewritten == nil {
+			rewritten = make([]*Identifier, len(idents))
+			copy(rewritten, idents)
+		}
+		if rewritten != nil {
+			rewritten[i] = modified
+		}
+	}
+	return rewritten, nil
+}
+
+func rewriteExpression(expr Expression, modifier func(Node) Node) (Expression, Node) {
+	if isNil(expr) {
+		return expr, nil
+	}
+	modified, ok := Rewrite(expr, modifier).(Expression)
+	if !ok {
+		return nil, toErrorNode(Expression(nil), modified)
+	}
+	return modified, nil
+}
+
+func rewriteBlock(block *BlockStatement, modifier func(Node) Node) (*BlockStatement, Node) {
+	if block == nil {
+		return nil, nil
+	}
+	modified, ok := Rewrite(block, modifier).(*BlockStatement)
+	if !ok {
+		return nil, toErrorNode(&BlockStatement{}, modified)
+	}
+	return modified, nil
+}
+
+func rewriteIdentifier(ident *Identifier, modifier func(Node) Node) (*Identifier, Node) {
+	if ident == nil {
+		return nil, nil
+	}
+	modified, ok := Rewrite(ident, modifier).(*Identifier)
+	if !ok {
+		return nil, toErrorNode(&Identifier{}, modified)
+	}
+	return modified, nil
+}
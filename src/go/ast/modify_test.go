@@ -6,14 +6,14 @@ import (
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
 )
 
-var one = func() *NumberLiteral {
-	return &NumberLiteral{
-		token.Token{Type: token.NUMBER, Literal: "1"},
-		1,
+var one = func() *IntegerLiteral {
+	return &IntegerLiteral{
+		Token: token.Token{Type: token.INT, Literal: "1"},
+		Value: 1,
 	}
 }
 var toTwo = func(node Node) Node {
-	num, ok := node.(*NumberLiteral)
+	num, ok := node.(*IntegerLiteral)
 	if !ok {
 		return node
 	}
@@ -42,7 +42,7 @@ func TestModify(t *testing.T) {
 			input: &Program{
 				[]Statement{
 					&ExpressionStatement{
-						token.Token{Type: token.NUMBER, Literal: "1"},
+						token.Token{Type: token.INT, Literal: "1"},
 						one(),
 					},
 				},
@@ -96,7 +96,7 @@ func TestModify(t *testing.T) {
 					token.Token{Type: token.LBRACE, Literal: "{"},
 					[]Statement{
 						&ExpressionStatement{
-							token.Token{Type: token.NUMBER, Literal: "1"},
+							token.Token{Type: token.INT, Literal: "1"},
 							one(),
 						},
 					},
@@ -105,7 +105,7 @@ func TestModify(t *testing.T) {
 					token.Token{Type: token.LBRACE, Literal: "{"},
 					[]Statement{
 						&ExpressionStatement{
-							token.Token{Type: token.NUMBER, Literal: "1"},
+							token.Token{Type: token.INT, Literal: "1"},
 							one(),
 						},
 					},
@@ -126,23 +126,24 @@ func TestModify(t *testing.T) {
 			input: &LetDeclaration{
 				token.Token{Type: token.LET, Literal: "let"},
 				&Identifier{
-					token.Token{Type: token.IDENT, Literal: "ident"},
-					"ident",
+					Token: token.Token{Type: token.IDENT, Literal: "ident"},
+					Value: "ident",
 				},
 				one(),
+				false,
 			},
 			modifier: toTwo,
 			output:   "let ident=2;",
 		},
 		{
 			input: &FunctionLiteral{
-				token.Token{Type: token.FN, Literal: "fn"},
-				[]*Identifier{},
-				&BlockStatement{
+				Token:      token.Token{Type: token.FN, Literal: "fn"},
+				Parameters: []*Identifier{},
+				Body: &BlockStatement{
 					token.Token{Type: token.LBRACE, Literal: "{"},
 					[]Statement{
 						&ExpressionStatement{
-							token.Token{Type: token.NUMBER, Literal: "1"},
+							token.Token{Type: token.INT, Literal: "1"},
 							one(),
 						},
 					},
@@ -204,3 +205,72 @@ func TestModifyHashLiteral(t *testing.T) {
 		}
 	}
 }
+
+var plusOne = func(node Node) Node {
+	num, ok := node.(*IntegerLiteral)
+	if !ok {
+		return node
+	}
+
+	return &IntegerLiteral{
+		Token: token.Token{Type: token.INT, Literal: "2"},
+		Value: num.Value + 1,
+	}
+}
+
+func TestRewrite(t *testing.T) {
+	shared := &Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"}
+	input := &Program{
+		[]Statement{
+			&ExpressionStatement{
+				token.Token{Type: token.INT, Literal: "1"},
+				&BinaryExpression{
+					token.Token{Type: token.PLUS, Literal: "+"},
+					one(),
+					"+",
+					shared,
+				},
+			},
+			&ExpressionStatement{
+				token.Token{Type: token.IDENT, Literal: "x"},
+				shared,
+			},
+		},
+	}
+	untouched := input.Statements[1]
+
+	actual := Rewrite(input, plusOne).(*Program)
+
+	if "(2+x);x;" != actual.String() {
+		t.Fatalf("Rewrite() ==> expected: <%s> but was: <%s>", "(2+x);x;", actual.String())
+	}
+
+	if "(1+x);x;" != input.String() {
+		t.Fatalf("input.String() ==> expected: <%s> but was: <%s>", "(1+x);x;", input.String())
+	}
+
+	if actual == input {
+		t.Fatalf("Rewrite() ==> expected a new program")
+	}
+
+	if actual.Statements[1] != untouched {
+		t.Fatalf("Rewrite() ==> expected unchanged statements to be shared")
+	}
+
+	if actual.Statements[0].(*ExpressionStatement).Expression.(*BinaryExpression).Right != shared {
+		t.Fatalf("Rewrite() ==> expected unchanged expressions to be shared")
+	}
+}
+
+func TestRewriteUnchanged(t *testing.T) {
+	input := &ArrayLiteral{
+		token.Token{Type: token.LBRACK, Literal: "["},
+		[]Expression{
+			&Identifier{Token: token.Token{Type: token.IDENT, Literal: "x"}, Value: "x"},
+		},
+	}
+
+	if actual := Rewrite(input, plusOne); actual != input {
+		t.Fatalf("Rewrite() ==> expected: <%p> but was: <%p>", input, actual)
+	}
+}
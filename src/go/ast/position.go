@@ -0,0 +1,69 @@
+package ast
+
+import "github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+
+// Position returns the position of the token that introduces node, or the
+// zero Position for nodes built without one. Calls are located at their
+// callee rather than at the opening parenthesis.
+func Position(node Node) token.Position {
+	switch node := node.(type) {
+	case *Program:
+		if len(node.Statements) > 0 {
+			return Position(node.Statements[0])
+		}
+	case *Error:
+		return node.Token.Position
+	case *LetDeclaration:
+		return node.Token.Position
+	case *ReturnStatement:
+		return node.Token.Position
+	case *ExpressionStatement:
+		return node.Token.Position
+	case *BlockStatement:
+		return node.Token.Position
+	case *MacroStatement:
+		return node.Token.Position
+	case *ImportStatement:
+		return node.Token.Position
+	case *UnaryExpression:
+		return node.Token.Position
+	case *BinaryExpression:
+		return node.Token.Position
+	case *LogicalExpression:
+		return node.Token.Position
+	case *ConditionalExpression:
+		return node.Token.Position
+	case *FunctionLiteral:
+		return node.Token.Position
+	case *CallExpression:
+		if position := Position(node.Callee); position.IsValid() {
+			return position
+		}
+		return node.Token.Position
+	case *AssignmentExpression:
+		return node.Token.Position
+	case *SubscriptExpression:
+		return node.Token.Position
+	case *Identifier:
+		return node.Token.Position
+	case *IntegerLiteral:
+		return node.Token.Position
+	case *DecimalLiteral:
+		return node.Token.Position
+	case *FloatLiteral:
+		return node.Token.Position
+	case *BooleanLiteral:
+		return node.Token.Position
+	case *StringLiteral:
+		return node.Token.Position
+	case *ArrayLiteral:
+		return node.Token.Position
+	case *HashLiteral:
+		return node.Token.Position
+	case *NullLiteral:
+		return node.Token.Position
+	case *YieldExpression:
+		return node.Token.Position
+	}
+	return token.Position{}
+}
@@ -0,0 +1,31 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestPosition(t *testing.T) {
+	program := parser.NewParserAtLine("let x = 1;\n  f(x) + -y;", 3, false).ParseProgram()
+	call := program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.BinaryExpression)
+
+	tests := []struct {
+		node     ast.Node
+		expected string
+	}{
+		{program, "3:1"},
+		{program.Statements[0], "3:1"},
+		{call, "4:8"},
+		{call.Left, "4:3"},
+		{call.Right, "4:10"},
+		{&ast.Program{}, "-"},
+	}
+
+	for i, test := range tests {
+		if actual := ast.Position(test.node).String(); test.expected != actual {
+			t.Errorf("test[%d] - Position() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+}
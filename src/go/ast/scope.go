@@ -0,0 +1,26 @@
+package ast
+
+type BindingKind int
+
+const (
+	UNRESOLVED BindingKind = iota
+	LOCAL
+	GLOBAL
+)
+
+// Binding is where an identifier was resolved: slot Slot of the function
+// Depth calls out, or the global environment Depth calls out. Unresolved
+// identifiers are looked up by name.
+type Binding struct {
+	Kind  BindingKind
+	Depth int
+	Slot  int
+}
+
+// Scope lays out the variables of a function: its parameters first, in
+// order, followed by every other name it declares. A function that yields
+// is a Generator.
+type Scope struct {
+	Names     []string
+	Generator bool
+}
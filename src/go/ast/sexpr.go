@@ -0,0 +1,34 @@
+package ast
+
+import "strings"
+
+func EncodeSExpr(node Node) string {
+	var out strings.Builder
+	writeSExpr(&out, node, 0)
+	out.WriteString("\n")
+	return out.String()
+}
+
+func writeSExpr(out *strings.Builder, node Node, depth int) {
+	if isNil(node) {
+		out.WriteString("nil")
+		return
+	}
+
+	out.WriteString("(")
+	out.WriteString(node.Type().String())
+	if detail := detail(node); detail != "" {
+		out.WriteString(" ")
+		out.WriteString(detail)
+	}
+
+	for _, child := range Children(node) {
+		out.WriteString("\n")
+		out.WriteString(strings.Repeat("  ", depth+1))
+		out.WriteString(":")
+		out.WriteString(child.Field)
+		out.WriteString(" ")
+		writeSExpr(out, child.Node, depth+1)
+	}
+	out.WriteString(")")
+}
@@ -0,0 +1,160 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+)
+
+type Visitor interface {
+	Enter(node Node, path []Node) bool
+	Leave(node Node, path []Node)
+}
+
+type WalkFuncs struct {
+	Pre  func(node Node, path []Node) bool
+	Post func(node Node, path []Node)
+}
+
+func (wf WalkFuncs) Enter(node Node, path []Node) bool {
+	if wf.Pre == nil {
+		return true
+	}
+	return wf.Pre(node, path)
+}
+
+func (wf WalkFuncs) Leave(node Node, path []Node) {
+	if wf.Post != nil {
+		wf.Post(node, path)
+	}
+}
+
+type Child struct {
+	Field string
+	Node  Node
+}
+
+func Walk(node Node, v Visitor) {
+	walk(node, v, []Node{})
+}
+
+func Inspect(node Node, f func(Node) bool) {
+	Walk(node, WalkFuncs{
+		Pre: func(node Node, path []Node) bool {
+			return f(node)
+		},
+	})
+}
+
+func walk(node Node, v Visitor, path []Node) {
+	if isNil(node) {
+		return
+	}
+
+	if !v.Enter(node, path) {
+		return
+	}
+
+	path = append(path, node)
+	for _, child := range Children(node) {
+		walk(child.Node, v, path)
+	}
+	path = path[:len(path)-1]
+
+	v.Leave(node, path)
+}
+
+func Children(node Node) []Child {
+	if isNil(node) {
+		return nil
+	}
+
+	children := []Child{}
+	add := func(field string, child Node) {
+		if !isNil(child) {
+			children = append(children, Child{Field: field, Node: child})
+		}
+	}
+
+	switch node := node.(type) {
+	case *Program:
+		for i, stmt := range node.Statements {
+			add(indexed("Statements", i), stmt)
+		}
+	case *LetDeclaration:
+		add("Name", node.Name)
+		add("Value", node.Value)
+	case *ReturnStatement:
+		add("ReturnValue", node.ReturnValue)
+	case *ExpressionStatement:
+		add("Expression", node.Expression)
+	case *BlockStatement:
+		for i, stmt := range node.Statements {
+			add(indexed("Statements", i), stmt)
+		}
+	case *MacroStatement:
+		add("Name", node.Name)
+		for i, param := range node.Parameters {
+			add(indexed("Parameters", i), param)
+		}
+		add("Body", node.Body)
+	case *ImportStatement:
+		add("Path", node.Path)
+		add("Name", node.Name)
+	case *UnaryExpression:
+		add("Right", node.Right)
+	case *BinaryExpression:
+		add("Left", node.Left)
+		add("Right", node.Right)
+	case *LogicalExpression:
+		add("Left", node.Left)
+		add("Right", node.Right)
+	case *ConditionalExpression:
+		add("Condition", node.Condition)
+		add("Consequence", node.Consequence)
+		add("Alternative", node.Alternative)
+	case *FunctionLiteral:
+		for i, param := range node.Parameters {
+			add(indexed("Parameters", i), param)
+		}
+		add("Body", node.Body)
+	case *CallExpression:
+		add("Callee", node.Callee)
+		for i, arg := range node.Arguments {
+			add(indexed("Arguments", i), arg)
+		}
+	case *AssignmentExpression:
+		add("LValue", node.LValue)
+		add("RValue", node.RValue)
+	case *SubscriptExpression:
+		add("Base", node.Base)
+		add("Subscript", node.Subscript)
+	case *ArrayLiteral:
+		for i, elem := range node.Elements {
+			add(indexed("Elements", i), elem)
+		}
+	case *HashLiteral:
+		for i, key := range node.Keys {
+			add(indexed("Keys", i), key)
+			add(indexed("Pairs", i), node.Pairs[key])
+		}
+	case *YieldExpression:
+		add("Value", node.Value)
+	case *Error, *Identifier, *IntegerLiteral, *FloatLiteral, *DecimalLiteral, *BooleanLiteral, *StringLiteral, *NullLiteral:
+	default:
+		panic(fmt.Errorf("unexpected node type: %T", node))
+	}
+
+	return children
+}
+
+func indexed(field string, index int) string {
+	return fmt.Sprintf("%s[%d]", field, index)
+}
+
+func isNil(node Node) bool {
+	if node == nil {
+		return true
+	}
+	value := reflect.ValueOf(node)
+	return value.Kind() == reflect.Pointer && value.IsNil()
+}
@@ -0,0 +1,165 @@
+package ast
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+func ident(name string) *Identifier {
+	return &Identifier{
+		Token: token.Token{Type: token.IDENT, Literal: name},
+		Value: name,
+	}
+}
+
+var walkProgram = func() *Program {
+	return &Program{
+		Statements: []Statement{
+			&MacroStatement{
+				Token:      token.Token{Type: token.MACRO, Literal: "macro"},
+				Name:       ident("m"),
+				Parameters: []*Identifier{ident("a")},
+				Body: &BlockStatement{
+					Token: token.Token{Type: token.LBRACE, Literal: "{"},
+					Statements: []Statement{
+						&ExpressionStatement{
+							Token:      token.Token{Type: token.IDENT, Literal: "a"},
+							Expression: ident("a"),
+						},
+					},
+				},
+			},
+			&LetDeclaration{
+				Token: token.Token{Type: token.LET, Literal: "let"},
+				Name:  ident("x"),
+				Value: &BinaryExpression{
+					Token:    token.Token{Type: token.PLUS, Literal: "+"},
+					Left:     one(),
+					Operator: "+",
+					Right:    ident("y"),
+				},
+			},
+			&Error{Message: "oops"},
+		},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	events := []string{}
+	Walk(walkProgram(), WalkFuncs{
+		Pre: func(node Node, path []Node) bool {
+			events = append(events, "+"+node.Type().String())
+			return true
+		},
+		Post: func(node Node, path []Node) {
+			events = append(events, "-"+node.Type().String())
+		},
+	})
+
+	expected := []string{
+		"+PROGRAM",
+		"+MACRO_STATEMENT",
+		"+IDENTIFIER", "-IDENTIFIER",
+		"+IDENTIFIER", "-IDENTIFIER",
+		"+BLOCK_STATEMENT",
+		"+EXPRESSION_STATEMENT",
+		"+IDENTIFIER", "-IDENTIFIER",
+		"-EXPRESSION_STATEMENT",
+		"-BLOCK_STATEMENT",
+		"-MACRO_STATEMENT",
+		"+LET_DECLARATION",
+		"+IDENTIFIER", "-IDENTIFIER",
+		"+BINARY_EXPRESSION",
+		"+INTEGER_LITERAL", "-INTEGER_LITERAL",
+		"+IDENTIFIER", "-IDENTIFIER",
+		"-BINARY_EXPRESSION",
+		"-LET_DECLARATION",
+		"+ERROR", "-ERROR",
+		"-PROGRAM",
+	}
+
+	if !slices.Equal(expected, events) {
+		t.Fatalf("Walk() ==> expected: <%s> but was: <%s>", strings.Join(expected, " "), strings.Join(events, " "))
+	}
+}
+
+func TestWalkPrune(t *testing.T) {
+	visited := []string{}
+	Inspect(walkProgram(), func(node Node) bool {
+		visited = append(visited, node.Type().String())
+		return node.Type() != MACRO_STATEMENT && node.Type() != BINARY_EXPRESSION
+	})
+
+	expected := []string{
+		"PROGRAM",
+		"MACRO_STATEMENT",
+		"LET_DECLARATION",
+		"IDENTIFIER",
+		"BINARY_EXPRESSION",
+		"ERROR",
+	}
+
+	if !slices.Equal(expected, visited) {
+		t.Fatalf("Inspect() ==> expected: <%s> but was: <%s>", strings.Join(expected, " "), strings.Join(visited, " "))
+	}
+}
+
+func TestWalkPath(t *testing.T) {
+	var path []string
+	Walk(walkProgram(), WalkFuncs{
+		Pre: func(node Node, ancestors []Node) bool {
+			if id, ok := node.(*Identifier); ok && id.Value == "y" {
+				for _, ancestor := range ancestors {
+					path = append(path, ancestor.Type().String())
+				}
+			}
+			return true
+		},
+	})
+
+	expected := []string{"PROGRAM", "LET_DECLARATION", "BINARY_EXPRESSION"}
+	if !slices.Equal(expected, path) {
+		t.Fatalf("Walk() path ==> expected: <%s> but was: <%s>", strings.Join(expected, " "), strings.Join(path, " "))
+	}
+}
+
+func TestChildren(t *testing.T) {
+	call := &CallExpression{
+		Token:     token.Token{Type: token.LPAREN, Literal: "("},
+		Callee:    ident("f"),
+		Arguments: []Expression{one(), ident("x")},
+	}
+
+	fields := []string{}
+	for _, child := range Children(call) {
+		fields = append(fields, child.Field)
+	}
+
+	expected := []string{"Callee", "Arguments[0]", "Arguments[1]"}
+	if !slices.Equal(expected, fields) {
+		t.Fatalf("Children() ==> expected: <%s> but was: <%s>", strings.Join(expected, " "), strings.Join(fields, " "))
+	}
+}
+
+func TestWalkSkipsNilNodes(t *testing.T) {
+	var missing *LetDeclaration
+	program := &Program{
+		Statements: []Statement{
+			missing,
+			&ReturnStatement{Token: token.Token{Type: token.RETURN, Literal: "return"}},
+		},
+	}
+
+	count := 0
+	Inspect(program, func(node Node) bool {
+		count += 1
+		return true
+	})
+
+	if count != 2 {
+		t.Fatalf("Inspect() count ==> expected: <%d> but was: <%d>", 2, count)
+	}
+}
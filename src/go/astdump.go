@@ -0,0 +1,92 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/optimizer"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func dumpAST(args []string) int {
+	flags := flag.NewFlagSet("ast", flag.ContinueOnError)
+	format := flags.String("format", "sexpr", "output format: dot, sexpr or json")
+	expand := flags.Bool("expand", false, "expand macros before printing")
+	level := flags.Int("O", 0, fmt.Sprintf("optimization level applied after expanding macros: 0-%d", optimizer.MaxLevel))
+	names := flags.String("passes", "", "comma-separated optimization passes to run instead of a level")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var src []byte
+	var err error
+	if flags.NArg() == 0 {
+		src, err = io.ReadAll(os.Stdin)
+	} else {
+		src, err = os.ReadFile(flags.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprintln(os.Stderr, "parser errors:")
+		for _, msg := range p.Errors() {
+			fmt.Fprintln(os.Stderr, "\t"+msg)
+		}
+		return 1
+	}
+
+	passes, err := selectPasses(*level, *names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var node ast.Node = program
+	if *expand || len(passes) != 0 {
+		macros := object.NewEnvironment(nil)
+		expanded, interrupt := evaluator.ExpandMacros(evaluator.DefineMacros(program, macros), macros)
+		if interrupt != nil {
+			fmt.Fprintln(os.Stderr, interrupt.Inspect())
+			return 1
+		}
+		node = expanded
+	}
+	if expanded, ok := node.(*ast.Program); ok && len(passes) != 0 {
+		node = optimizer.Optimize(expanded, passes...)
+	}
+
+	output, err := encodeAST(node, *format)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	io.WriteString(os.Stdout, output)
+	return 0
+}
+
+func encodeAST(node ast.Node, format string) (string, error) {
+	switch format {
+	case "dot":
+		return ast.EncodeDOT(node), nil
+	case "sexpr":
+		return ast.EncodeSExpr(node), nil
+	case "json":
+		data, err := ast.EncodeJSONIndent(node, "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown format: %q", format)
+	}
+}
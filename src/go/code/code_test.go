@@ -0,0 +1,104 @@
+package code
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		op       Opcode
+		operands []int
+		expected []byte
+	}{
+		{OpConstant, []int{65534}, []byte{byte(OpConstant), 255, 254}},
+		{OpAdd, []int{}, []byte{byte(OpAdd)}},
+		{OpGetLocal, []int{255}, []byte{byte(OpGetLocal), 255}},
+		{OpQuote, []int{65535, 3}, []byte{byte(OpQuote), 255, 255, 3}},
+	}
+
+	for i, test := range tests {
+		instruction := Make(test.op, test.operands...)
+		if string(test.expected) != string(instruction) {
+			t.Errorf("test[%d] - Make() ==> expected: <%v> but was: <%v>", i, test.expected, instruction)
+		}
+	}
+}
+
+func TestReadOperands(t *testing.T) {
+	tests := []struct {
+		op        Opcode
+		operands  []int
+		bytesRead int
+	}{
+		{OpConstant, []int{65535}, 2},
+		{OpGetLocal, []int{255}, 1},
+		{OpQuote, []int{1024, 2}, 3},
+	}
+
+	for i, test := range tests {
+		instruction := Make(test.op, test.operands...)
+		def, err := Lookup(byte(test.op))
+		if err != nil {
+			t.Fatalf("test[%d] - Lookup() ==> unexpected error: %s", i, err)
+		}
+
+		operands, n := ReadOperands(def, instruction[1:])
+		if test.bytesRead != n {
+			t.Fatalf("test[%d] - bytes read ==> expected: <%d> but was: <%d>", i, test.bytesRead, n)
+		}
+		for j, expected := range test.operands {
+			if expected != operands[j] {
+				t.Errorf("test[%d] - operands[%d] ==> expected: <%d> but was: <%d>", i, j, expected, operands[j])
+			}
+		}
+	}
+}
+
+func TestInstructionsString(t *testing.T) {
+	instructions := []Instructions{
+		Make(OpAdd),
+		Make(OpGetLocal, 1),
+		Make(OpConstant, 2),
+		Make(OpQuote, 65535, 2),
+	}
+
+	expected := `0000 OpAdd
+0001 OpGetLocal 1
+0003 OpConstant 2
+0006 OpQuote 65535 2
+`
+
+	concatted := Instructions{}
+	for _, ins := range instructions {
+		concatted = append(concatted, ins...)
+	}
+
+	if expected != concatted.String() {
+		t.Fatalf("Instructions.String() ==> expected: <%q> but was: <%q>", expected, concatted.String())
+	}
+}
+
+func TestSourceMapLookup(t *testing.T) {
+	first := token.Position{Offset: 0, Line: 1, Column: 1}
+	second := token.Position{Offset: 8, Line: 2, Column: 3}
+	sourceMap := SourceMap{{Offset: 2, Position: first}, {Offset: 5, Position: second}}
+
+	tests := []struct {
+		offset   int
+		expected token.Position
+	}{
+		{0, token.Position{}},
+		{2, first},
+		{4, first},
+		{5, second},
+		{42, second},
+	}
+
+	for i, test := range tests {
+		if actual := sourceMap.Lookup(test.offset); test.expected != actual {
+			t.Errorf("test[%d] - Lookup() ==> expected: <%v> but was: <%v>", i, test.expected, actual)
+		}
+	}
+}
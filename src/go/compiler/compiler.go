@@ -0,0 +1,520 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/code"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+type Bytecode struct {
+	Instructions code.Instructions
+	SourceMap    code.SourceMap
+	Constants    []object.Object
+	Globals      []string
+}
+
+type Compiler struct {
+	constants   []object.Object
+	symbolTable *SymbolTable
+	captures    map[*ast.FunctionLiteral]map[string]bool
+
+	scopes     []CompilationScope
+	scopeIndex int
+
+	// position is where the node being compiled starts in the source.
+	position token.Position
+}
+
+type CompilationScope struct {
+	instructions code.Instructions
+	sourceMap    code.SourceMap
+}
+
+var binaryOperators = map[string]code.Opcode{
+	"+":  code.OpAdd,
+	"-":  code.OpSub,
+	"*":  code.OpMul,
+	"/":  code.OpDiv,
+	"==": code.OpEqual,
+	"!=": code.OpNotEqual,
+	">":  code.OpGreaterThan,
+	"<":  code.OpLessThan,
+}
+
+func New() *Compiler {
+	return NewWithState(NewSymbolTable(), []object.Object{})
+}
+
+func NewWithState(symbolTable *SymbolTable, constants []object.Object) *Compiler {
+	return &Compiler{
+		constants:   constants,
+		symbolTable: symbolTable,
+		captures:    map[*ast.FunctionLiteral]map[string]bool{},
+		scopes:      []CompilationScope{{instructions: code.Instructions{}}},
+	}
+}
+
+func (c *Compiler) Bytecode() *Bytecode {
+	return &Bytecode{
+		Instructions: c.currentInstructions(),
+		SourceMap:    c.scopes[c.scopeIndex].sourceMap,
+		Constants:    c.constants,
+		Globals:      c.symbolTable.Names(),
+	}
+}
+
+func (c *Compiler) Compile(node ast.Node) error {
+	switch node := node.(type) {
+	case *ast.Program:
+		for i, stmt := range node.Statements {
+			pushed, err := c.compileStatement(stmt)
+			if err != nil {
+				return err
+			}
+			if !pushed && i == len(node.Statements)-1 {
+				c.emit(code.OpNull)
+				pushed = true
+			}
+			if pushed {
+				c.emit(code.OpPop)
+			}
+		}
+		return nil
+	case ast.Statement:
+		pushed, err := c.compileStatement(node)
+		if err != nil {
+			return err
+		}
+		if pushed {
+			c.emit(code.OpPop)
+		}
+		return nil
+	case ast.Expression:
+		if err := c.compileExpression(node); err != nil {
+			return err
+		}
+		c.emit(code.OpPop)
+		return nil
+	default:
+		return fmt.Errorf("unexpected node type: %T", node)
+	}
+}
+
+func (c *Compiler) compileStatement(stmt ast.Statement) (bool, error) {
+	defer c.at(stmt)()
+
+	switch stmt := stmt.(type) {
+	case *ast.LetDeclaration:
+		if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok {
+			if err := c.compileFunction(fn, stmt.Name.Value); err != nil {
+				return false, err
+			}
+		} else if err := c.compileExpression(stmt.Value); err != nil {
+			return false, err
+		}
+		c.store(c.symbolTable.Define(stmt.Name.Value))
+		return false, nil
+	case *ast.ReturnStatement:
+		if err := c.compileExpression(stmt.ReturnValue); err != nil {
+			return false, err
+		}
+		c.emit(code.OpReturnValue)
+		return false, nil
+	case *ast.ExpressionStatement:
+		return true, c.compileExpression(stmt.Expression)
+	case *ast.BlockStatement:
+		return true, c.compileBlock(stmt)
+	case *ast.Error:
+		c.emit(code.OpError, c.addConstant(object.String(stmt.Message)))
+		return false, nil
+	case *ast.MacroStatement:
+		return false, fmt.Errorf("unexpected macro definition: %s (macros must be expanded before compiling)", stmt.Name.Value)
+	case *ast.ImportStatement:
+		return false, fmt.Errorf("import needs the eval engine")
+	default:
+		return false, fmt.Errorf("unexpected statement type: %T", stmt)
+	}
+}
+
+func (c *Compiler) compileBlock(block *ast.BlockStatement) error {
+	if block == nil || len(block.Statements) == 0 {
+		c.emit(code.OpNull)
+		return nil
+	}
+
+	for i, stmt := range block.Statements {
+		pushed, err := c.compileStatement(stmt)
+		if err != nil {
+			return err
+		}
+
+		last := i == len(block.Statements)-1
+		switch {
+		case pushed && !last:
+			c.emit(code.OpPop)
+		case !pushed && last:
+			c.emit(code.OpNull)
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) compileExpression(expr ast.Expression) error {
+	if expr != nil {
+		defer c.at(expr)()
+	}
+
+	switch expr := expr.(type) {
+	case nil:
+		c.emit(code.OpNull)
+	case *ast.IntegerLiteral:
+		if expr.Big != nil {
+			c.emit(code.OpConstant, c.addConstant(&object.BigInt{Value: expr.Big}))
+		} else {
+			c.emit(code.OpConstant, c.addConstant(object.Integer(expr.Value)))
+		}
+	case *ast.DecimalLiteral:
+		c.emit(code.OpConstant, c.addConstant(&object.Decimal{Value: expr.Value}))
+	case *ast.FloatLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.Float(expr.Value)))
+	case *ast.StringLiteral:
+		c.emit(code.OpConstant, c.addConstant(object.String(expr.Value)))
+	case *ast.BooleanLiteral:
+		if expr.Value {
+			c.emit(code.OpTrue)
+		} else {
+			c.emit(code.OpFalse)
+		}
+	case *ast.NullLiteral:
+		c.emit(code.OpNull)
+	case *ast.Identifier:
+		c.load(c.symbolTable.Resolve(expr.Value))
+	case *ast.UnaryExpression:
+		if err := c.compileExpression(expr.Right); err != nil {
+			return err
+		}
+		switch expr.Operator {
+		case "-":
+			c.emit(code.OpMinus)
+		case "!":
+			c.emit(code.OpBang)
+		default:
+			return fmt.Errorf("unknown operator: %s", expr.Operator)
+		}
+	case *ast.BinaryExpression:
+		return c.compileBinary(expr.Left, expr.Operator, expr.Right)
+	case *ast.LogicalExpression:
+		var jump code.Opcode
+		switch expr.Operator {
+		case "or":
+			jump = code.OpJumpTruthy
+		case "and":
+			jump = code.OpJumpFalsy
+		default:
+			return c.compileBinary(expr.Left, expr.Operator, expr.Right)
+		}
+
+		if err := c.compileExpression(expr.Left); err != nil {
+			return err
+		}
+		pos := c.emit(jump, 0xFFFF)
+		if err := c.compileExpression(expr.Right); err != nil {
+			return err
+		}
+		c.patch(pos)
+	case *ast.ConditionalExpression:
+		if err := c.compileExpression(expr.Condition); err != nil {
+			return err
+		}
+		jumpNotTruthy := c.emit(code.OpJumpNotTruthy, 0xFFFF)
+		if err := c.compileBlock(expr.Consequence); err != nil {
+			return err
+		}
+		jump := c.emit(code.OpJump, 0xFFFF)
+		c.patch(jumpNotTruthy)
+		if err := c.compileBlock(expr.Alternative); err != nil {
+			return err
+		}
+		c.patch(jump)
+	case *ast.FunctionLiteral:
+		return c.compileFunction(expr, "")
+	case *ast.CallExpression:
+		return c.compileCall(expr)
+	case *ast.AssignmentExpression:
+		return c.compileAssignment(expr)
+	case *ast.SubscriptExpression:
+		if err := c.compileExpression(expr.Base); err != nil {
+			return err
+		}
+		if err := c.compileExpression(expr.Subscript); err != nil {
+			return err
+		}
+		c.emit(code.OpIndex)
+	case *ast.ArrayLiteral:
+		for _, elem := range expr.Elements {
+			if err := c.compileExpression(elem); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpArray, len(expr.Elements))
+	case *ast.HashLiteral:
+		for _, key := range expr.Keys {
+			if err := c.compileExpression(key); err != nil {
+				return err
+			}
+			if err := c.compileExpression(expr.Pairs[key]); err != nil {
+				return err
+			}
+		}
+		c.emit(code.OpHash, len(expr.Keys))
+	case *ast.Error:
+		c.emit(code.OpError, c.addConstant(object.String(expr.Message)))
+	case *ast.YieldExpression:
+		return fmt.Errorf("yield needs the eval engine")
+	default:
+		return fmt.Errorf("unexpected expression type: %T", expr)
+	}
+	return nil
+}
+
+func (c *Compiler) compileBinary(left ast.Expression, operator string, right ast.Expression) error {
+	if err := c.compileExpression(left); err != nil {
+		return err
+	}
+	if err := c.compileExpression(right); err != nil {
+		return err
+	}
+
+	if op, ok := binaryOperators[operator]; ok {
+		c.emit(op)
+	} else {
+		c.emit(code.OpBinary, c.addConstant(object.String(operator)))
+	}
+	return nil
+}
+
+func (c *Compiler) compileFunction(fn *ast.FunctionLiteral, name string) error {
+	captured, ok := c.captures[fn]
+	if !ok {
+		analyze(fn, nil, c.captures)
+		captured = c.captures[fn]
+	}
+
+	c.enterScope(NewEnclosedSymbolTable(c.symbolTable, captured))
+
+	for _, param := range fn.Parameters {
+		local, boxed := c.symbolTable.DefineParameter(param.Value)
+		if boxed {
+			c.emit(code.OpGetLocal, local.Index)
+			c.store(c.symbolTable.Resolve(param.Value))
+		}
+	}
+	for _, name := range hoisted(fn.Body) {
+		c.symbolTable.Define(name)
+	}
+
+	if err := c.compileBlock(fn.Body); err != nil {
+		c.leaveScope()
+		return err
+	}
+	c.emit(code.OpReturnValue)
+
+	table := c.symbolTable
+	instructions, sourceMap := c.leaveScope()
+
+	captures := make([]object.Capture, len(table.Free))
+	for i, free := range table.Free {
+		captures[i] = object.Capture{Cell: free.Scope == CellScope, Index: free.Index, Name: free.Name}
+	}
+
+	compiled := &object.CompiledFunction{
+		Name:          name,
+		Literal:       fn,
+		Instructions:  instructions,
+		SourceMap:     sourceMap,
+		NumParameters: len(fn.Parameters),
+		NumLocals:     len(table.Names()),
+		NumCells:      len(table.Cells()),
+		Locals:        table.Names(),
+		Cells:         table.Cells(),
+		Captures:      captures,
+	}
+	c.emit(code.OpClosure, c.addConstant(compiled))
+	return nil
+}
+
+func (c *Compiler) compileCall(call *ast.CallExpression) error {
+	if callee, ok := call.Callee.(*ast.Identifier); ok && !c.symbolTable.Defined(callee.Value) {
+		if builtin, ok := evaluator.LookupBuiltin(callee.Value); ok {
+			if _, ok := builtin.(*object.BuiltinMacro); ok {
+				return c.compileMacroCall(callee, call)
+			}
+		}
+	}
+
+	if err := c.compileExpression(call.Callee); err != nil {
+		return err
+	}
+	for _, arg := range call.Arguments {
+		if err := c.compileExpression(arg); err != nil {
+			return err
+		}
+	}
+	c.emit(code.OpCall, len(call.Arguments))
+	return nil
+}
+
+func (c *Compiler) compileMacroCall(callee *ast.Identifier, call *ast.CallExpression) error {
+	if callee.Value == "quote" && len(call.Arguments) == 1 {
+		return c.compileQuote(call.Arguments[0])
+	}
+
+	c.load(c.symbolTable.Resolve(callee.Value))
+	for _, arg := range call.Arguments {
+		c.emit(code.OpConstant, c.addConstant(&object.Quote{Node: arg}))
+	}
+	c.emit(code.OpCall, len(call.Arguments))
+	return nil
+}
+
+func (c *Compiler) compileQuote(node ast.Expression) error {
+	template := &Template{Node: node, Holes: map[ast.Node]int{}, Errors: map[ast.Node]string{}}
+
+	var err error
+	ast.Inspect(node, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || call.Callee.TokenLiteral() != "unquote" || err != nil {
+			return err == nil
+		}
+
+		if len(call.Arguments) != 1 {
+			template.Errors[call] = unquoteError(call)
+			return false
+		}
+
+		template.Holes[call] = len(template.Holes)
+		err = c.compileExpression(call.Arguments[0])
+		return false
+	})
+	if err != nil {
+		return err
+	}
+
+	c.emit(code.OpQuote, c.addConstant(template), len(template.Holes))
+	return nil
+}
+
+func (c *Compiler) compileAssignment(expr *ast.AssignmentExpression) error {
+	if err := c.compileExpression(expr.RValue); err != nil {
+		return err
+	}
+
+	switch lvalue := expr.LValue.(type) {
+	case *ast.Identifier:
+		c.emit(code.OpDup)
+		symbol := c.symbolTable.Resolve(lvalue.Value)
+		if symbol.Scope == GlobalScope {
+			c.emit(code.OpAssignGlobal, symbol.Index)
+		} else {
+			c.store(symbol)
+		}
+	case *ast.SubscriptExpression:
+		if err := c.compileExpression(lvalue.Base); err != nil {
+			return err
+		}
+		if err := c.compileExpression(lvalue.Subscript); err != nil {
+			return err
+		}
+		c.emit(code.OpSetIndex)
+	default:
+		return fmt.Errorf("unknown lvalue type: %s", expr.LValue.Type())
+	}
+	return nil
+}
+
+func (c *Compiler) load(symbol Symbol) {
+	switch symbol.Scope {
+	case GlobalScope:
+		c.emit(code.OpGetGlobal, symbol.Index)
+	case LocalScope:
+		c.emit(code.OpGetLocal, symbol.Index)
+	case CellScope:
+		c.emit(code.OpGetCell, symbol.Index)
+	case FreeScope:
+		c.emit(code.OpGetFree, symbol.Index)
+	}
+}
+
+func (c *Compiler) store(symbol Symbol) {
+	switch symbol.Scope {
+	case GlobalScope:
+		c.emit(code.OpSetGlobal, symbol.Index)
+	case LocalScope:
+		c.emit(code.OpSetLocal, symbol.Index)
+	case CellScope:
+		c.emit(code.OpSetCell, symbol.Index)
+	case FreeScope:
+		c.emit(code.OpSetFree, symbol.Index)
+	}
+}
+
+func (c *Compiler) addConstant(obj object.Object) int {
+	c.constants = append(c.constants, obj)
+	return len(c.constants) - 1
+}
+
+func (c *Compiler) emit(op code.Opcode, operands ...int) int {
+	ins := code.Make(op, operands...)
+	pos := len(c.currentInstructions())
+	c.scopes[c.scopeIndex].instructions = append(c.currentInstructions(), ins...)
+	c.mark(pos)
+	return pos
+}
+
+// mark records that the instruction at pos was compiled from the current
+// position, unless the instructions before it were too.
+func (c *Compiler) mark(pos int) {
+	scope := &c.scopes[c.scopeIndex]
+	if n := len(scope.sourceMap); n > 0 && scope.sourceMap[n-1].Position == c.position {
+		return
+	}
+	scope.sourceMap = append(scope.sourceMap, code.Mark{Offset: pos, Position: c.position})
+}
+
+// at makes node the current position, if it has one, until the returned
+// function restores the previous one.
+func (c *Compiler) at(node ast.Node) func() {
+	outer := c.position
+	if position := ast.Position(node); position.IsValid() {
+		c.position = position
+	}
+	return func() { c.position = outer }
+}
+
+func (c *Compiler) patch(pos int) {
+	target := len(c.currentInstructions())
+	op := code.Opcode(c.currentInstructions()[pos])
+	copy(c.currentInstructions()[pos:], code.Make(op, target))
+}
+
+func (c *Compiler) currentInstructions() code.Instructions {
+	return c.scopes[c.scopeIndex].instructions
+}
+
+func (c *Compiler) enterScope(symbolTable *SymbolTable) {
+	c.scopes = append(c.scopes, CompilationScope{instructions: code.Instructions{}})
+	c.scopeIndex += 1
+	c.symbolTable = symbolTable
+}
+
+func (c *Compiler) leaveScope() (code.Instructions, code.SourceMap) {
+	scope := c.scopes[c.scopeIndex]
+	c.scopes = c.scopes[:len(c.scopes)-1]
+	c.scopeIndex -= 1
+	c.symbolTable = c.symbolTable.Outer
+	return scope.instructions, scope.sourceMap
+}
@@ -0,0 +1,128 @@
+package compiler
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/code"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func concat(instructions ...[]byte) code.Instructions {
+	out := code.Instructions{}
+	for _, ins := range instructions {
+		out = append(out, ins...)
+	}
+	return out
+}
+
+func compile(t *testing.T, input string) *Bytecode {
+	c := New()
+	if err := c.Compile(parser.NewParser(input, false).ParseProgram()); err != nil {
+		t.Fatalf("Compile() ==> unexpected error: %s", err)
+	}
+	return c.Bytecode()
+}
+
+func TestCompileInstructions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected code.Instructions
+	}{
+		{
+			"1 + 2",
+			concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpAdd),
+				code.Make(code.OpPop),
+			),
+		},
+		{
+			"let x = 1; x",
+			concat(
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpSetGlobal, 0),
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpPop),
+			),
+		},
+		{
+			"if (true) { 10 }; 3",
+			concat(
+				code.Make(code.OpTrue),
+				code.Make(code.OpJumpNotTruthy, 10),
+				code.Make(code.OpConstant, 0),
+				code.Make(code.OpJump, 11),
+				code.Make(code.OpNull),
+				code.Make(code.OpPop),
+				code.Make(code.OpConstant, 1),
+				code.Make(code.OpPop),
+			),
+		},
+		{
+			"a or b",
+			concat(
+				code.Make(code.OpGetGlobal, 0),
+				code.Make(code.OpGetGlobal, 1),
+				code.Make(code.OpBinary, 0),
+				code.Make(code.OpPop),
+			),
+		},
+	}
+
+	for i, test := range tests {
+		actual := compile(t, test.input).Instructions
+		if test.expected.String() != actual.String() {
+			t.Errorf("test[%d] - Instructions ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+}
+
+func TestCompileCaptures(t *testing.T) {
+	bytecode := compile(t, `fn(a, b) { let c = 1; fn() { a + c } }`)
+
+	outer := bytecode.Constants[len(bytecode.Constants)-1].(*object.CompiledFunction)
+	if outer.NumLocals != 2 || outer.NumCells != 2 {
+		t.Fatalf("outer ==> expected: <2 locals, 2 cells> but was: <%d locals, %d cells>", outer.NumLocals, outer.NumCells)
+	}
+
+	var inner *object.CompiledFunction
+	for _, constant := range bytecode.Constants {
+		if fn, ok := constant.(*object.CompiledFunction); ok && fn != outer {
+			inner = fn
+		}
+	}
+
+	expected := []object.Capture{{Cell: true, Index: 0, Name: "a"}, {Cell: true, Index: 1, Name: "c"}}
+	if len(expected) != len(inner.Captures) {
+		t.Fatalf("len(inner.Captures) ==> expected: <%d> but was: <%d>", len(expected), len(inner.Captures))
+	}
+	for i, capture := range expected {
+		if capture != inner.Captures[i] {
+			t.Errorf("inner.Captures[%d] ==> expected: <%+v> but was: <%+v>", i, capture, inner.Captures[i])
+		}
+	}
+}
+
+func TestCompileSourceMap(t *testing.T) {
+	bytecode := compile(t, "let x = 1;\nx + true")
+
+	tests := []struct {
+		offset   int
+		expected string
+	}{
+		{0, "1:9"},
+		{3, "1:1"},
+		{6, "2:1"},
+		{9, "2:5"},
+		{10, "2:3"},
+		{11, "-"},
+	}
+
+	for i, test := range tests {
+		if actual := bytecode.SourceMap.Lookup(test.offset).String(); test.expected != actual {
+			t.Errorf("test[%d] - SourceMap.Lookup(%d) ==> expected: <%s> but was: <%s>", i, test.offset, test.expected, actual)
+		}
+	}
+}
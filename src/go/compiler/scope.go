@@ -0,0 +1,60 @@
+package compiler
+
+import "github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+
+type scope struct {
+	parent   *scope
+	names    map[string]bool
+	captured map[string]bool
+}
+
+func analyze(fn *ast.FunctionLiteral, parent *scope, captures map[*ast.FunctionLiteral]map[string]bool) {
+	s := &scope{parent: parent, names: map[string]bool{}, captured: map[string]bool{}}
+	for _, param := range fn.Parameters {
+		s.names[param.Value] = true
+	}
+	for _, name := range hoisted(fn.Body) {
+		s.names[name] = true
+	}
+	captures[fn] = s.captured
+
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			analyze(node, s, captures)
+			return false
+		case *ast.Identifier:
+			s.reference(node.Value)
+		}
+		return true
+	})
+}
+
+func (s *scope) reference(name string) {
+	if s.names[name] {
+		return
+	}
+	for enclosing := s.parent; enclosing != nil; enclosing = enclosing.parent {
+		if enclosing.names[name] {
+			enclosing.captured[name] = true
+			return
+		}
+	}
+}
+
+func hoisted(body *ast.BlockStatement) []string {
+	names := []string{}
+	if body == nil {
+		return names
+	}
+	ast.Inspect(body, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.LetDeclaration:
+			names = append(names, node.Name.Value)
+		}
+		return true
+	})
+	return names
+}
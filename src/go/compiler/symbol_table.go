@@ -0,0 +1,117 @@
+package compiler
+
+import "fmt"
+
+type SymbolScope int
+
+const (
+	GlobalScope SymbolScope = iota
+	LocalScope
+	CellScope
+	FreeScope
+)
+
+type Symbol struct {
+	Name  string
+	Scope SymbolScope
+	Index int
+}
+
+type SymbolTable struct {
+	Outer *SymbolTable
+
+	store    map[string]Symbol
+	captured map[string]bool
+
+	names []string
+	cells []string
+	Free  []Symbol
+}
+
+func NewSymbolTable() *SymbolTable {
+	return &SymbolTable{store: map[string]Symbol{}}
+}
+
+func NewEnclosedSymbolTable(outer *SymbolTable, captured map[string]bool) *SymbolTable {
+	st := NewSymbolTable()
+	st.Outer = outer
+	st.captured = captured
+	return st
+}
+
+func (st *SymbolTable) Define(name string) Symbol {
+	if symbol, ok := st.store[name]; ok && symbol.Scope != FreeScope {
+		return symbol
+	}
+
+	var symbol Symbol
+	switch {
+	case st.Outer == nil:
+		symbol = Symbol{Name: name, Scope: GlobalScope, Index: len(st.names)}
+		st.names = append(st.names, name)
+	case st.captured[name]:
+		symbol = Symbol{Name: name, Scope: CellScope, Index: len(st.cells)}
+		st.cells = append(st.cells, name)
+	default:
+		symbol = Symbol{Name: name, Scope: LocalScope, Index: len(st.names)}
+		st.names = append(st.names, name)
+	}
+	st.store[name] = symbol
+	return symbol
+}
+
+func (st *SymbolTable) DefineParameter(name string) (Symbol, bool) {
+	local := Symbol{Name: name, Scope: LocalScope, Index: len(st.names)}
+	st.names = append(st.names, name)
+	st.store[name] = local
+	if !st.captured[name] {
+		return local, false
+	}
+
+	delete(st.store, name)
+	st.Define(name)
+	return local, true
+}
+
+func (st *SymbolTable) Resolve(name string) Symbol {
+	if symbol, ok := st.store[name]; ok {
+		return symbol
+	}
+
+	if st.Outer == nil {
+		return st.Define(name)
+	}
+
+	symbol := st.Outer.Resolve(name)
+	if symbol.Scope == GlobalScope {
+		return symbol
+	}
+	if symbol.Scope == LocalScope {
+		panic(fmt.Errorf("local %q captured without a cell", name))
+	}
+	return st.defineFree(symbol)
+}
+
+func (st *SymbolTable) Defined(name string) bool {
+	for table := st; table != nil; table = table.Outer {
+		if _, ok := table.store[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (st *SymbolTable) Names() []string {
+	return st.names
+}
+
+func (st *SymbolTable) Cells() []string {
+	return st.cells
+}
+
+func (st *SymbolTable) defineFree(original Symbol) Symbol {
+	st.Free = append(st.Free, original)
+	symbol := Symbol{Name: original.Name, Scope: FreeScope, Index: len(st.Free) - 1}
+	st.store[original.Name] = symbol
+	return symbol
+}
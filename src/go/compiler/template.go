@@ -0,0 +1,42 @@
+package compiler
+
+import (
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+)
+
+type Template struct {
+	Node   ast.Expression
+	Holes  map[ast.Node]int
+	Errors map[ast.Node]string
+}
+
+func (t *Template) Type() object.ObjectType {
+	return object.QUOTE
+}
+
+func (t *Template) Inspect() string {
+	return "TEMPLATE(" + t.Node.String() + ")"
+}
+
+func (t *Template) Fill(values []object.Object, toNode func(object.Object) ast.Expression) ast.Expression {
+	return ast.Rewrite(t.Node, func(node ast.Node) ast.Node {
+		if i, ok := t.Holes[node]; ok {
+			return toNode(values[i])
+		}
+		if message, ok := t.Errors[node]; ok {
+			return &ast.Error{Message: message}
+		}
+		return node
+	}).(ast.Expression)
+}
+
+func unquoteError(call *ast.CallExpression) string {
+	types := make([]string, len(call.Arguments))
+	for i := range call.Arguments {
+		types[i] = object.BUILTIN.String()
+	}
+	return "argument(s) to `unquote` not supported: (" + strings.Join(types, ", ") + ")"
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/coverage"
+)
+
+// coverFlags are the coverage flags shared by run and test.
+type coverFlags struct {
+	cover bool
+	lcov  string
+	html  string
+}
+
+func (c *coverFlags) register(flags *flag.FlagSet) {
+	flags.BoolVar(&c.cover, "cover", false, "record which statements and branches run and print a summary (eval only)")
+	flags.StringVar(&c.lcov, "coverprofile", "", "write the coverage as an lcov tracefile to this file (implies -cover)")
+	flags.StringVar(&c.html, "coverhtml", "", "write the coverage as an HTML report to this file (implies -cover)")
+}
+
+func (c *coverFlags) enabled() bool {
+	return c.cover || c.lcov != "" || c.html != ""
+}
+
+// report prints the summary of profiles to out and writes the files asked
+// for.
+func (c *coverFlags) report(out io.Writer, profiles []*coverage.Profile) error {
+	if err := coverage.WriteText(out, profiles); err != nil {
+		return err
+	}
+	if c.lcov != "" {
+		if err := writeCoverage(c.lcov, coverage.WriteLCOV, profiles); err != nil {
+			return err
+		}
+	}
+	if c.html != "" {
+		if err := writeCoverage(c.html, coverage.WriteHTML, profiles); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCoverage(path string, write func(io.Writer, []*coverage.Profile) error, profiles []*coverage.Profile) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, profiles); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
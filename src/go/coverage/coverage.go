@@ -0,0 +1,223 @@
+package coverage
+
+import (
+	"sort"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+// Statement counts how many times the statement at Position ran.
+type Statement struct {
+	Position token.Position
+	Count    int64
+}
+
+// Branch counts how many times the conditional at Position took its
+// consequence and its alternative, which it has even when it has no else.
+type Branch struct {
+	Position    token.Position
+	Consequence int64
+	Alternative int64
+}
+
+// Line is what ran of the statements and conditionals starting on a line.
+type Line struct {
+	Number     int
+	Count      int64
+	Statements int
+	Covered    int
+	Branches   int
+	Taken      int
+}
+
+// Partial reports whether only some of the line ran.
+func (l Line) Partial() bool {
+	return l.Covered != 0 && (l.Covered != l.Statements || l.Taken != l.Branches)
+}
+
+// Nodes are found by position and type rather than identity, since macro
+// expansion and optimization evaluate copies of the parsed program.
+type key struct {
+	position token.Position
+	node     ast.NodeType
+}
+
+// Profile observes the evaluation of one source file and counts the
+// statements and branches that ran.
+type Profile struct {
+	object.BaseObserver
+
+	Filename   string
+	Source     string
+	Statements []*Statement
+	Branches   []*Branch
+
+	statements  map[key]*Statement
+	expressions map[key]*Statement
+	branches    map[key]*Branch
+	conditions  map[ast.Node]*Branch
+	last        *Statement
+}
+
+// New finds the statements and conditionals of program, which was parsed
+// from source; the bodies of macros are left out, since they run while the
+// program is expanded.
+func New(filename string, source string, program *ast.Program) *Profile {
+	p := &Profile{
+		Filename:    filename,
+		Source:      source,
+		statements:  map[key]*Statement{},
+		expressions: map[key]*Statement{},
+		branches:    map[key]*Branch{},
+		conditions:  map[ast.Node]*Branch{},
+	}
+
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.MacroStatement:
+			return false
+		case *ast.LetDeclaration, *ast.ReturnStatement:
+			p.statement(node)
+		case *ast.ExpressionStatement:
+			s := p.statement(node)
+			if s != nil && node.Expression != nil {
+				p.expressions[key{ast.Position(node.Expression), node.Expression.Type()}] = s
+			}
+		case *ast.ConditionalExpression:
+			b := &Branch{Position: ast.Position(node)}
+			p.branches[key{b.Position, node.Type()}] = b
+			p.Branches = append(p.Branches, b)
+		}
+		return true
+	})
+
+	sort.SliceStable(p.Statements, func(i, j int) bool {
+		return p.Statements[i].Position.Offset < p.Statements[j].Position.Offset
+	})
+	sort.SliceStable(p.Branches, func(i, j int) bool {
+		return p.Branches[i].Position.Offset < p.Branches[j].Position.Offset
+	})
+	return p
+}
+
+func (p *Profile) statement(node ast.Node) *Statement {
+	position := ast.Position(node)
+	if !position.IsValid() {
+		return nil
+	}
+	s := &Statement{Position: position}
+	p.statements[key{position, node.Type()}] = s
+	p.Statements = append(p.Statements, s)
+	return s
+}
+
+// Enter counts statements, including the last expression of a body, which
+// is evaluated in tail position without its statement. The statement just
+// entered is remembered so that entering its expression does not count it
+// again.
+func (p *Profile) Enter(node ast.Node, env *object.Environment) {
+	k := key{ast.Position(node), node.Type()}
+	last := p.last
+	p.last = nil
+	if s, ok := p.statements[k]; ok {
+		s.Count += 1
+		p.last = s
+	} else if s, ok := p.expressions[k]; ok {
+		if s != last {
+			s.Count += 1
+		}
+		p.last = s
+	}
+
+	if conditional, ok := node.(*ast.ConditionalExpression); ok {
+		if b, ok := p.branches[k]; ok {
+			p.conditions[conditional.Condition] = b
+		}
+	}
+}
+
+// Exit counts the branch a conditional takes once its condition evaluates.
+func (p *Profile) Exit(node ast.Node, env *object.Environment, value object.Object, interrupt object.Interruption) {
+	if interrupt != nil {
+		return
+	}
+	if b, ok := p.conditions[node]; ok {
+		if evaluator.IsTruthy(value) {
+			b.Consequence += 1
+		} else {
+			b.Alternative += 1
+		}
+	}
+}
+
+// StatementsCovered returns how many statements ran and how many there are.
+func (p *Profile) StatementsCovered() (int, int) {
+	covered := 0
+	for _, s := range p.Statements {
+		if s.Count != 0 {
+			covered += 1
+		}
+	}
+	return covered, len(p.Statements)
+}
+
+// BranchesCovered returns how many branches were taken and how many there
+// are, two for every conditional.
+func (p *Profile) BranchesCovered() (int, int) {
+	taken := 0
+	for _, b := range p.Branches {
+		taken += b.taken()
+	}
+	return taken, 2 * len(p.Branches)
+}
+
+func (b *Branch) taken() int {
+	taken := 0
+	if b.Consequence != 0 {
+		taken += 1
+	}
+	if b.Alternative != 0 {
+		taken += 1
+	}
+	return taken
+}
+
+// Lines returns the lines on which statements or conditionals start, in
+// order. A line's count is that of the statement on it that ran most.
+func (p *Profile) Lines() []Line {
+	lines := map[int]*Line{}
+	line := func(number int) *Line {
+		l, ok := lines[number]
+		if !ok {
+			l = &Line{Number: number}
+			lines[number] = l
+		}
+		return l
+	}
+
+	for _, s := range p.Statements {
+		l := line(s.Position.Line)
+		l.Statements += 1
+		if s.Count != 0 {
+			l.Covered += 1
+		}
+		l.Count = max(l.Count, s.Count)
+	}
+	for _, b := range p.Branches {
+		l := line(b.Position.Line)
+		l.Branches += 2
+		l.Taken += b.taken()
+	}
+
+	result := []Line{}
+	for _, l := range lines {
+		result = append(result, *l)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Number < result[j].Number
+	})
+	return result
+}
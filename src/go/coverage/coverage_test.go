@@ -0,0 +1,183 @@
+package coverage
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+const source = `let abs = fn(n) {
+  if (n < 0) {
+    return -n;
+  }
+  n
+};
+let sign = fn(n) { if (n < 0) { -1 } else { 1 } };
+let unused = fn() {
+  puts("never");
+};
+macro unless(c, body) { quote(if (!(unquote(c))) { unquote(body) }) };
+unless(abs(-2) == 2, unused());
+sign(abs(3));`
+
+func cover(t *testing.T, count int) []*Profile {
+	p := parser.NewParser(source, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("p.Errors() ==> %v", p.Errors())
+	}
+
+	profiles := []*Profile{}
+	observers := object.Observers{}
+	for range count {
+		profile := New("abs.mk", source, program)
+		profiles = append(profiles, profile)
+		observers = append(observers, profile)
+	}
+	e := engine.NewEvaluator()
+	e.SetObserver(observers)
+	if _, interrupt := e.Run(program); interrupt != nil {
+		t.Fatalf("Run() ==> %s", interrupt.Inspect())
+	}
+	return profiles
+}
+
+func TestStatements(t *testing.T) {
+	profile := cover(t, 1)[0]
+
+	tests := []struct {
+		position string
+		count    int64
+	}{
+		{"1:1", 1},
+		{"2:3", 2},
+		{"3:5", 1},
+		{"5:3", 1},
+		{"7:1", 1},
+		{"7:20", 1},
+		{"7:33", 0},
+		{"7:45", 1},
+		{"8:1", 1},
+		{"9:3", 0},
+		{"12:1", 1},
+		{"13:1", 1},
+	}
+
+	if len(profile.Statements) != len(tests) {
+		t.Fatalf("len(Statements) ==> expected: <%d> but was: <%d>", len(tests), len(profile.Statements))
+	}
+	for i, test := range tests {
+		s := profile.Statements[i]
+		if test.position != s.Position.String() || test.count != s.Count {
+			t.Errorf("test[%d] - Statement ==> expected: <%s %d> but was: <%s %d>", i, test.position, test.count, s.Position, s.Count)
+		}
+	}
+
+	covered, total := profile.StatementsCovered()
+	if covered != 10 || total != 12 {
+		t.Errorf("StatementsCovered() ==> expected: <10/12> but was: <%d/%d>", covered, total)
+	}
+}
+
+func TestBranches(t *testing.T) {
+	profile := cover(t, 1)[0]
+
+	expected := "[2:3 1/1 7:20 0/1]"
+	actual := []string{}
+	for _, b := range profile.Branches {
+		actual = append(actual, fmt.Sprintf("%s %d/%d", b.Position, b.Consequence, b.Alternative))
+	}
+	if expected != fmt.Sprint(actual) {
+		t.Errorf("Branches ==> expected: <%s> but was: <%v>", expected, actual)
+	}
+
+	taken, total := profile.BranchesCovered()
+	if taken != 3 || total != 4 {
+		t.Errorf("BranchesCovered() ==> expected: <3/4> but was: <%d/%d>", taken, total)
+	}
+}
+
+func TestObservers(t *testing.T) {
+	profiles := cover(t, 2)
+
+	for i, s := range profiles[0].Statements {
+		if s.Count != profiles[1].Statements[i].Count {
+			t.Errorf("test[%d] - Statement.Count ==> expected: <%d> but was: <%d>", i, s.Count, profiles[1].Statements[i].Count)
+		}
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	profile := cover(t, 1)[0]
+
+	out := &strings.Builder{}
+	if err := WriteText(out, []*Profile{profile, profile}); err != nil {
+		t.Fatal(err)
+	}
+	expected := "abs.mk\tstatements 83.3% (10/12)\tbranches 75.0% (3/4)\tnot run: 9\n" +
+		"abs.mk\tstatements 83.3% (10/12)\tbranches 75.0% (3/4)\tnot run: 9\n" +
+		"total\tstatements 83.3% (20/24)\tbranches 75.0% (6/8)\n"
+	if expected != out.String() {
+		t.Errorf("WriteText() ==> expected: <%s> but was: <%s>", expected, out.String())
+	}
+}
+
+func TestWriteLCOV(t *testing.T) {
+	profile := cover(t, 1)[0]
+
+	out := &strings.Builder{}
+	if err := WriteLCOV(out, []*Profile{profile}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `TN:
+SF:abs.mk
+BRDA:2,0,0,1
+BRDA:2,0,1,1
+BRDA:7,1,0,0
+BRDA:7,1,1,1
+BRF:4
+BRH:3
+DA:1,1
+DA:2,2
+DA:3,1
+DA:5,1
+DA:7,1
+DA:8,1
+DA:9,0
+DA:12,1
+DA:13,1
+LF:9
+LH:8
+end_of_record
+`
+	if expected != out.String() {
+		t.Errorf("WriteLCOV() ==> expected: <%s> but was: <%s>", expected, out.String())
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	profile := cover(t, 1)[0]
+
+	out := &strings.Builder{}
+	if err := WriteHTML(out, []*Profile{profile}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []string{
+		`<h2>abs.mk</h2>`,
+		`<p>statements 83.3% (10/12), branches 75.0% (3/4)</p>`,
+		`<span class="line run"><span class="number">2</span><span class="count">2x</span>  if (n &lt; 0) {</span>`,
+		`<span class="line partial"><span class="number">7</span><span class="count">1x</span>`,
+		`<span class="line unrun"><span class="number">9</span><span class="count">0x</span>  puts(&#34;never&#34;);</span>`,
+		`<span class="line "><span class="number">10</span><span class="count"></span>};</span>`,
+	}
+	for i, test := range tests {
+		if !strings.Contains(out.String(), test) {
+			t.Errorf("test[%d] - WriteHTML() ==> expected to contain: <%s> but was: <%s>", i, test, out.String())
+		}
+	}
+}
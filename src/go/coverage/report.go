@@ -0,0 +1,176 @@
+package coverage
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteText writes a line per profile with the share of its statements and
+// branches that ran and the lines that did not, followed by a total when
+// there is more than one profile.
+func WriteText(w io.Writer, profiles []*Profile) error {
+	out := bufio.NewWriter(w)
+	covered, statements, taken, branches := 0, 0, 0, 0
+	for _, p := range profiles {
+		c, s := p.StatementsCovered()
+		t, b := p.BranchesCovered()
+		covered, statements, taken, branches = covered+c, statements+s, taken+t, branches+b
+
+		fmt.Fprintf(out, "%s\tstatements %s\tbranches %s", p.Filename, percent(c, s), percent(t, b))
+		if lines := notRun(p.Lines()); lines != "" {
+			fmt.Fprintf(out, "\tnot run: %s", lines)
+		}
+		fmt.Fprintln(out)
+	}
+	if len(profiles) > 1 {
+		fmt.Fprintf(out, "total\tstatements %s\tbranches %s\n", percent(covered, statements), percent(taken, branches))
+	}
+	return out.Flush()
+}
+
+func percent(covered, total int) string {
+	if total == 0 {
+		return "- (0/0)"
+	}
+	return fmt.Sprintf("%.1f%% (%d/%d)", 100*float64(covered)/float64(total), covered, total)
+}
+
+// notRun lists the lines none of whose statements ran, joining consecutive
+// lines into ranges.
+func notRun(lines []Line) string {
+	ranges := []string{}
+	start, end := 0, 0
+	flush := func() {
+		if start == 0 {
+			return
+		}
+		if start == end {
+			ranges = append(ranges, strconv.Itoa(start))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", start, end))
+		}
+		start = 0
+	}
+
+	for _, l := range lines {
+		if l.Statements == 0 {
+			continue
+		}
+		if l.Covered != 0 {
+			flush()
+			continue
+		}
+		if start == 0 {
+			start = l.Number
+		}
+		end = l.Number
+	}
+	flush()
+	return strings.Join(ranges, ", ")
+}
+
+// WriteLCOV writes the profiles as an lcov tracefile, with a DA record per
+// line holding statements and a BRDA record per branch.
+func WriteLCOV(w io.Writer, profiles []*Profile) error {
+	out := bufio.NewWriter(w)
+	for _, p := range profiles {
+		fmt.Fprintln(out, "TN:")
+		fmt.Fprintf(out, "SF:%s\n", p.Filename)
+
+		for i, b := range p.Branches {
+			for j, count := range []int64{b.Consequence, b.Alternative} {
+				taken := "-"
+				if b.Consequence+b.Alternative != 0 {
+					taken = strconv.FormatInt(count, 10)
+				}
+				fmt.Fprintf(out, "BRDA:%d,%d,%d,%s\n", b.Position.Line, i, j, taken)
+			}
+		}
+		taken, branches := p.BranchesCovered()
+		fmt.Fprintf(out, "BRF:%d\nBRH:%d\n", branches, taken)
+
+		found, hit := 0, 0
+		for _, l := range p.Lines() {
+			if l.Statements == 0 {
+				continue
+			}
+			found += 1
+			if l.Count != 0 {
+				hit += 1
+			}
+			fmt.Fprintf(out, "DA:%d,%d\n", l.Number, l.Count)
+		}
+		fmt.Fprintf(out, "LF:%d\nLH:%d\n", found, hit)
+		fmt.Fprintln(out, "end_of_record")
+	}
+	return out.Flush()
+}
+
+// WriteHTML writes a page with the source of each profile, its lines marked
+// as run, partly run or not run, and the count of each line in its margin.
+func WriteHTML(w io.Writer, profiles []*Profile) error {
+	out := bufio.NewWriter(w)
+	out.WriteString(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Monkey coverage</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+pre { font-family: monospace; line-height: 1.3; }
+.line { display: block; }
+.number, .count { display: inline-block; width: 4em; padding-right: 1em; text-align: right; color: #888; user-select: none; }
+.run { background: #dfd; }
+.partial { background: #ffd; }
+.unrun { background: #fdd; }
+</style>
+</head>
+<body>
+`)
+
+	for _, p := range profiles {
+		c, s := p.StatementsCovered()
+		t, b := p.BranchesCovered()
+		fmt.Fprintf(out, "<h2>%s</h2>\n", html.EscapeString(p.Filename))
+		fmt.Fprintf(out, "<p>statements %s, branches %s</p>\n", percent(c, s), percent(t, b))
+
+		lines := map[int]Line{}
+		for _, l := range p.Lines() {
+			lines[l.Number] = l
+		}
+
+		out.WriteString("<pre>")
+		for i, text := range strings.Split(p.Source, "\n") {
+			number := i + 1
+			class, count := "", ""
+			if l, ok := lines[number]; ok {
+				class, count = mark(l)
+			}
+			fmt.Fprintf(out, `<span class="line %s"><span class="number">%d</span><span class="count">%s</span>%s</span>`,
+				class, number, count, html.EscapeString(strings.TrimRight(text, "\r")))
+		}
+		out.WriteString("</pre>\n")
+	}
+
+	out.WriteString("</body>\n</html>\n")
+	return out.Flush()
+}
+
+func mark(l Line) (string, string) {
+	count := ""
+	if l.Statements != 0 {
+		count = strconv.FormatInt(l.Count, 10) + "x"
+	}
+	switch {
+	case l.Statements == 0 && l.Taken == 0, l.Statements != 0 && l.Covered == 0:
+		return "unrun", count
+	case l.Partial(), l.Taken != l.Branches:
+		return "partial", count
+	default:
+		return "run", count
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/dap"
+)
+
+func debugAdapter(args []string) int {
+	flags := flag.NewFlagSet("dap", flag.ContinueOnError)
+	listen := flags.String("listen", "", "serve one client on this address, e.g. 127.0.0.1:4711, instead of stdio")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: monkey dap [-listen=host:port]")
+		return 2
+	}
+
+	var in io.Reader = os.Stdin
+	var out io.Writer = os.Stdout
+	if *listen != "" {
+		listener, err := net.Listen("tcp", *listen)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "listening on %s\n", listener.Addr())
+		conn, err := listener.Accept()
+		listener.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer conn.Close()
+		in, out = conn, conn
+	}
+
+	// The program prints to os.Stdout, which carries the protocol when
+	// serving stdio, so its output is sent to the client instead.
+	r, w, err := os.Pipe()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	os.Stdout = w
+
+	server := dap.NewServer(in, out)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		buffer := make([]byte, 4096)
+		for {
+			n, err := r.Read(buffer)
+			if n > 0 {
+				server.Output("stdout", string(buffer[:n]))
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	err = server.Serve()
+	w.Close()
+	<-forwarded
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
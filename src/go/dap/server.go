@@ -0,0 +1,500 @@
+package dap
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/debugger"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+// THREAD is the id of the only thread a program runs on.
+const THREAD = 1
+
+var reasons = map[debugger.Reason]string{
+	debugger.ENTRY:               "entry",
+	debugger.STEP:                "step",
+	debugger.BREAKPOINT:          "breakpoint",
+	debugger.FUNCTION_BREAKPOINT: "function breakpoint",
+	debugger.ERROR:               "exception",
+	debugger.PAUSE:               "pause",
+}
+
+// Server is a debug adapter for one debugging session. Requests are served
+// on the goroutine calling Serve while the program runs on another, which
+// hands the session over each time it stops.
+type Server struct {
+	reader *bufio.Reader
+
+	wmu sync.Mutex
+	out io.Writer
+	seq int
+
+	debugger *debugger.Debugger
+	path     string
+	source   string
+	program  *ast.Program
+
+	launched   bool
+	configured bool
+	started    bool
+	done       chan struct{}
+
+	resume chan debugger.Action
+	action debugger.Action
+
+	// guarded by smu; frames and handles are only meaningful while stopped
+	smu         sync.Mutex
+	stopped     bool
+	terminating bool
+	frames      []*debugger.Frame
+	handles     []any
+
+	lines     []int
+	functions []int
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	s := &Server{
+		reader: bufio.NewReader(in),
+		out:    out,
+		done:   make(chan struct{}),
+		resume: make(chan debugger.Action),
+	}
+	s.debugger = debugger.New(s)
+	return s
+}
+
+var handlers = map[string]func(s *Server, request *Request) (any, error){
+	"initialize":             (*Server).initialize,
+	"launch":                 (*Server).launch,
+	"setBreakpoints":         (*Server).setBreakpoints,
+	"setFunctionBreakpoints": (*Server).setFunctionBreakpoints,
+	"configurationDone":      (*Server).configurationDone,
+	"threads":                (*Server).threads,
+	"stackTrace":             (*Server).stackTrace,
+	"scopes":                 (*Server).scopes,
+	"variables":              (*Server).variables,
+	"evaluate":               (*Server).evaluate,
+	"continue":               resuming(debugger.CONTINUE),
+	"next":                   resuming(debugger.STEP_OVER),
+	"stepIn":                 resuming(debugger.STEP_IN),
+	"stepOut":                resuming(debugger.STEP_OUT),
+	"pause":                  (*Server).pause,
+}
+
+// Serve answers requests until the client disconnects or the input ends.
+func (s *Server) Serve() error {
+	for {
+		content, err := ReadMessage(s.reader)
+		if err != nil {
+			s.terminate()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		request := &Request{}
+		if err := json.Unmarshal(content, request); err != nil {
+			return fmt.Errorf("invalid message: %w", err)
+		}
+		if request.Type != "request" {
+			continue
+		}
+
+		if request.Command == "disconnect" || request.Command == "terminate" {
+			s.terminate()
+			s.respond(request, nil, nil)
+			return nil
+		}
+
+		handler, ok := handlers[request.Command]
+		if !ok {
+			s.respond(request, nil, fmt.Errorf("unsupported request: %s", request.Command))
+			continue
+		}
+		body, err := handler(s, request)
+		s.respond(request, body, err)
+		if err == nil {
+			s.after(request.Command)
+		}
+	}
+}
+
+// Output sends text to the client as output of the program.
+func (s *Server) Output(category, text string) {
+	s.event("output", OutputEvent{Category: category, Output: text})
+}
+
+func (s *Server) send(message any) {
+	s.wmu.Lock()
+	defer s.wmu.Unlock()
+	s.seq += 1
+	switch message := message.(type) {
+	case *Response:
+		message.Seq = s.seq
+	case *Event:
+		message.Seq = s.seq
+	}
+	WriteMessage(s.out, message)
+}
+
+func (s *Server) respond(request *Request, body any, err error) {
+	response := &Response{Type: "response", RequestSeq: request.Seq, Success: err == nil, Command: request.Command, Body: body}
+	if err != nil {
+		response.Message = err.Error()
+	}
+	s.send(response)
+}
+
+func (s *Server) event(name string, body any) {
+	s.send(&Event{Type: "event", Event: name, Body: body})
+}
+
+// after sends what must follow a response, and starts the program once it
+// is both launched and configured.
+func (s *Server) after(command string) {
+	switch command {
+	case "initialize":
+		s.event("initialized", nil)
+	case "launch", "configurationDone":
+		if s.launched && s.configured && !s.started {
+			s.started = true
+			go s.run()
+		}
+	case "continue", "next", "stepIn", "stepOut":
+		s.resume <- s.action
+	}
+}
+
+func (s *Server) run() {
+	defer close(s.done)
+
+	_, interrupt := s.debugger.Run(context.Background(), engine.NewEvaluator(), s.program)
+	code := 0
+	if interrupt != nil {
+		code = 1
+		switch err := interrupt.(type) {
+		case *object.Error:
+			s.Output("stderr", err.Traceback(s.source))
+		case *object.LimitError:
+			if !errors.Is(err, debugger.ErrQuit) {
+				s.Output("stderr", err.Inspect()+"\n")
+			}
+		default:
+			s.Output("stderr", interrupt.Inspect()+"\n")
+		}
+	}
+	s.event("exited", ExitedEvent{ExitCode: code})
+	s.event("terminated", nil)
+}
+
+// terminate stops the program, if it runs, and waits for it to end.
+func (s *Server) terminate() {
+	if !s.started {
+		return
+	}
+	s.smu.Lock()
+	s.terminating = true
+	stopped := s.stopped
+	s.stopped = false
+	s.smu.Unlock()
+
+	s.debugger.Pause()
+	if stopped {
+		s.resume <- debugger.QUIT
+	}
+	<-s.done
+}
+
+func (s *Server) Stopped(d *debugger.Debugger, stop *debugger.Stop) debugger.Action {
+	s.smu.Lock()
+	if s.terminating {
+		s.smu.Unlock()
+		return debugger.QUIT
+	}
+	s.stopped = true
+	s.frames = d.Stack()
+	s.handles = nil
+	s.smu.Unlock()
+
+	body := StoppedEvent{Reason: reasons[stop.Reason], ThreadID: THREAD, AllThreadsStopped: true}
+	if stop.Breakpoint != nil {
+		body.HitBreakpointIDs = []int{stop.Breakpoint.ID}
+	}
+	if stop.Error != nil {
+		body.Text = stop.Error.Message
+	}
+	s.event("stopped", body)
+	return <-s.resume
+}
+
+func (s *Server) initialize(request *Request) (any, error) {
+	return Capabilities{
+		SupportsConfigurationDoneRequest: true,
+		SupportsFunctionBreakpoints:      true,
+		SupportsEvaluateForHovers:        true,
+	}, nil
+}
+
+func (s *Server) launch(request *Request) (any, error) {
+	if s.launched {
+		return nil, errors.New("already launched")
+	}
+	args := LaunchArguments{}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	src, err := os.ReadFile(args.Program)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New("parser errors: " + strings.Join(p.Errors(), "; "))
+	}
+	if errs := evaluator.Check(program); len(errs) != 0 {
+		messages := []string{}
+		for _, err := range errs {
+			messages = append(messages, fmt.Sprintf("%s: %s", err.Position, err.Message))
+		}
+		return nil, errors.New(strings.Join(messages, "; "))
+	}
+
+	s.path, s.source, s.program = args.Program, string(src), program
+	s.debugger.StopOnEntry = args.StopOnEntry
+	s.launched = true
+	return nil, nil
+}
+
+func (s *Server) setBreakpoints(request *Request) (any, error) {
+	args := SetBreakpointsArguments{}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	for _, id := range s.lines {
+		s.debugger.Clear(id)
+	}
+	s.lines = nil
+
+	// a program is a single file, so breakpoints in other sources never hit
+	verified := s.path == "" || args.Source.Path == "" || sameFile(args.Source.Path, s.path)
+	breakpoints := []Breakpoint{}
+	for _, requested := range args.Breakpoints {
+		if !verified {
+			breakpoints = append(breakpoints, Breakpoint{Line: requested.Line, Message: "not part of the program"})
+			continue
+		}
+		breakpoint := s.debugger.Break(requested.Line)
+		s.lines = append(s.lines, breakpoint.ID)
+		breakpoints = append(breakpoints, Breakpoint{ID: breakpoint.ID, Verified: true, Line: breakpoint.Line})
+	}
+	return map[string]any{"breakpoints": breakpoints}, nil
+}
+
+func sameFile(a, b string) bool {
+	a, errA := filepath.Abs(a)
+	b, errB := filepath.Abs(b)
+	return errA == nil && errB == nil && filepath.Clean(a) == filepath.Clean(b)
+}
+
+func (s *Server) setFunctionBreakpoints(request *Request) (any, error) {
+	args := SetFunctionBreakpointsArguments{}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	for _, id := range s.functions {
+		s.debugger.Clear(id)
+	}
+	s.functions = nil
+
+	breakpoints := []Breakpoint{}
+	for _, requested := range args.Breakpoints {
+		breakpoint := s.debugger.BreakFunction(requested.Name)
+		s.functions = append(s.functions, breakpoint.ID)
+		breakpoints = append(breakpoints, Breakpoint{ID: breakpoint.ID, Verified: true})
+	}
+	return map[string]any{"breakpoints": breakpoints}, nil
+}
+
+func (s *Server) configurationDone(request *Request) (any, error) {
+	s.configured = true
+	return nil, nil
+}
+
+func (s *Server) threads(request *Request) (any, error) {
+	return map[string]any{"threads": []Thread{{ID: THREAD, Name: "main"}}}, nil
+}
+
+// paused runs f with the state of the stopped program, or fails if it is
+// running.
+func (s *Server) paused(f func() (any, error)) (any, error) {
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	if !s.stopped {
+		return nil, errors.New("the program is not stopped")
+	}
+	return f()
+}
+
+func (s *Server) stackTrace(request *Request) (any, error) {
+	return s.paused(func() (any, error) {
+		frames := []StackFrame{}
+		for i, frame := range s.frames {
+			frames = append(frames, StackFrame{
+				ID:     i + 1,
+				Name:   frame.Name(),
+				Source: &Source{Name: filepath.Base(s.path), Path: s.path},
+				Line:   frame.Position.Line,
+				Column: frame.Position.Column,
+			})
+		}
+		return map[string]any{"stackFrames": frames, "totalFrames": len(frames)}, nil
+	})
+}
+
+func (s *Server) frame(id int) (*debugger.Frame, error) {
+	if id < 1 || id > len(s.frames) {
+		return nil, fmt.Errorf("unknown frame: %d", id)
+	}
+	return s.frames[id-1], nil
+}
+
+// handle returns the reference the client uses to expand value.
+func (s *Server) handle(value any) int {
+	s.handles = append(s.handles, value)
+	return len(s.handles)
+}
+
+func (s *Server) scopes(request *Request) (any, error) {
+	args := ScopesArguments{}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	return s.paused(func() (any, error) {
+		frame, err := s.frame(args.FrameID)
+		if err != nil {
+			return nil, err
+		}
+		scopes := []Scope{}
+		for _, scope := range s.debugger.Scopes(frame) {
+			scopes = append(scopes, Scope{Name: scope.Name, VariablesReference: s.handle(scope.Env)})
+		}
+		return map[string]any{"scopes": scopes}, nil
+	})
+}
+
+func (s *Server) variables(request *Request) (any, error) {
+	args := VariablesArguments{}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	return s.paused(func() (any, error) {
+		if args.VariablesReference < 1 || args.VariablesReference > len(s.handles) {
+			return nil, fmt.Errorf("unknown variables reference: %d", args.VariablesReference)
+		}
+
+		variables := []Variable{}
+		switch value := s.handles[args.VariablesReference-1].(type) {
+		case *object.Environment:
+			for _, variable := range debugger.Variables(value) {
+				variables = append(variables, s.variable(variable.Name, variable.Value))
+			}
+		case *object.Array:
+			for i, element := range value.Elements {
+				variables = append(variables, s.variable(strconv.Itoa(i), element))
+			}
+		case *object.Hash:
+			for _, pair := range value.Pairs {
+				variables = append(variables, s.variable(pair.Key.Inspect(), pair.Value))
+			}
+		}
+		return map[string]any{"variables": variables}, nil
+	})
+}
+
+func (s *Server) variable(name string, value object.Object) Variable {
+	variable := Variable{Name: name, Value: value.Inspect(), Type: value.Type().String()}
+	switch value := value.(type) {
+	case *object.Array:
+		if len(value.Elements) != 0 {
+			variable.VariablesReference = s.handle(value)
+		}
+	case *object.Hash:
+		if len(value.Pairs) != 0 {
+			variable.VariablesReference = s.handle(value)
+		}
+	}
+	return variable
+}
+
+func (s *Server) evaluate(request *Request) (any, error) {
+	args := EvaluateArguments{}
+	if err := json.Unmarshal(request.Arguments, &args); err != nil {
+		return nil, err
+	}
+
+	return s.paused(func() (any, error) {
+		if args.FrameID == 0 {
+			args.FrameID = 1
+		}
+		frame, err := s.frame(args.FrameID)
+		if err != nil {
+			return nil, err
+		}
+		value, err := s.debugger.Evaluate(frame, args.Expression)
+		if err != nil {
+			return nil, err
+		}
+		result := s.variable("", value)
+		return map[string]any{"result": result.Value, "type": result.Type, "variablesReference": result.VariablesReference}, nil
+	})
+}
+
+// resuming answers a request that resumes the stopped program with
+// action, which the program receives after the response is sent.
+func resuming(action debugger.Action) func(s *Server, request *Request) (any, error) {
+	return func(s *Server, request *Request) (any, error) {
+		s.smu.Lock()
+		stopped := s.stopped
+		s.stopped = false
+		s.smu.Unlock()
+		if !stopped {
+			return nil, errors.New("the program is not stopped")
+		}
+
+		s.action = action
+		if action == debugger.CONTINUE {
+			return map[string]any{"allThreadsContinued": true}, nil
+		}
+		return nil, nil
+	}
+}
+
+func (s *Server) pause(request *Request) (any, error) {
+	if !s.started {
+		return nil, errors.New("the program is not running")
+	}
+	s.debugger.Pause()
+	return nil, nil
+}
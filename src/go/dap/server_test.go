@@ -0,0 +1,304 @@
+package dap
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const program = `let double = fn(n) {
+  let m = n * 2;
+  m
+};
+let xs = [double(1), 2];
+let y = double(xs[0]);
+y;`
+
+// client is a scripted debugger frontend talking to a Server.
+type client struct {
+	t      *testing.T
+	seq    int
+	in     *io.PipeWriter
+	out    *bufio.Reader
+	served chan error
+}
+
+func newClient(t *testing.T) *client {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	c := &client{t: t, in: inWriter, out: bufio.NewReader(outReader), served: make(chan error, 1)}
+	go func() {
+		c.served <- NewServer(inReader, outWriter).Serve()
+		outWriter.Close()
+	}()
+	return c
+}
+
+func (c *client) send(command string, arguments any) int {
+	c.seq += 1
+	request := map[string]any{"seq": c.seq, "type": "request", "command": command}
+	if arguments != nil {
+		request["arguments"] = arguments
+	}
+	if err := WriteMessage(c.in, request); err != nil {
+		c.t.Fatalf("WriteMessage(%s) ==> %v", command, err)
+	}
+	return c.seq
+}
+
+type message struct {
+	Type       string          `json:"type"`
+	Event      string          `json:"event"`
+	Command    string          `json:"command"`
+	RequestSeq int             `json:"request_seq"`
+	Success    bool            `json:"success"`
+	Message    string          `json:"message"`
+	Body       json.RawMessage `json:"body"`
+}
+
+// expect reads messages until the response to seq, or the event named
+// event when seq is 0, skipping output events, and decodes its body.
+func (c *client) expect(seq int, event string, body any) *message {
+	c.t.Helper()
+	deadline := time.AfterFunc(5*time.Second, func() { c.in.CloseWithError(io.ErrClosedPipe) })
+	defer deadline.Stop()
+
+	for {
+		content, err := ReadMessage(c.out)
+		if err != nil {
+			c.t.Fatalf("expected: <%d %s> but was: <%v>", seq, event, err)
+		}
+		m := &message{}
+		if err := json.Unmarshal(content, m); err != nil {
+			c.t.Fatalf("json.Unmarshal(%s) ==> %v", content, err)
+		}
+		if m.Type == "event" && m.Event == "output" {
+			continue
+		}
+
+		matched := (seq != 0 && m.Type == "response" && m.RequestSeq == seq) || (seq == 0 && m.Type == "event" && m.Event == event)
+		if !matched {
+			c.t.Fatalf("expected: <%d %s> but was: <%s>", seq, event, content)
+		}
+		if body != nil && len(m.Body) != 0 {
+			if err := json.Unmarshal(m.Body, body); err != nil {
+				c.t.Fatalf("json.Unmarshal(%s) ==> %v", m.Body, err)
+			}
+		}
+		return m
+	}
+}
+
+func (c *client) request(command string, arguments any, body any) *message {
+	c.t.Helper()
+	m := c.expect(c.send(command, arguments), "", body)
+	if !m.Success {
+		c.t.Fatalf("%s ==> unexpected failure: %s", command, m.Message)
+	}
+	return m
+}
+
+func (c *client) stopped(reason string) StoppedEvent {
+	c.t.Helper()
+	event := StoppedEvent{}
+	c.expect(0, "stopped", &event)
+	if reason != event.Reason {
+		c.t.Fatalf("stopped.reason ==> expected: <%s> but was: <%s>", reason, event.Reason)
+	}
+	return event
+}
+
+func (c *client) stack() []StackFrame {
+	c.t.Helper()
+	body := struct{ StackFrames []StackFrame }{}
+	c.request("stackTrace", map[string]any{"threadId": THREAD}, &body)
+	return body.StackFrames
+}
+
+func (c *client) variables(reference int) map[string]Variable {
+	c.t.Helper()
+	body := struct{ Variables []Variable }{}
+	c.request("variables", map[string]any{"variablesReference": reference}, &body)
+	variables := map[string]Variable{}
+	for _, variable := range body.Variables {
+		variables[variable.Name] = variable
+	}
+	return variables
+}
+
+// close disconnects, skipping the events of a program ended early.
+func (c *client) close() {
+	c.t.Helper()
+	seq := c.send("disconnect", nil)
+	for {
+		content, err := ReadMessage(c.out)
+		if err != nil {
+			c.t.Fatalf("expected: <%d> but was: <%v>", seq, err)
+		}
+		m := &message{}
+		if err := json.Unmarshal(content, m); err != nil {
+			c.t.Fatalf("json.Unmarshal(%s) ==> %v", content, err)
+		}
+		if m.Type == "response" && m.RequestSeq == seq {
+			break
+		}
+	}
+	c.in.Close()
+	if err := <-c.served; err != nil {
+		c.t.Fatalf("Serve() ==> %v", err)
+	}
+}
+
+func launch(t *testing.T, c *client, stopOnEntry bool, configure func()) {
+	path := filepath.Join(t.TempDir(), "program.mk")
+	if err := os.WriteFile(path, []byte(program), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	capabilities := Capabilities{}
+	c.request("initialize", map[string]any{"adapterID": "monkey"}, &capabilities)
+	if !capabilities.SupportsConfigurationDoneRequest || !capabilities.SupportsFunctionBreakpoints {
+		t.Errorf("initialize ==> unexpected capabilities: %+v", capabilities)
+	}
+	c.expect(0, "initialized", nil)
+	c.request("launch", map[string]any{"program": path, "stopOnEntry": stopOnEntry}, nil)
+	if configure != nil {
+		configure()
+	}
+	c.request("configurationDone", nil, nil)
+}
+
+func TestServerBreakpoints(t *testing.T) {
+	c := newClient(t)
+	launch(t, c, false, func() {
+		body := struct{ Breakpoints []Breakpoint }{}
+		c.request("setBreakpoints", map[string]any{
+			"source":      map[string]any{"path": "elsewhere.mk"},
+			"breakpoints": []map[string]any{{"line": 3}},
+		}, &body)
+		if len(body.Breakpoints) != 1 || body.Breakpoints[0].Verified {
+			t.Errorf("setBreakpoints(elsewhere.mk) ==> expected an unverified breakpoint but was: <%+v>", body.Breakpoints)
+		}
+
+		c.request("setBreakpoints", map[string]any{
+			"source":      map[string]any{},
+			"breakpoints": []map[string]any{{"line": 3}, {"line": 7}},
+		}, &body)
+		if len(body.Breakpoints) != 2 || !body.Breakpoints[0].Verified || body.Breakpoints[1].Line != 7 {
+			t.Errorf("setBreakpoints ==> unexpected breakpoints: <%+v>", body.Breakpoints)
+		}
+	})
+
+	event := c.stopped("breakpoint")
+	if len(event.HitBreakpointIDs) != 1 {
+		t.Errorf("stopped.hitBreakpointIds ==> expected one id but was: <%v>", event.HitBreakpointIDs)
+	}
+	threads := struct{ Threads []Thread }{}
+	c.request("threads", nil, &threads)
+	if len(threads.Threads) != 1 || threads.Threads[0].ID != THREAD {
+		t.Errorf("threads ==> unexpected threads: <%+v>", threads.Threads)
+	}
+
+	frames := c.stack()
+	if len(frames) != 2 || frames[0].Name != "double" || frames[0].Line != 3 || frames[1].Name != "<main>" || frames[1].Line != 5 {
+		t.Fatalf("stackTrace ==> unexpected frames: <%+v>", frames)
+	}
+	if frames[0].Source == nil || frames[0].Source.Name != "program.mk" {
+		t.Errorf("stackTrace ==> unexpected source: <%+v>", frames[0].Source)
+	}
+
+	scopes := struct{ Scopes []Scope }{}
+	c.request("scopes", map[string]any{"frameId": frames[0].ID}, &scopes)
+	if len(scopes.Scopes) != 2 || scopes.Scopes[0].Name != "local" || scopes.Scopes[1].Name != "global" {
+		t.Fatalf("scopes ==> unexpected scopes: <%+v>", scopes.Scopes)
+	}
+	locals := c.variables(scopes.Scopes[0].VariablesReference)
+	if locals["n"].Value != "1" || locals["m"].Value != "2" || locals["m"].Type != "INTEGER" {
+		t.Errorf("variables ==> unexpected locals: <%+v>", locals)
+	}
+
+	c.request("continue", map[string]any{"threadId": THREAD}, nil)
+	c.stopped("breakpoint")
+	c.request("continue", map[string]any{"threadId": THREAD}, nil)
+	c.stopped("breakpoint")
+
+	frames = c.stack()
+	if len(frames) != 1 || frames[0].Line != 7 {
+		t.Fatalf("stackTrace ==> unexpected frames: <%+v>", frames)
+	}
+	c.request("scopes", map[string]any{"frameId": frames[0].ID}, &scopes)
+	globals := c.variables(scopes.Scopes[0].VariablesReference)
+	if globals["y"].Value != "4" || globals["xs"].Value != "[2, 2]" || globals["xs"].VariablesReference == 0 {
+		t.Fatalf("variables ==> unexpected globals: <%+v>", globals)
+	}
+	elements := c.variables(globals["xs"].VariablesReference)
+	if elements["0"].Value != "2" || elements["1"].Value != "2" {
+		t.Errorf("variables ==> unexpected elements: <%+v>", elements)
+	}
+
+	c.request("continue", map[string]any{"threadId": THREAD}, nil)
+	exited := ExitedEvent{}
+	c.expect(0, "exited", &exited)
+	if exited.ExitCode != 0 {
+		t.Errorf("exited.exitCode ==> expected: <0> but was: <%d>", exited.ExitCode)
+	}
+	c.expect(0, "terminated", nil)
+	c.close()
+}
+
+func TestServerStepping(t *testing.T) {
+	c := newClient(t)
+	launch(t, c, true, nil)
+
+	lines := []int{}
+	c.stopped("entry")
+	for _, command := range []string{"next", "stepIn", "next", "stepOut"} {
+		lines = append(lines, c.stack()[0].Line)
+		c.request(command, map[string]any{"threadId": THREAD}, nil)
+		c.stopped("step")
+	}
+	lines = append(lines, c.stack()[0].Line)
+
+	if "[1 5 2 3 6]" != fmt.Sprint(lines) {
+		t.Errorf("lines ==> expected: <[1 5 2 3 6]> but was: <%v>", lines)
+	}
+
+	c.close()
+}
+
+func TestServerEvaluate(t *testing.T) {
+	c := newClient(t)
+	launch(t, c, false, func() {
+		body := struct{ Breakpoints []Breakpoint }{}
+		c.request("setFunctionBreakpoints", map[string]any{"breakpoints": []map[string]any{{"name": "double"}}}, &body)
+		if len(body.Breakpoints) != 1 || !body.Breakpoints[0].Verified {
+			t.Errorf("setFunctionBreakpoints ==> unexpected breakpoints: <%+v>", body.Breakpoints)
+		}
+	})
+
+	c.stopped("function breakpoint")
+	frames := c.stack()
+
+	result := struct {
+		Result             string
+		VariablesReference int
+	}{}
+	c.request("evaluate", map[string]any{"expression": "[n, n * 10]", "frameId": frames[0].ID}, &result)
+	if result.Result != "[1, 10]" || result.VariablesReference == 0 {
+		t.Errorf("evaluate ==> unexpected result: <%+v>", result)
+	}
+
+	m := c.expect(c.send("evaluate", map[string]any{"expression": "m + 1", "frameId": frames[0].ID}), "", nil)
+	if m.Success || m.Message != "ERROR: unknown identifier: m" {
+		t.Errorf("evaluate ==> expected: <ERROR: unknown identifier: m> but was: <%v %s>", m.Success, m.Message)
+	}
+
+	c.request("continue", map[string]any{"threadId": THREAD}, nil)
+	c.stopped("function breakpoint")
+	c.close()
+}
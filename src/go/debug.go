@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/debugger"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func debug(args []string) int {
+	flags := flag.NewFlagSet("debug", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: monkey debug file.mk")
+		return 2
+	}
+
+	src, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprintln(os.Stderr, "parser errors:")
+		for _, msg := range p.Errors() {
+			fmt.Fprintln(os.Stderr, "\t"+msg)
+		}
+		return 1
+	}
+
+	if errs := evaluator.Check(program); len(errs) != 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s:%s: %s\n", flags.Arg(0), err.Position, err.Message)
+		}
+		return 1
+	}
+
+	console := debugger.NewConsole(bufio.NewScanner(os.Stdin), os.Stdout)
+	console.Source = string(src)
+	d := debugger.New(console)
+	d.StopOnEntry = true
+
+	_, interrupt := d.Run(context.Background(), engine.NewEvaluator(), program)
+	if interrupt != nil {
+		if err, ok := interrupt.(*object.LimitError); ok && errors.Is(err, debugger.ErrQuit) {
+			return 1
+		}
+		if err, ok := interrupt.(*object.Error); ok {
+			fmt.Fprint(os.Stderr, err.Traceback(string(src)))
+		} else {
+			fmt.Fprintln(os.Stderr, interrupt.Inspect())
+		}
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,212 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const PROMPT = "(debug) "
+
+// Console is a Handler driven by commands read line by line, as typed in a
+// terminal.
+type Console struct {
+	Source string
+
+	scanner  *bufio.Scanner
+	out      io.Writer
+	selected int
+	last     string
+}
+
+func NewConsole(scanner *bufio.Scanner, out io.Writer) *Console {
+	return &Console{scanner: scanner, out: out}
+}
+
+var actions = map[string]Action{
+	"c":        CONTINUE,
+	"continue": CONTINUE,
+	"s":        STEP_IN,
+	"step":     STEP_IN,
+	"n":        STEP_OVER,
+	"next":     STEP_OVER,
+	"o":        STEP_OUT,
+	"out":      STEP_OUT,
+	"q":        QUIT,
+	"quit":     QUIT,
+}
+
+var commands = map[string]func(c *Console, d *Debugger, args string){
+	"b":      (*Console).breakpoint,
+	"break":  (*Console).breakpoint,
+	"clear":  (*Console).clear,
+	"bt":     (*Console).backtrace,
+	"where":  (*Console).backtrace,
+	"frame":  (*Console).frame,
+	"env":    (*Console).env,
+	"p":      (*Console).print,
+	"print":  (*Console).print,
+	"l":      (*Console).list,
+	"list":   (*Console).list,
+	"help":   (*Console).help,
+	"breaks": (*Console).breakpoints,
+}
+
+func (c *Console) Stopped(d *Debugger, stop *Stop) Action {
+	c.selected = 0
+	c.report(d, stop)
+
+	for {
+		fmt.Fprint(c.out, PROMPT)
+		if !c.scanner.Scan() {
+			fmt.Fprintln(c.out)
+			return QUIT
+		}
+
+		line := strings.TrimSpace(c.scanner.Text())
+		if line == "" {
+			line = c.last
+		}
+		c.last = line
+
+		name, args, _ := strings.Cut(line, " ")
+		if action, ok := actions[name]; ok {
+			return action
+		}
+		if command, ok := commands[name]; ok {
+			command(c, d, strings.TrimSpace(args))
+		} else if name != "" {
+			fmt.Fprintf(c.out, "unknown command: %s (try help)\n", name)
+		}
+	}
+}
+
+func (c *Console) report(d *Debugger, stop *Stop) {
+	frame := d.Stack()[0]
+	reason := stop.Reason.String()
+	if stop.Breakpoint != nil {
+		reason = "breakpoint " + stop.Breakpoint.String()
+	}
+	fmt.Fprintf(c.out, "stopped at %s in %s (%s)\n", stop.Position, frame.Name(), reason)
+	if stop.Error != nil {
+		fmt.Fprintln(c.out, stop.Error.Inspect())
+	}
+	c.printLine(stop.Position.Line, true)
+}
+
+func (c *Console) printLine(line int, current bool) bool {
+	lines := strings.Split(c.Source, "\n")
+	if line < 1 || line > len(lines) {
+		return false
+	}
+	marker := " "
+	if current {
+		marker = ">"
+	}
+	fmt.Fprintf(c.out, "%s %4d | %s\n", marker, line, lines[line-1])
+	return true
+}
+
+func (c *Console) help(d *Debugger, args string) {
+	io.WriteString(c.out, "c, continue\t\trun until the next breakpoint\n")
+	io.WriteString(c.out, "s, step\t\t\tstep into the next statement\n")
+	io.WriteString(c.out, "n, next\t\t\tstep over calls to the next statement\n")
+	io.WriteString(c.out, "o, out\t\t\tstep out of the current function\n")
+	io.WriteString(c.out, "b, break <line|name>\tset a breakpoint on a line or function\n")
+	io.WriteString(c.out, "breaks\t\t\tlist the breakpoints\n")
+	io.WriteString(c.out, "clear [id]\t\tremove a breakpoint, or all of them\n")
+	io.WriteString(c.out, "bt, where\t\tprint the call stack\n")
+	io.WriteString(c.out, "frame <n>\t\tselect a frame of the call stack\n")
+	io.WriteString(c.out, "env\t\t\tprint the environments of the selected frame\n")
+	io.WriteString(c.out, "p, print <expr>\t\tevaluate an expression in the selected frame\n")
+	io.WriteString(c.out, "l, list\t\t\tprint the source around the selected frame\n")
+	io.WriteString(c.out, "q, quit\t\t\tabort the evaluation\n")
+}
+
+func (c *Console) breakpoint(d *Debugger, args string) {
+	if args == "" {
+		io.WriteString(c.out, "usage: break <line|name>\n")
+		return
+	}
+
+	if line, err := strconv.Atoi(args); err == nil {
+		fmt.Fprintf(c.out, "breakpoint %s\n", d.Break(line))
+	} else {
+		fmt.Fprintf(c.out, "breakpoint %s\n", d.BreakFunction(args))
+	}
+}
+
+func (c *Console) breakpoints(d *Debugger, args string) {
+	if len(d.Breakpoints()) == 0 {
+		io.WriteString(c.out, "no breakpoints\n")
+	}
+	for _, breakpoint := range d.Breakpoints() {
+		fmt.Fprintln(c.out, breakpoint)
+	}
+}
+
+func (c *Console) clear(d *Debugger, args string) {
+	if args == "" {
+		d.ClearAll()
+		io.WriteString(c.out, "cleared all breakpoints\n")
+		return
+	}
+
+	id, err := strconv.Atoi(strings.TrimPrefix(args, "#"))
+	if err != nil || !d.Clear(id) {
+		fmt.Fprintf(c.out, "no breakpoint %s\n", args)
+		return
+	}
+	fmt.Fprintf(c.out, "cleared breakpoint #%d\n", id)
+}
+
+func (c *Console) backtrace(d *Debugger, args string) {
+	for i, frame := range d.Stack() {
+		marker := " "
+		if i == c.selected {
+			marker = "*"
+		}
+		fmt.Fprintf(c.out, "%s #%d %s at %s\n", marker, i, frame.Name(), frame.Position)
+	}
+}
+
+func (c *Console) frame(d *Debugger, args string) {
+	n, err := strconv.Atoi(args)
+	if err != nil || n < 0 || n >= len(d.Stack()) {
+		fmt.Fprintf(c.out, "no frame %s\n", args)
+		return
+	}
+	c.selected = n
+	frame := d.Stack()[n]
+	fmt.Fprintf(c.out, "#%d %s at %s\n", n, frame.Name(), frame.Position)
+	c.printLine(frame.Position.Line, true)
+}
+
+func (c *Console) env(d *Debugger, args string) {
+	for _, scope := range d.Scopes(d.Stack()[c.selected]) {
+		fmt.Fprintf(c.out, "%s:\n", scope.Name)
+		for _, variable := range scope.Variables {
+			fmt.Fprintf(c.out, "  %s = %s\n", variable.Name, variable.Value.Inspect())
+		}
+	}
+}
+
+func (c *Console) print(d *Debugger, args string) {
+	value, err := d.Evaluate(d.Stack()[c.selected], args)
+	if err != nil {
+		fmt.Fprintln(c.out, err)
+		return
+	}
+	fmt.Fprintln(c.out, value.Inspect())
+}
+
+func (c *Console) list(d *Debugger, args string) {
+	current := d.Stack()[c.selected].Position.Line
+	for line := max(current-3, 1); line <= current+3; line += 1 {
+		if !c.printLine(line, line == current) {
+			break
+		}
+	}
+}
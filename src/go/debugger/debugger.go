@@ -0,0 +1,394 @@
+package debugger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+// ErrQuit is the cause of the *object.LimitError a debugged evaluation
+// returns when the debugger is told to quit.
+var ErrQuit = errors.New("debugger quit")
+
+type Action int
+
+const (
+	CONTINUE Action = iota
+	STEP_IN
+	STEP_OVER
+	STEP_OUT
+	QUIT
+)
+
+type Reason int
+
+const (
+	ENTRY Reason = iota
+	STEP
+	BREAKPOINT
+	FUNCTION_BREAKPOINT
+	ERROR
+	PAUSE
+)
+
+var reasons = [...]string{
+	ENTRY:               "entry",
+	STEP:                "step",
+	BREAKPOINT:          "breakpoint",
+	FUNCTION_BREAKPOINT: "function breakpoint",
+	ERROR:               "error",
+	PAUSE:               "pause",
+}
+
+func (r Reason) String() string {
+	return reasons[r]
+}
+
+// A Breakpoint stops on entering its line, or on calling the function named
+// Function.
+type Breakpoint struct {
+	ID       int
+	Line     int
+	Function string
+}
+
+func (b *Breakpoint) String() string {
+	if b.Function != "" {
+		return fmt.Sprintf("#%d %s()", b.ID, b.Function)
+	}
+	return fmt.Sprintf("#%d line %d", b.ID, b.Line)
+}
+
+type Stop struct {
+	Reason     Reason
+	Position   token.Position
+	Breakpoint *Breakpoint
+	Error      *object.Error
+}
+
+// A Handler decides how to go on each time the evaluation stops. The
+// debugger can be inspected until it returns.
+type Handler interface {
+	Stopped(d *Debugger, stop *Stop) Action
+}
+
+type Frame struct {
+	Function *object.Function
+	Call     token.Position
+	Position token.Position
+	Env      *object.Environment
+
+	line    int
+	pending *Breakpoint
+}
+
+func (f *Frame) Name() string {
+	if f.Function == nil {
+		return "<main>"
+	}
+	return object.Frame{Function: f.Function}.Name()
+}
+
+type Variable struct {
+	Name  string
+	Value object.Object
+}
+
+type Scope struct {
+	Name      string
+	Env       *object.Environment
+	Variables []Variable
+}
+
+// Debugger observes an evaluation and stops it at breakpoints, after steps
+// and on errors, handing control to its Handler. Breakpoints can be changed
+// and a pause requested from other goroutines while the evaluation runs.
+type Debugger struct {
+	object.BaseObserver
+
+	StopOnEntry bool
+	StopOnError bool
+
+	handler Handler
+	paused  atomic.Bool
+
+	mu          sync.Mutex
+	breakpoints []*Breakpoint
+	nextID      int
+
+	frames     []*Frame
+	action     Action
+	depth      int
+	entry      bool
+	evaluating bool
+}
+
+func New(handler Handler) *Debugger {
+	return &Debugger{handler: handler, StopOnError: true}
+}
+
+type quit struct{}
+
+// Run runs program with e, stopping as told by the handler.
+func (d *Debugger) Run(ctx context.Context, e *engine.Evaluator, program *ast.Program) (value object.Object, interrupt object.Interruption) {
+	d.frames = []*Frame{{}}
+	d.action = CONTINUE
+	if d.StopOnEntry {
+		d.action, d.entry = STEP_IN, true
+	}
+
+	e.SetObserver(d)
+	defer func() {
+		e.SetObserver(nil)
+		d.frames = nil
+		if recovered := recover(); recovered != nil {
+			if _, ok := recovered.(quit); !ok {
+				panic(recovered)
+			}
+			value, interrupt = nil, &object.LimitError{Limit: object.CANCELLED, Err: ErrQuit}
+		}
+	}()
+	return e.RunContext(ctx, program)
+}
+
+func (d *Debugger) Break(line int) *Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID += 1
+	breakpoint := &Breakpoint{ID: d.nextID, Line: line}
+	d.breakpoints = append(d.breakpoints, breakpoint)
+	return breakpoint
+}
+
+func (d *Debugger) BreakFunction(name string) *Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID += 1
+	breakpoint := &Breakpoint{ID: d.nextID, Function: name}
+	d.breakpoints = append(d.breakpoints, breakpoint)
+	return breakpoint
+}
+
+func (d *Debugger) Clear(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, breakpoint := range d.breakpoints {
+		if breakpoint.ID == id {
+			d.breakpoints = append(d.breakpoints[:i], d.breakpoints[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// ClearAll removes every breakpoint.
+func (d *Debugger) ClearAll() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.breakpoints = nil
+}
+
+func (d *Debugger) Breakpoints() []*Breakpoint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.breakpoints)
+}
+
+// Pause stops the evaluation at its next statement or line.
+func (d *Debugger) Pause() {
+	d.paused.Store(true)
+}
+
+// Stack returns the frames of the stopped evaluation, innermost first.
+func (d *Debugger) Stack() []*Frame {
+	stack := make([]*Frame, 0, len(d.frames))
+	for i := len(d.frames) - 1; i >= 0; i -= 1 {
+		stack = append(stack, d.frames[i])
+	}
+	return stack
+}
+
+// Scopes lists the environments visible from frame, innermost first, with
+// the variables defined in each.
+func (d *Debugger) Scopes(frame *Frame) []Scope {
+	scopes := []Scope{}
+	for env := frame.Env; env != nil; env = env.Enclosing {
+		name := "closure"
+		switch {
+		case env.Enclosing == nil:
+			name = "global"
+		case len(scopes) == 0:
+			name = "local"
+		}
+		scopes = append(scopes, Scope{Name: name, Env: env, Variables: Variables(env)})
+	}
+	return scopes
+}
+
+// Variables lists the variables defined directly in env, slots first.
+func Variables(env *object.Environment) []Variable {
+	variables := []Variable{}
+	for i, name := range env.Names {
+		if env.Slots[i] != nil {
+			variables = append(variables, Variable{Name: name, Value: env.Slots[i]})
+		}
+	}
+	names := []string{}
+	for name := range env.Values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		variables = append(variables, Variable{Name: name, Value: env.Values[name]})
+	}
+	return variables
+}
+
+// Evaluate evaluates input in the environment of frame without stopping.
+func (d *Debugger) Evaluate(frame *Frame, input string) (object.Object, error) {
+	if frame.Env == nil {
+		return nil, errors.New("the frame has no environment yet")
+	}
+
+	p := parser.NewParser(input, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, errors.New(strings.Join(p.Errors(), "; "))
+	}
+
+	d.evaluating = true
+	defer func() { d.evaluating = false }()
+	value, interrupt := evaluator.Evaluate(program, frame.Env)
+	if interrupt != nil {
+		return nil, errors.New(interrupt.Inspect())
+	}
+	if value == nil {
+		return evaluator.NULL, nil
+	}
+	return value, nil
+}
+
+func (d *Debugger) Enter(node ast.Node, env *object.Environment) {
+	if d.evaluating || len(d.frames) == 0 {
+		return
+	}
+
+	frame := d.frames[len(d.frames)-1]
+	if frame.Env == nil {
+		frame.Env = env
+	}
+	position := ast.Position(node)
+	if !position.IsValid() {
+		return
+	}
+	switch node.(type) {
+	case *ast.Program, *ast.BlockStatement:
+		return
+	case *ast.LetDeclaration, *ast.ReturnStatement, *ast.ExpressionStatement:
+	default:
+		if position.Line == frame.line {
+			return
+		}
+	}
+
+	entered := position.Line != frame.line
+	frame.line = position.Line
+	frame.Position = position
+	frame.Env = env
+
+	stop := d.check(frame, entered)
+	if stop != nil {
+		stop.Position = position
+		d.stop(stop)
+	}
+}
+
+// check decides whether to stop at a new statement or line of frame.
+func (d *Debugger) check(frame *Frame, entered bool) *Stop {
+	if frame.pending != nil {
+		breakpoint := frame.pending
+		frame.pending = nil
+		return &Stop{Reason: FUNCTION_BREAKPOINT, Breakpoint: breakpoint}
+	}
+
+	depth := len(d.frames)
+	switch {
+	case d.paused.Swap(false):
+		return &Stop{Reason: PAUSE}
+	case d.entry:
+		d.entry = false
+		return &Stop{Reason: ENTRY}
+	case d.action == STEP_IN:
+		return &Stop{Reason: STEP}
+	case d.action == STEP_OVER && depth <= d.depth:
+		return &Stop{Reason: STEP}
+	case d.action == STEP_OUT && depth < d.depth:
+		return &Stop{Reason: STEP}
+	}
+
+	if entered {
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		for _, breakpoint := range d.breakpoints {
+			if breakpoint.Function == "" && breakpoint.Line == frame.line {
+				return &Stop{Reason: BREAKPOINT, Breakpoint: breakpoint}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Debugger) Call(fn *object.Function, args []object.Object, position token.Position, env *object.Environment) {
+	if d.evaluating || len(d.frames) == 0 {
+		return
+	}
+
+	d.frames[len(d.frames)-1].Position = position
+	frame := &Frame{Function: fn, Call: position, Env: env}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, breakpoint := range d.breakpoints {
+		if breakpoint.Function != "" && breakpoint.Function == frame.Name() {
+			frame.pending = breakpoint
+		}
+	}
+	d.frames = append(d.frames, frame)
+}
+
+func (d *Debugger) Return(fn *object.Function, value object.Object, interrupt object.Interruption) {
+	if d.evaluating || len(d.frames) <= 1 {
+		return
+	}
+	d.frames = d.frames[:len(d.frames)-1]
+}
+
+func (d *Debugger) Raise(err *object.Error, env *object.Environment) {
+	if d.evaluating || len(d.frames) == 0 || !d.StopOnError {
+		return
+	}
+
+	frame := d.frames[len(d.frames)-1]
+	frame.Position = err.Position
+	frame.Env = env
+	d.stop(&Stop{Reason: ERROR, Position: err.Position, Error: err})
+}
+
+func (d *Debugger) stop(stop *Stop) {
+	d.action = d.handler.Stopped(d, stop)
+	d.depth = len(d.frames)
+	if d.action == QUIT {
+		panic(quit{})
+	}
+}
@@ -0,0 +1,220 @@
+package debugger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+const source = `let double = fn(n) {
+  let m = n * 2;
+  m
+};
+let x = double(1);
+let y = double(x);
+x + y;`
+
+// script answers each stop with the next action, recording where it stopped
+// and running inspect first.
+type script struct {
+	actions []Action
+	stops   []string
+	inspect func(d *Debugger)
+}
+
+func (s *script) Stopped(d *Debugger, stop *Stop) Action {
+	s.stops = append(s.stops, fmt.Sprintf("%s %d %s", d.Stack()[0].Name(), stop.Position.Line, stop.Reason))
+	if s.inspect != nil {
+		s.inspect(d)
+	}
+	if len(s.actions) == 0 {
+		return CONTINUE
+	}
+	action := s.actions[0]
+	s.actions = s.actions[1:]
+	return action
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.NewParser(input, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("p.Errors() ==> %v", p.Errors())
+	}
+	return program
+}
+
+func TestDebuggerStepping(t *testing.T) {
+	tests := []struct {
+		actions  []Action
+		expected []string
+	}{
+		{
+			[]Action{STEP_IN, STEP_IN, STEP_IN, STEP_IN, STEP_IN, CONTINUE},
+			[]string{"<main> 1 entry", "<main> 5 step", "double 2 step", "double 3 step", "<main> 6 step", "double 2 step"},
+		},
+		{
+			[]Action{STEP_OVER, STEP_OVER, STEP_OVER, STEP_OVER},
+			[]string{"<main> 1 entry", "<main> 5 step", "<main> 6 step", "<main> 7 step"},
+		},
+		{
+			[]Action{STEP_OVER, STEP_IN, STEP_OUT, STEP_OUT},
+			[]string{"<main> 1 entry", "<main> 5 step", "double 2 step", "<main> 6 step"},
+		},
+	}
+
+	for i, test := range tests {
+		s := &script{actions: test.actions}
+		d := New(s)
+		d.StopOnEntry = true
+
+		value, interrupt := d.Run(context.Background(), engine.NewEvaluator(), parse(t, source))
+		if interrupt != nil {
+			t.Fatalf("test[%d] - Run() ==> unexpected interrupt: %s", i, interrupt.Inspect())
+		}
+		if value.Inspect() != "6" {
+			t.Errorf("test[%d] - Run() ==> expected: <6> but was: <%s>", i, value.Inspect())
+		}
+		if strings.Join(test.expected, ", ") != strings.Join(s.stops, ", ") {
+			t.Errorf("test[%d] - stops ==> expected: <%s> but was: <%s>", i, strings.Join(test.expected, ", "), strings.Join(s.stops, ", "))
+		}
+	}
+}
+
+func TestDebuggerBreakpoints(t *testing.T) {
+	s := &script{}
+	d := New(s)
+	d.Break(3)
+	d.BreakFunction("double")
+	d.Break(7)
+
+	if _, interrupt := d.Run(context.Background(), engine.NewEvaluator(), parse(t, source)); interrupt != nil {
+		t.Fatalf("Run() ==> unexpected interrupt: %s", interrupt.Inspect())
+	}
+
+	expected := "double 2 function breakpoint, double 3 breakpoint, double 2 function breakpoint, double 3 breakpoint, <main> 7 breakpoint"
+	if expected != strings.Join(s.stops, ", ") {
+		t.Errorf("stops ==> expected: <%s> but was: <%s>", expected, strings.Join(s.stops, ", "))
+	}
+
+	if !d.Clear(2) || d.Clear(2) || len(d.Breakpoints()) != 2 {
+		t.Errorf("Clear(2) ==> expected to remove breakpoint #2 once")
+	}
+}
+
+func TestDebuggerInspect(t *testing.T) {
+	stacks := []string{}
+	scopes := []string{}
+	values := []string{}
+	s := &script{inspect: func(d *Debugger) {
+		names := []string{}
+		for _, frame := range d.Stack() {
+			names = append(names, fmt.Sprintf("%s@%s", frame.Name(), frame.Position))
+		}
+		stacks = append(stacks, strings.Join(names, " "))
+
+		for _, scope := range d.Scopes(d.Stack()[0]) {
+			variables := []string{}
+			for _, variable := range scope.Variables {
+				variables = append(variables, variable.Name+"="+variable.Value.Inspect())
+			}
+			scopes = append(scopes, scope.Name+"{"+strings.Join(variables, " ")+"}")
+		}
+
+		value, err := d.Evaluate(d.Stack()[0], "n + m")
+		if err != nil {
+			values = append(values, err.Error())
+		} else {
+			values = append(values, value.Inspect())
+		}
+	}}
+	d := New(s)
+	d.Break(3)
+
+	if _, interrupt := d.Run(context.Background(), engine.NewEvaluator(), parse(t, source)); interrupt != nil {
+		t.Fatalf("Run() ==> unexpected interrupt: %s", interrupt.Inspect())
+	}
+
+	expected := "double@3:3 <main>@5:9, double@3:3 <main>@6:9"
+	if expected != strings.Join(stacks, ", ") {
+		t.Errorf("stacks ==> expected: <%s> but was: <%s>", expected, strings.Join(stacks, ", "))
+	}
+	expected = "local{n=1 m=2}, global{double=<fn (n)>}, local{n=2 m=4}, global{double=<fn (n)> x=2}"
+	if expected != strings.Join(scopes, ", ") {
+		t.Errorf("scopes ==> expected: <%s> but was: <%s>", expected, strings.Join(scopes, ", "))
+	}
+	expected = "3, 6"
+	if expected != strings.Join(values, ", ") {
+		t.Errorf("values ==> expected: <%s> but was: <%s>", expected, strings.Join(values, ", "))
+	}
+}
+
+func TestDebuggerErrors(t *testing.T) {
+	s := &script{}
+	d := New(s)
+
+	_, interrupt := d.Run(context.Background(), engine.NewEvaluator(), parse(t, "let f = fn(n) {\n  n + true\n};\nf(1);"))
+	if _, ok := interrupt.(*object.Error); !ok {
+		t.Fatalf("Run() ==> expected: <*object.Error> but was: <%#v>", interrupt)
+	}
+	if "f 2 error" != strings.Join(s.stops, ", ") {
+		t.Errorf("stops ==> expected: <%s> but was: <%s>", "f 2 error", strings.Join(s.stops, ", "))
+	}
+}
+
+func TestDebuggerQuit(t *testing.T) {
+	s := &script{actions: []Action{STEP_IN, QUIT}}
+	d := New(s)
+	d.StopOnEntry = true
+
+	e := engine.NewEvaluator()
+	_, interrupt := d.Run(context.Background(), e, parse(t, source))
+	err, ok := interrupt.(*object.LimitError)
+	if !ok || !errors.Is(err, ErrQuit) {
+		t.Fatalf("Run() ==> expected: <%s> but was: <%#v>", ErrQuit, interrupt)
+	}
+
+	value, interrupt := e.Run(parse(t, "double(5)"))
+	if interrupt != nil || value.Inspect() != "10" {
+		t.Errorf("Run() after quit ==> expected: <10> but was: <%v, %v>", value, interrupt)
+	}
+}
+
+func TestConsole(t *testing.T) {
+	input := "b double\nc\nbt\nenv\np n * 10\nn\np m\nclear\nc\n"
+	out := &bytes.Buffer{}
+	console := NewConsole(bufio.NewScanner(strings.NewReader(input)), out)
+	console.Source = source
+	d := New(console)
+	d.StopOnEntry = true
+
+	if _, interrupt := d.Run(context.Background(), engine.NewEvaluator(), parse(t, source)); interrupt != nil {
+		t.Fatalf("Run() ==> unexpected interrupt: %s", interrupt.Inspect())
+	}
+
+	expected := []string{
+		"stopped at 1:1 in <main> (entry)\n>    1 | let double = fn(n) {\n",
+		"breakpoint #1 double()\n",
+		"stopped at 2:3 in double (breakpoint #1 double())\n>    2 |   let m = n * 2;\n",
+		"* #0 double at 2:3\n  #1 <main> at 5:9\n",
+		"local:\n  n = 1\nglobal:\n  double = <fn (n)>\n",
+		"(debug) 10\n",
+		"stopped at 3:3 in double (step)\n",
+		"(debug) 2\n",
+		"cleared all breakpoints\n",
+	}
+	for i, fragment := range expected {
+		if !strings.Contains(out.String(), fragment) {
+			t.Errorf("test[%d] - output ==> expected to contain: <%s> but was: <%s>", i, fragment, out.String())
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/compiler"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/optimizer"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/vm"
+)
+
+type Engine interface {
+	Name() string
+	Macros() *object.Environment
+	SetLimits(limits object.Limits)
+	SetPasses(passes []optimizer.Pass)
+	Run(program *ast.Program) (object.Object, object.Interruption)
+	RunContext(ctx context.Context, program *ast.Program) (object.Object, object.Interruption)
+}
+
+var engines = map[string]func() Engine{
+	"eval": func() Engine { return NewEvaluator() },
+	"vm":   func() Engine { return NewVirtualMachine() },
+}
+
+const Default = "eval"
+
+func New(name string) (Engine, error) {
+	constructor, ok := engines[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown engine: %q (available: %v)", name, Names())
+	}
+	return constructor(), nil
+}
+
+func Names() []string {
+	names := []string{}
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type Evaluator struct {
+	env    *object.Environment
+	macros *object.Environment
+	passes []optimizer.Pass
+}
+
+func NewEvaluator() *Evaluator {
+	return &Evaluator{
+		env:    object.NewEnvironment(nil),
+		macros: object.NewEnvironment(nil),
+	}
+}
+
+func (e *Evaluator) Name() string {
+	return "eval"
+}
+
+func (e *Evaluator) Macros() *object.Environment {
+	return e.macros
+}
+
+func (e *Evaluator) SetLimits(limits object.Limits) {
+	e.env.Runtime.Limits = limits
+	e.macros.Runtime.Limits = limits
+}
+
+func (e *Evaluator) SetPasses(passes []optimizer.Pass) {
+	e.passes = passes
+}
+
+// SetObserver installs an observer on the evaluations of e, or removes it
+// when observer is nil.
+func (e *Evaluator) SetObserver(observer object.Observer) {
+	e.env.Runtime.Observer = observer
+}
+
+// SetModulePath makes the programs e runs import modules from the
+// directories of path, in order.
+func (e *Evaluator) SetModulePath(path []string) {
+	loader := evaluator.NewLoader(path...)
+	e.env.Runtime.Importer = loader
+	e.macros.Runtime.Importer = loader
+}
+
+func (e *Evaluator) Run(program *ast.Program) (object.Object, object.Interruption) {
+	return e.RunContext(context.Background(), program)
+}
+
+func (e *Evaluator) RunContext(ctx context.Context, program *ast.Program) (object.Object, object.Interruption) {
+	node, interrupt := prepare(ctx, program, e.macros, e.passes)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+	return evaluator.EvaluateContext(ctx, node, e.env)
+}
+
+type VirtualMachine struct {
+	macros    *object.Environment
+	symbols   *compiler.SymbolTable
+	constants []object.Object
+	globals   []object.Object
+	limits    object.Limits
+	passes    []optimizer.Pass
+}
+
+func NewVirtualMachine() *VirtualMachine {
+	return &VirtualMachine{
+		macros:    object.NewEnvironment(nil),
+		symbols:   compiler.NewSymbolTable(),
+		constants: []object.Object{},
+		globals:   make([]object.Object, vm.GlobalsSize),
+		limits:    object.DefaultLimits,
+	}
+}
+
+func (v *VirtualMachine) Name() string {
+	return "vm"
+}
+
+func (v *VirtualMachine) Macros() *object.Environment {
+	return v.macros
+}
+
+func (v *VirtualMachine) SetLimits(limits object.Limits) {
+	v.limits = limits
+	v.macros.Runtime.Limits = limits
+}
+
+func (v *VirtualMachine) SetPasses(passes []optimizer.Pass) {
+	v.passes = passes
+}
+
+func (v *VirtualMachine) Run(program *ast.Program) (object.Object, object.Interruption) {
+	return v.RunContext(context.Background(), program)
+}
+
+func (v *VirtualMachine) RunContext(ctx context.Context, program *ast.Program) (object.Object, object.Interruption) {
+	node, interrupt := prepare(ctx, program, v.macros, v.passes)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+
+	c := compiler.NewWithState(v.symbols, v.constants)
+	if err := c.Compile(node); err != nil {
+		return nil, &object.Error{Message: err.Error()}
+	}
+
+	bytecode := c.Bytecode()
+	v.constants = bytecode.Constants
+	machine := vm.NewWithGlobalsStore(bytecode, v.globals)
+	machine.SetLimits(v.limits)
+	return machine.RunContext(ctx)
+}
+
+// prepare expands the macros of program under ctx and optimizes the result.
+func prepare(ctx context.Context, program *ast.Program, macros *object.Environment, passes []optimizer.Pass) (ast.Node, object.Interruption) {
+	expanded, interrupt := evaluator.ExpandMacrosContext(ctx, evaluator.DefineMacros(program, macros), macros)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+	if program, ok := expanded.(*ast.Program); ok && len(passes) != 0 {
+		return optimizer.Optimize(program, passes...), nil
+	}
+	return expanded, nil
+}
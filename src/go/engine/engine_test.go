@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/optimizer"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestEnginesKeepState(t *testing.T) {
+	lines := []string{
+		`let x = 20;`,
+		`macro twice(e) { quote(unquote(e) * 2) };`,
+		`let f = fn(y) { x + y };`,
+		`twice(f(1))`,
+	}
+
+	for _, name := range Names() {
+		e, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%q) ==> unexpected error: %s", name, err)
+		}
+
+		var result string
+		for _, line := range lines {
+			value, interrupt := e.Run(parser.NewParser(line, false).ParseProgram())
+			if interrupt != nil {
+				t.Fatalf("%s - Run(%q) ==> unexpected interrupt: %s", name, line, interrupt.Inspect())
+			}
+			if value != nil {
+				result = value.Inspect()
+			}
+		}
+
+		if result != "42" {
+			t.Errorf("%s - Run() ==> expected: <42> but was: <%s>", name, result)
+		}
+	}
+}
+
+func TestEnginesOptimize(t *testing.T) {
+	input := `
+		macro twice(e) { quote(unquote(e) * 2) };
+		let add = fn(a, b) { a + b };
+		let debug = false;
+		if (debug) { puts("debugging") };
+		twice(add(20, 1))`
+
+	for _, name := range Names() {
+		e, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%q) ==> unexpected error: %s", name, err)
+		}
+		passes, err := optimizer.Level(optimizer.MaxLevel)
+		if err != nil {
+			t.Fatalf("Level(%d) ==> unexpected error: %s", optimizer.MaxLevel, err)
+		}
+		e.SetPasses(passes)
+
+		value, interrupt := e.Run(parser.NewParser(input, false).ParseProgram())
+		if interrupt != nil {
+			t.Fatalf("%s - Run() ==> unexpected interrupt: %s", name, interrupt.Inspect())
+		}
+		if value.Inspect() != "42" {
+			t.Errorf("%s - Run() ==> expected: <42> but was: <%s>", name, value.Inspect())
+		}
+	}
+}
+
+func TestEnginesMacroErrors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		input    string
+		ctx      context.Context
+		expected string
+	}{
+		{"macro m() { 1 }; m()", context.Background(), "ERROR: unsupported type returned from macro expansion: INTEGER"},
+		{"macro spin() { let f = fn(n) { f(n + 1) }; f(0) }; spin()", cancelled, "ERROR: evaluation cancelled: context canceled"},
+	}
+
+	for _, name := range Names() {
+		e, err := New(name)
+		if err != nil {
+			t.Fatalf("New(%q) ==> unexpected error: %s", name, err)
+		}
+
+		for i, test := range tests {
+			_, interrupt := e.RunContext(test.ctx, parser.NewParser(test.input, false).ParseProgram())
+			if interrupt == nil || test.expected != interrupt.Inspect() {
+				t.Errorf("%s - test[%d] - RunContext() ==> expected: <%s> but was: <%v>", name, i, test.expected, interrupt)
+			}
+		}
+	}
+}
+
+func TestUnknownEngine(t *testing.T) {
+	if _, err := New("jit"); err == nil {
+		t.Fatalf("New(\"jit\") ==> expected an error")
+	}
+}
@@ -21,9 +21,9 @@ func init() {
 
 				switch args[0].Type() {
 				case object.STRING:
-					return object.Number(len(args[0].(object.String))), nil
+					return object.Integer(len(args[0].(object.String))), nil
 				case object.ARRAY:
-					return object.Number(len(args[0].(*object.Array).Elements)), nil
+					return object.Integer(len(args[0].(*object.Array).Elements)), nil
 				default:
 					return nil, toBuiltinError("len", args)
 				}
@@ -88,7 +88,7 @@ func init() {
 					}
 					result := make([]object.Object, length-1)
 					copy(result, elements[1:])
-					return &object.Array{Elements: result}, nil
+					return allocate(&object.Array{Elements: result}, ctx)
 				default:
 					return nil, toBuiltinError("rest", args)
 				}
@@ -107,12 +107,317 @@ func init() {
 					result := make([]object.Object, length+1)
 					copy(result, elements)
 					result[length] = args[1]
-					return &object.Array{Elements: result}, nil
+					return allocate(&object.Array{Elements: result}, ctx)
 				default:
 					return nil, toBuiltinError("push", args)
 				}
 			},
 		},
+		"int": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("int", args)
+				}
+
+				value, interrupt := convertInteger(args[0])
+				if interrupt != nil {
+					return nil, interrupt
+				}
+				return allocate(value, ctx)
+			},
+		},
+		"float": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("float", args)
+				}
+
+				value, interrupt := convertFloat(args[0])
+				if interrupt != nil {
+					return nil, interrupt
+				}
+				return allocate(value, ctx)
+			},
+		},
+		"decimal": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("decimal", args)
+				}
+
+				value, interrupt := convertDecimal(args[0])
+				if interrupt != nil {
+					return nil, interrupt
+				}
+				return allocate(value, ctx)
+			},
+		},
+		"str": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("str", args)
+				}
+
+				switch arg := args[0].(type) {
+				case object.String:
+					return arg, nil
+				case *object.Decimal:
+					return allocate(object.String(arg.String()), ctx)
+				default:
+					return allocate(object.String(arg.Inspect()), ctx)
+				}
+			},
+		},
+		"spawn": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) == 0 {
+					return nil, toBuiltinError("spawn", args)
+				}
+
+				switch fn := args[0].(type) {
+				case *object.Function:
+					if fn.Compiled != nil {
+						return nil, toError("`spawn` needs the eval engine")
+					}
+				case *object.BuiltinFunction:
+				default:
+					return nil, toBuiltinError("spawn", args)
+				}
+
+				copies := object.CopyAll(args...)
+				task := ctx.Runtime.Scheduler().Spawn(ctx.Runtime, func(runtime *object.Runtime) (object.Object, object.Interruption) {
+					return Apply(copies[0], copies[1:], &object.Environment{Runtime: runtime})
+				})
+				return task, nil
+			},
+		},
+		"await": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("await", args)
+				}
+				task, ok := args[0].(*object.Task)
+				if !ok {
+					return nil, toBuiltinError("await", args)
+				}
+
+				value, failure, interrupt := ctx.Runtime.Scheduler().Await(ctx.Runtime, task)
+				if interrupt != nil {
+					return nil, interrupt
+				}
+				if failure != nil {
+					return nil, toError("task %d failed: %s", task.ID, strings.TrimPrefix(failure.Inspect(), "ERROR: "))
+				}
+				return value, nil
+			},
+		},
+		"channel": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				capacity := object.Integer(0)
+				switch len(args) {
+				case 0:
+				case 1:
+					n, ok := args[0].(object.Integer)
+					if !ok || n < 0 {
+						return nil, toBuiltinError("channel", args)
+					}
+					capacity = n
+				default:
+					return nil, toBuiltinError("channel", args)
+				}
+				return ctx.Runtime.Scheduler().NewChannel(int(capacity)), nil
+			},
+		},
+		"send": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 2 {
+					return nil, toBuiltinError("send", args)
+				}
+				channel, ok := args[0].(*object.Channel)
+				if !ok {
+					return nil, toBuiltinError("send", args)
+				}
+
+				cases := []object.Case{{Channel: channel, Send: true, Value: args[1]}}
+				if _, _, _, interrupt := ctx.Runtime.Scheduler().Select(ctx.Runtime, cases, true); interrupt != nil {
+					return nil, interrupt
+				}
+				return NULL, nil
+			},
+		},
+		"recv": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("recv", args)
+				}
+				channel, ok := args[0].(*object.Channel)
+				if !ok {
+					return nil, toBuiltinError("recv", args)
+				}
+
+				cases := []object.Case{{Channel: channel}}
+				_, value, _, interrupt := ctx.Runtime.Scheduler().Select(ctx.Runtime, cases, true)
+				if interrupt != nil {
+					return nil, interrupt
+				}
+				return value, nil
+			},
+		},
+		"close": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("close", args)
+				}
+				channel, ok := args[0].(*object.Channel)
+				if !ok {
+					return nil, toBuiltinError("close", args)
+				}
+
+				if err := ctx.Runtime.Scheduler().Close(channel); err != nil {
+					return nil, err
+				}
+				return NULL, nil
+			},
+		},
+		"select": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 && len(args) != 2 {
+					return nil, toBuiltinError("select", args)
+				}
+				array, ok := args[0].(*object.Array)
+				if !ok {
+					return nil, toBuiltinError("select", args)
+				}
+				block := object.Boolean(true)
+				if len(args) == 2 {
+					if block, ok = args[1].(object.Boolean); !ok {
+						return nil, toBuiltinError("select", args)
+					}
+				}
+
+				cases := make([]object.Case, len(array.Elements))
+				for i, element := range array.Elements {
+					switch element := element.(type) {
+					case *object.Channel:
+						cases[i] = object.Case{Channel: element}
+						continue
+					case *object.Array:
+						if len(element.Elements) == 2 {
+							if channel, ok := element.Elements[0].(*object.Channel); ok {
+								cases[i] = object.Case{Channel: channel, Send: true, Value: element.Elements[1]}
+								continue
+							}
+						}
+					}
+					return nil, toError("`select` case %d is not a channel or [channel, value]", i)
+				}
+
+				index, value, _, interrupt := ctx.Runtime.Scheduler().Select(ctx.Runtime, cases, bool(block))
+				if interrupt != nil {
+					return nil, interrupt
+				}
+				return allocate(&object.Array{Elements: []object.Object{object.Integer(index), value}}, ctx)
+			},
+		},
+		"next": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 1 {
+					return nil, toBuiltinError("next", args)
+				}
+				iterator, ok := args[0].(*object.Iterator)
+				if !ok {
+					return nil, toBuiltinError("next", args)
+				}
+
+				value, _, interrupt := iterator.Next(ctx)
+				return value, interrupt
+			},
+		},
+		"take": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 2 {
+					return nil, toBuiltinError("take", args)
+				}
+				next, ok := iterate(args[0])
+				if !ok {
+					return nil, toBuiltinError("take", args)
+				}
+				n, ok := args[1].(object.Integer)
+				if !ok || n < 0 {
+					return nil, toBuiltinError("take", args)
+				}
+
+				elements := []object.Object{}
+				for len(elements) < int(n) {
+					value, ok, interrupt := next(ctx)
+					if interrupt != nil {
+						return nil, interrupt
+					}
+					if !ok {
+						break
+					}
+					elements = append(elements, value)
+				}
+				return allocate(&object.Array{Elements: elements}, ctx)
+			},
+		},
+		"map": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 2 {
+					return nil, toBuiltinError("map", args)
+				}
+				next, ok := iterate(args[0])
+				if !ok {
+					return nil, toBuiltinError("map", args)
+				}
+
+				fn := args[1]
+				mapped := func(ctx *object.Environment) (object.Object, bool, object.Interruption) {
+					value, ok, interrupt := next(ctx)
+					if !ok || interrupt != nil {
+						return value, ok, interrupt
+					}
+					value, interrupt = Apply(fn, []object.Object{value}, ctx)
+					return value, interrupt == nil, interrupt
+				}
+				if _, ok := args[0].(*object.Array); ok {
+					return collect(mapped, ctx)
+				}
+				return object.NewIterator(ctx.Runtime, mapped), nil
+			},
+		},
+		"filter": &object.BuiltinFunction{
+			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
+				if len(args) != 2 {
+					return nil, toBuiltinError("filter", args)
+				}
+				next, ok := iterate(args[0])
+				if !ok {
+					return nil, toBuiltinError("filter", args)
+				}
+
+				fn := args[1]
+				filtered := func(ctx *object.Environment) (object.Object, bool, object.Interruption) {
+					for {
+						value, ok, interrupt := next(ctx)
+						if !ok || interrupt != nil {
+							return value, ok, interrupt
+						}
+						keep, interrupt := Apply(fn, []object.Object{value}, ctx)
+						if interrupt != nil {
+							return nil, false, interrupt
+						}
+						if IsTruthy(keep) {
+							return value, true, nil
+						}
+					}
+				}
+				if _, ok := args[0].(*object.Array); ok {
+					return collect(filtered, ctx)
+				}
+				return object.NewIterator(ctx.Runtime, filtered), nil
+			},
+		},
 		"quote": &object.BuiltinMacro{
 			Fn: func(ctx *object.Environment, args ...object.Object) (object.Object, object.Interruption) {
 				if len(args) != 1 {
@@ -124,7 +429,7 @@ func init() {
 					return nil, toBuiltinError("quote", args)
 				}
 
-				node := ast.Modify(quote.Node, func(node ast.Node) ast.Node {
+				node := ast.Rewrite(quote.Node, func(node ast.Node) ast.Node {
 					call, ok := node.(*ast.CallExpression)
 					if !ok {
 						return node
@@ -148,7 +453,7 @@ func init() {
 						}
 					}
 
-					return toNode(result)
+					return ToNode(result)
 				})
 
 				return &object.Quote{Node: node.(ast.Expression)}, nil
@@ -175,15 +480,39 @@ func init() {
 	}
 }
 
-func toNode(o object.Object) ast.Expression {
+func ToNode(o object.Object) ast.Expression {
 	switch o := o.(type) {
 	case *object.Function:
-		return o.Literal
-	case object.Number:
-		return &ast.NumberLiteral{
+		return ast.Clone(o.Literal).(ast.Expression)
+	case object.Integer:
+		return &ast.IntegerLiteral{
+			Token: token.Token{
+				Type:    token.INT,
+				Literal: o.Inspect(),
+			},
+			Value: int64(o),
+		}
+	case *object.BigInt:
+		return &ast.IntegerLiteral{
+			Token: token.Token{
+				Type:    token.INT,
+				Literal: o.Inspect(),
+			},
+			Big: o.Value,
+		}
+	case *object.Decimal:
+		return &ast.DecimalLiteral{
 			Token: token.Token{
-				Type:    token.NUMBER,
-				Literal: fmt.Sprintf("%g", o),
+				Type:    token.DECIMAL,
+				Literal: o.Inspect(),
+			},
+			Value: o.Value,
+		}
+	case object.Float:
+		return &ast.FloatLiteral{
+			Token: token.Token{
+				Type:    token.FLOAT,
+				Literal: o.Inspect(),
 			},
 			Value: float64(o),
 		}
@@ -222,23 +551,23 @@ func toNode(o object.Object) ast.Expression {
 			Elements: []ast.Expression{},
 		}
 
-		for i, elem := range o.Elements {
-			node.Elements[i] = toNode(elem)
+		for _, elem := range o.Elements {
+			node.Elements = append(node.Elements, ToNode(elem))
 		}
 		return node
 	case *object.Hash:
 		node := &ast.HashLiteral{
 			Token: token.Token{
-				Type:    token.LBRACK,
-				Literal: "[",
+				Type:    token.LBRACE,
+				Literal: "{",
 			},
 			Keys:  []ast.Expression{},
 			Pairs: map[ast.Expression]ast.Expression{},
 		}
 
 		for _, pair := range o.Pairs {
-			key := toNode(pair.Key)
-			value := toNode(pair.Value)
+			key := ToNode(pair.Key)
+			value := ToNode(pair.Value)
 
 			node.Keys = append(node.Keys, key)
 			node.Pairs[key] = value
@@ -253,12 +582,52 @@ func toNode(o object.Object) ast.Expression {
 			},
 		}
 	case *object.Quote:
-		return o.Node
+		return ast.Clone(o.Node).(ast.Expression)
 	default:
 		panic(fmt.Errorf("unexpected object type: %s", o.Type()))
 	}
 }
 
+// iterate returns the next function of an array, an iterator or a channel,
+// which receives until the channel is closed.
+func iterate(iterable object.Object) (func(ctx *object.Environment) (object.Object, bool, object.Interruption), bool) {
+	switch iterable := iterable.(type) {
+	case *object.Array:
+		i := 0
+		return func(ctx *object.Environment) (object.Object, bool, object.Interruption) {
+			if i >= len(iterable.Elements) {
+				return NULL, false, nil
+			}
+			i += 1
+			return iterable.Elements[i-1], true, nil
+		}, true
+	case *object.Iterator:
+		return iterable.Next, true
+	case *object.Channel:
+		return func(ctx *object.Environment) (object.Object, bool, object.Interruption) {
+			cases := []object.Case{{Channel: iterable}}
+			_, value, ok, interrupt := ctx.Runtime.Scheduler().Select(ctx.Runtime, cases, true)
+			return value, ok, interrupt
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+func collect(next func(ctx *object.Environment) (object.Object, bool, object.Interruption), ctx *object.Environment) (object.Object, object.Interruption) {
+	elements := []object.Object{}
+	for {
+		value, ok, interrupt := next(ctx)
+		if interrupt != nil {
+			return nil, interrupt
+		}
+		if !ok {
+			return allocate(&object.Array{Elements: elements}, ctx)
+		}
+		elements = append(elements, value)
+	}
+}
+
 func toBuiltinError(name string, args []object.Object) *object.Error {
 	types := []string{}
 	for _, arg := range args {
@@ -1,12 +1,14 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"slices"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
 )
 
 const (
@@ -14,9 +16,64 @@ const (
 	FALSE = object.Boolean(false)
 )
 
-var NULL = &object.Null{}
+var NULL = object.Nil
+
+// EvaluateContext evaluates node with a fresh budget, aborting with an
+// *object.LimitError once ctx is done or any of env's limits is exceeded.
+func EvaluateContext(ctx context.Context, node ast.Node, env *object.Environment) (object.Object, object.Interruption) {
+	runtime := env.Runtime
+	previous := runtime.Context
+	runtime.Context = ctx
+	defer func() { runtime.Context = previous }()
+	runtime.Enter()
+	defer runtime.Leave()
+
+	runtime.Reset()
+	if err := runtime.Cancelled(); err != nil {
+		return nil, err
+	}
+	return Evaluate(node, env)
+}
 
 func Evaluate(node ast.Node, env *object.Environment) (object.Object, object.Interruption) {
+	if err := env.Runtime.Step(); err != nil {
+		return nil, err
+	}
+
+	if env.Runtime.Observer != nil {
+		return observe(node, env, evaluateNode)
+	}
+
+	value, interrupt := evaluateNode(node, env)
+	if interrupt != nil {
+		raise(interrupt, node, env)
+	}
+	return value, interrupt
+}
+
+func observe(node ast.Node, env *object.Environment, evaluate func(ast.Node, *object.Environment) (object.Object, object.Interruption)) (object.Object, object.Interruption) {
+	env.Runtime.Observer.Enter(node, env)
+	value, interrupt := evaluate(node, env)
+	if interrupt != nil {
+		raise(interrupt, node, env)
+	}
+	// Spawning a task may have replaced the observer with a locked one.
+	env.Runtime.Observer.Exit(node, env, value, interrupt)
+	return value, interrupt
+}
+
+// raise records node as the origin of an error that does not know where it
+// was raised yet.
+func raise(interrupt object.Interruption, node ast.Node, env *object.Environment) {
+	if err, ok := interrupt.(*object.Error); ok && !err.Position.IsValid() {
+		err.Position = ast.Position(node)
+		if observer := env.Runtime.Observer; observer != nil {
+			observer.Raise(err, env)
+		}
+	}
+}
+
+func evaluateNode(node ast.Node, env *object.Environment) (object.Object, object.Interruption) {
 	switch node.Type() {
 	case ast.PROGRAM:
 		return evaluateProgram(node.(*ast.Program), env)
@@ -48,8 +105,12 @@ func Evaluate(node ast.Node, env *object.Environment) (object.Object, object.Int
 		return evaluateSubscriptExpression(node.(*ast.SubscriptExpression), env)
 	case ast.IDENTIFIER:
 		return evaluateIdentifier(node.(*ast.Identifier), env)
-	case ast.NUMBER_LITERAL:
-		return object.Number(node.(*ast.NumberLiteral).Value), nil
+	case ast.INTEGER_LITERAL:
+		return integerLiteral(node.(*ast.IntegerLiteral)), nil
+	case ast.FLOAT_LITERAL:
+		return object.Float(node.(*ast.FloatLiteral).Value), nil
+	case ast.DECIMAL_LITERAL:
+		return &object.Decimal{Value: node.(*ast.DecimalLiteral).Value}, nil
 	case ast.BOOLEAN_LITERAL:
 		return toBoolean(node.(*ast.BooleanLiteral).Value), nil
 	case ast.STRING_LITERAL:
@@ -60,19 +121,31 @@ func Evaluate(node ast.Node, env *object.Environment) (object.Object, object.Int
 		return evaluateHashLiteral(node.(*ast.HashLiteral), env)
 	case ast.NULL_LITERAL:
 		return NULL, nil
+	case ast.YIELD_EXPRESSION:
+		return evaluateYieldExpression(node.(*ast.YieldExpression), env)
+	case ast.IMPORT_STATEMENT:
+		return evaluateImportStatement(node.(*ast.ImportStatement), env)
 	default:
 		panic(fmt.Errorf("unexpected node type: %T", node))
 	}
 }
 
 func evaluateProgram(node *ast.Program, env *object.Environment) (object.Object, object.Interruption) {
+	// Resolving fills in the nodes, which expanding macros shares with the
+	// program the caller holds, so a copy is resolved instead.
+	node = ast.Clone(node).(*ast.Program)
+	Resolve(node)
+
 	var result object.Object
 	var interrupt object.Interruption
 	for _, stmt := range node.Statements {
 		result, interrupt = Evaluate(stmt, env)
 		if interrupt != nil {
-			if interrupt.Type() == object.RETURN_VALUE {
-				return interrupt.(*object.ReturnValue).Value, nil
+			switch interrupt := interrupt.(type) {
+			case *object.ReturnValue:
+				return interrupt.Value, nil
+			case *object.TailCall:
+				return applyFunction(interrupt.Function, interrupt.Arguments, interrupt.Position, env.Runtime)
 			}
 			return nil, interrupt
 		}
@@ -85,12 +158,29 @@ func evaluateLetDeclaration(node *ast.LetDeclaration, env *object.Environment) (
 	if interrupt != nil {
 		return nil, interrupt
 	}
-	env.Set(node.Name.Value, value)
+	if fn, ok := value.(*object.Function); ok && fn.Name == "" {
+		fn.Name = node.Name.Value
+	}
+	bind(node.Name, env, value)
+	return NULL, nil
+}
+
+func evaluateImportStatement(node *ast.ImportStatement, env *object.Environment) (object.Object, object.Interruption) {
+	importer := env.Runtime.Importer
+	if importer == nil {
+		return nil, toError("cannot import %q: no module search path is set", node.Path.Value)
+	}
+
+	module, interrupt := importer.Import(node.Path.Value, env.Runtime)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+	bind(node.Name, env, module)
 	return NULL, nil
 }
 
 func evaluateReturnStatement(node *ast.ReturnStatement, env *object.Environment) (object.Object, object.Interruption) {
-	value, interrupt := Evaluate(node.ReturnValue, env)
+	value, interrupt := evaluateTail(node.ReturnValue, env)
 	if interrupt != nil {
 		return nil, interrupt
 	}
@@ -118,20 +208,7 @@ func evaluateUnaryExpression(node *ast.UnaryExpression, env *object.Environment)
 	if interrupt != nil {
 		return nil, interrupt
 	}
-	switch node.Operator {
-	case "!":
-		if isTruthy(right) {
-			return FALSE, nil
-		}
-		return TRUE, nil
-	case "-":
-		switch right.Type() {
-		case object.NUMBER:
-			return object.Number(-right.(object.Number)), nil
-		}
-	}
-
-	return nil, toError("unknown operator: %s%s", node.Operator, right.Type())
+	return Unary(node.Operator, right)
 }
 
 func evaluateBinaryExpression(node *ast.BinaryExpression, env *object.Environment) (object.Object, object.Interruption) {
@@ -144,28 +221,22 @@ func evaluateBinaryExpression(node *ast.BinaryExpression, env *object.Environmen
 	if interrupt != nil {
 		return nil, interrupt
 	}
+
+	value, interrupt := Binary(node.Operator, left, right)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+	return allocate(value, env)
+}
+
+func Binary(operator string, left, right object.Object) (object.Object, object.Interruption) {
 	switch {
-	case left.Type() == object.NUMBER && right.Type() == object.NUMBER:
-		switch node.Operator {
-		case "+":
-			return object.Number(left.(object.Number) + right.(object.Number)), nil
-		case "-":
-			return object.Number(left.(object.Number) - right.(object.Number)), nil
-		case "*":
-			return object.Number(left.(object.Number) * right.(object.Number)), nil
-		case "/":
-			return object.Number(left.(object.Number) / right.(object.Number)), nil
-		case "<":
-			return toBoolean(left.(object.Number) < right.(object.Number)), nil
-		case ">":
-			return toBoolean(left.(object.Number) > right.(object.Number)), nil
-		case "==":
-			return toBoolean(left.(object.Number) == right.(object.Number)), nil
-		case "!=":
-			return toBoolean(left.(object.Number) != right.(object.Number)), nil
+	case isNumber(left) && isNumber(right):
+		if result, interrupt, ok := numberBinary(operator, left, right); ok {
+			return result, interrupt
 		}
 	case left.Type() == object.STRING && right.Type() == object.STRING:
-		switch node.Operator {
+		switch operator {
 		case "+":
 			return object.String(left.(object.String) + right.(object.String)), nil
 		case "==":
@@ -174,14 +245,30 @@ func evaluateBinaryExpression(node *ast.BinaryExpression, env *object.Environmen
 			return toBoolean(left.(object.String) != right.(object.String)), nil
 		}
 	case left.Type() != right.Type():
-		return nil, toError("type mismatch: %s %s %s", left.Type(), node.Operator, right.Type())
-	case node.Operator == "==":
+		return nil, toError("type mismatch: %s %s %s", left.Type(), operator, right.Type())
+	case operator == "==":
 		return toBoolean(left == right), nil
-	case node.Operator == "!=":
+	case operator == "!=":
 		return toBoolean(left != right), nil
 	}
 
-	return nil, toError("unknown operator: %s %s %s", left.Type(), node.Operator, right.Type())
+	return nil, toError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+}
+
+func Unary(operator string, right object.Object) (object.Object, object.Interruption) {
+	switch operator {
+	case "!":
+		if IsTruthy(right) {
+			return FALSE, nil
+		}
+		return TRUE, nil
+	case "-":
+		if isNumber(right) {
+			return negate(right), nil
+		}
+	}
+
+	return nil, toError("unknown operator: %s%s", operator, right.Type())
 }
 
 func evaluateLogicalExpression(node *ast.LogicalExpression, env *object.Environment) (object.Object, object.Interruption) {
@@ -192,12 +279,12 @@ func evaluateLogicalExpression(node *ast.LogicalExpression, env *object.Environm
 
 	switch node.Operator {
 	case "or":
-		if isTruthy(left) {
+		if IsTruthy(left) {
 			return left, nil
 		}
 		return Evaluate(node.Right, env)
 	case "and":
-		if !isTruthy(left) {
+		if !IsTruthy(left) {
 			return left, nil
 		}
 		return Evaluate(node.Right, env)
@@ -215,7 +302,7 @@ func evaluateConditionalExpression(node *ast.ConditionalExpression, env *object.
 	if interrupt != nil {
 		return nil, interrupt
 	}
-	if isTruthy(condition) {
+	if IsTruthy(condition) {
 		return Evaluate(node.Consequence, env)
 	} else if node.Alternative != nil {
 		return Evaluate(node.Alternative, env)
@@ -231,8 +318,7 @@ func evaluateAssignmentExpression(node *ast.AssignmentExpression, env *object.En
 
 	switch lvalue := node.LValue.(type) {
 	case *ast.Identifier:
-		if _, found := env.Get(lvalue.Value); found {
-			env.Set(lvalue.Value, rvalue)
+		if assign(lvalue, env, rvalue) {
 			return rvalue, nil
 		}
 
@@ -248,43 +334,10 @@ func evaluateAssignmentExpression(node *ast.AssignmentExpression, env *object.En
 			return nil, interrupt
 		}
 
-		switch base := baseValue.(type) {
-		case *object.Array:
-			subscript, ok := subscriptValue.(object.Number)
-			if !ok {
-				return nil, toError("unknown operator: %s[%s]", baseValue.Type(), subscriptValue.Type())
-			}
-
-			index, valid := toNativeInt(subscript)
-			if !valid || index < 0 {
-				return nil, toError("subscript value must be a positive whole number: %s", subscript.Inspect())
-			}
-
-			if index >= cap(base.Elements) {
-				current := cap(base.Elements)
-				base.Elements = slices.Grow(base.Elements, index+1-current)
-				base.Elements = base.Elements[:cap(base.Elements)]
-				for i := current; i < index; i += 1 {
-					base.Elements[i] = NULL
-				}
-			} else {
-				base.Elements = base.Elements[:cap(base.Elements)]
-			}
-			base.Elements[index] = rvalue
-		case *object.Hash:
-			key, ok := subscriptValue.(object.Hashable)
-			if !ok {
-				return nil, toError("unknown operator: %s[%s]", baseValue.Type(), subscriptValue.Type())
-			}
-			base.Pairs[key.HashKey()] = object.HashPair{Key: key, Value: rvalue}
-		default:
-			return nil, toError("unknown operator: %s[%s]", base.Type(), subscriptValue.Type())
-		}
+		return SetIndex(env.Runtime, baseValue, subscriptValue, rvalue)
 	default:
 		panic(fmt.Errorf("unknown lvalue type: %s", node.LValue.Type()))
 	}
-
-	return rvalue, nil
 }
 
 func evaluateCallExpression(node *ast.CallExpression, env *object.Environment) (object.Object, object.Interruption) {
@@ -293,25 +346,212 @@ func evaluateCallExpression(node *ast.CallExpression, env *object.Environment) (
 		return nil, interrupt
 	}
 
-	switch callee := value.(type) {
+	if callee, ok := value.(*object.Function); ok {
+		args, interrupt := evaluateArguments(callee, node, env)
+		if interrupt != nil {
+			return nil, interrupt
+		}
+		return applyFunction(callee, args, ast.Position(node), env.Runtime)
+	}
+	return callBuiltin(value, node, env)
+}
+
+func evaluateTailCall(node *ast.CallExpression, env *object.Environment) (object.Object, object.Interruption) {
+	value, interrupt := Evaluate(node.Callee, env)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+
+	if callee, ok := value.(*object.Function); ok {
+		args, interrupt := evaluateArguments(callee, node, env)
+		if interrupt != nil {
+			return nil, interrupt
+		}
+		return nil, &object.TailCall{Function: callee, Arguments: args, Position: ast.Position(node)}
+	}
+
+	value, interrupt = callBuiltin(value, node, env)
+	if interrupt != nil {
+		raise(interrupt, node, env)
+	}
+	return value, interrupt
+}
+
+// ApplyContext calls fn with args from Go with a fresh budget, like
+// EvaluateContext; env supplies the runtime and is what builtins see.
+func ApplyContext(ctx context.Context, fn object.Object, args []object.Object, env *object.Environment) (object.Object, object.Interruption) {
+	runtime := env.Runtime
+	previous := runtime.Context
+	runtime.Context = ctx
+	defer func() { runtime.Context = previous }()
+	runtime.Enter()
+	defer runtime.Leave()
+
+	runtime.Reset()
+	if err := runtime.Cancelled(); err != nil {
+		return nil, err
+	}
+	return Apply(fn, args, env)
+}
+
+// Apply calls fn with args from Go within the evaluation that is running,
+// as a builtin calling back into Monkey does.
+func Apply(fn object.Object, args []object.Object, env *object.Environment) (object.Object, object.Interruption) {
+	switch callee := fn.(type) {
 	case *object.Function:
-		environment := object.NewEnvironment(callee.Closure)
-		for i, parameter := range callee.Literal.Parameters {
-			value, interrupt := Evaluate(node.Arguments[i], env)
-			if interrupt != nil {
-				return nil, interrupt
-			}
-			environment.Set(parameter.Value, value)
+		if callee.Literal == nil {
+			return nil, toError("not a function: %s", fn.Type())
+		}
+		if len(args) != len(callee.Literal.Parameters) {
+			return nil, toError("wrong number of arguments: want=%d, got=%d", len(callee.Literal.Parameters), len(args))
 		}
+		size := len(args)
+		if scope := callee.Literal.Scope; scope != nil {
+			size = len(scope.Names)
+		}
+		// The frame reuses the capacity of its arguments, so they are copied
+		// rather than shared with the caller.
+		frame := make([]object.Object, len(args), size)
+		copy(frame, args)
+		return applyFunction(callee, frame, token.Position{}, env.Runtime)
+	case *object.BuiltinFunction:
+		return callee.Fn(env, args...)
+	default:
+		return nil, toError("not a function: %s", fn.Type())
+	}
+}
+
+func evaluateArguments(callee *object.Function, node *ast.CallExpression, env *object.Environment) ([]object.Object, object.Interruption) {
+	if len(node.Arguments) < len(callee.Literal.Parameters) {
+		return nil, toError("wrong number of arguments: want=%d, got=%d", len(callee.Literal.Parameters), len(node.Arguments))
+	}
+
+	size := len(callee.Literal.Parameters)
+	if scope := callee.Literal.Scope; scope != nil {
+		size = len(scope.Names)
+	}
 
-		result, interrupt := Evaluate(callee.Literal.Body, environment)
+	// The arguments become the first slots of the callee's frame.
+	args := make([]object.Object, len(callee.Literal.Parameters), size)
+	for i := range callee.Literal.Parameters {
+		value, interrupt := Evaluate(node.Arguments[i], env)
 		if interrupt != nil {
-			if interrupt.Type() == object.RETURN_VALUE {
-				return interrupt.(*object.ReturnValue).Value, nil
-			}
 			return nil, interrupt
 		}
-		return result, nil
+		args[i] = value
+	}
+	return args, nil
+}
+
+func applyFunction(callee *object.Function, args []object.Object, position token.Position, runtime *object.Runtime) (object.Object, object.Interruption) {
+	if err := runtime.Push(object.Frame{Function: callee, Position: position}); err != nil {
+		return nil, err
+	}
+	defer runtime.Pop()
+
+	for {
+		environment := newFrame(callee, args, runtime)
+		if isGenerator(callee.Literal) {
+			return newGenerator(callee, position, environment), nil
+		}
+
+		if observer := runtime.Observer; observer != nil {
+			observer.Call(callee, args, position, environment)
+		}
+		result, interrupt := evaluateBody(callee.Literal.Body, environment)
+
+		switch signal := interrupt.(type) {
+		case *object.TailCall:
+			if observer := runtime.Observer; observer != nil {
+				observer.Return(callee, nil, signal)
+			}
+			callee, args, position = signal.Function, signal.Arguments, signal.Position
+			runtime.Replace(object.Frame{Function: callee, Position: position})
+			continue
+		case *object.ReturnValue:
+			result, interrupt = signal.Value, nil
+		case *object.Error:
+			if signal.Frames == nil {
+				signal.Frames = slices.Clone(runtime.Frames)
+			}
+		}
+
+		if observer := runtime.Observer; observer != nil {
+			observer.Return(callee, result, interrupt)
+		}
+		return result, interrupt
+	}
+}
+
+func newFrame(callee *object.Function, args []object.Object, runtime *object.Runtime) *object.Environment {
+	var environment *object.Environment
+	if scope := callee.Literal.Scope; scope != nil {
+		environment = object.NewFrameEnvironment(callee.Closure, scope.Names, args)
+	} else {
+		environment = object.NewEnvironment(callee.Closure)
+		for i, parameter := range callee.Literal.Parameters {
+			environment.Set(parameter.Value, args[i])
+		}
+	}
+	// A function copied into a task closes over environments of the
+	// evaluation that spawned it, but runs on the task's runtime.
+	environment.Runtime = runtime
+	return environment
+}
+
+// isGenerator reports whether fn yields, as the resolver records in its
+// scope, looking through its body when it was not resolved.
+func isGenerator(fn *ast.FunctionLiteral) bool {
+	if fn.Scope != nil {
+		return fn.Scope.Generator
+	}
+	yields := false
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		switch node.(type) {
+		case *ast.FunctionLiteral:
+			return false
+		case *ast.YieldExpression:
+			yields = true
+		}
+		return !yields
+	})
+	return yields
+}
+
+// newGenerator returns an iterator that runs the body of callee in
+// environment up to each of its yields, one value at a time.
+func newGenerator(callee *object.Function, position token.Position, environment *object.Environment) *object.Iterator {
+	runtime := environment.Runtime
+	frame := object.Frame{Function: callee, Position: position}
+	return object.NewGenerator(runtime, frame, func() (object.Object, object.Interruption) {
+		result, interrupt := evaluateBody(callee.Literal.Body, environment)
+		switch signal := interrupt.(type) {
+		case *object.TailCall:
+			return applyFunction(signal.Function, signal.Arguments, signal.Position, runtime)
+		case *object.ReturnValue:
+			return signal.Value, nil
+		case *object.Error:
+			if signal.Frames == nil {
+				signal.Frames = slices.Clone(runtime.Frames)
+			}
+		}
+		return result, interrupt
+	})
+}
+
+func evaluateYieldExpression(node *ast.YieldExpression, env *object.Environment) (object.Object, object.Interruption) {
+	value, interrupt := Evaluate(node.Value, env)
+	if interrupt != nil {
+		return nil, interrupt
+	}
+	if interrupt := env.Runtime.Yield(value); interrupt != nil {
+		return nil, interrupt
+	}
+	return NULL, nil
+}
+
+func callBuiltin(value object.Object, node *ast.CallExpression, env *object.Environment) (object.Object, object.Interruption) {
+	switch callee := value.(type) {
 	case *object.BuiltinFunction:
 		args := []object.Object{}
 		for _, arg := range node.Arguments {
@@ -321,6 +561,9 @@ func evaluateCallExpression(node *ast.CallExpression, env *object.Environment) (
 			}
 			args = append(args, value)
 		}
+		if observer := env.Runtime.Observer; observer != nil {
+			observer.CallBuiltin(node.Callee.String(), args, ast.Position(node), env)
+		}
 		return callee.Fn(env, args...)
 	case *object.BuiltinMacro:
 		args := []object.Object{}
@@ -333,6 +576,56 @@ func evaluateCallExpression(node *ast.CallExpression, env *object.Environment) (
 	}
 }
 
+func evaluateBody(node *ast.BlockStatement, env *object.Environment) (object.Object, object.Interruption) {
+	var result object.Object
+	var interrupt object.Interruption
+	for i, stmt := range node.Statements {
+		if es, ok := stmt.(*ast.ExpressionStatement); ok && i == len(node.Statements)-1 {
+			return evaluateTail(es.Expression, env)
+		}
+
+		result, interrupt = Evaluate(stmt, env)
+		if interrupt != nil {
+			return nil, interrupt
+		}
+	}
+	return result, nil
+}
+
+func evaluateTail(node ast.Expression, env *object.Environment) (object.Object, object.Interruption) {
+	switch node.(type) {
+	case *ast.CallExpression, *ast.ConditionalExpression:
+		if env.Runtime.Observer != nil {
+			return observe(node, env, evaluateTailNode)
+		}
+		return evaluateTailNode(node, env)
+	default:
+		return Evaluate(node, env)
+	}
+}
+
+// evaluateTailNode evaluates a call or conditional in tail position, which
+// do not go through Evaluate.
+func evaluateTailNode(node ast.Node, env *object.Environment) (object.Object, object.Interruption) {
+	switch node := node.(type) {
+	case *ast.CallExpression:
+		return evaluateTailCall(node, env)
+	case *ast.ConditionalExpression:
+		condition, interrupt := Evaluate(node.Condition, env)
+		if interrupt != nil {
+			return nil, interrupt
+		}
+		if IsTruthy(condition) {
+			return evaluateBody(node.Consequence, env)
+		} else if node.Alternative != nil {
+			return evaluateBody(node.Alternative, env)
+		}
+		return NULL, nil
+	default:
+		panic(fmt.Errorf("unexpected tail node type: %T", node))
+	}
+}
+
 func evaluateSubscriptExpression(node *ast.SubscriptExpression, env *object.Environment) (object.Object, object.Interruption) {
 	baseValue, interrupt := Evaluate(node.Base, env)
 	if interrupt != nil {
@@ -344,9 +637,13 @@ func evaluateSubscriptExpression(node *ast.SubscriptExpression, env *object.Envi
 		return nil, interrupt
 	}
 
+	return Index(baseValue, subscriptValue)
+}
+
+func Index(baseValue, subscriptValue object.Object) (object.Object, object.Interruption) {
 	switch base := baseValue.(type) {
 	case *object.Array:
-		subscript, ok := subscriptValue.(object.Number)
+		subscript, ok := subscriptValue.(object.Integer)
 		if !ok {
 			return nil, toError("unknown operator: %s[%s]", baseValue.Type(), subscriptValue.Type())
 		}
@@ -367,27 +664,131 @@ func evaluateSubscriptExpression(node *ast.SubscriptExpression, env *object.Envi
 			return NULL, nil
 		}
 		return value.Value, nil
+	case *object.Module:
+		name, ok := subscriptValue.(object.String)
+		if !ok {
+			return nil, toError("unknown operator: %s[%s]", baseValue.Type(), subscriptValue.Type())
+		}
+
+		value, found := base.Members[string(name)]
+		if !found {
+			return nil, toError("module %q does not export %s", base.Path, string(name))
+		}
+		return value, nil
 	default:
 		return nil, toError("unknown operator: %s[%s]", base.Type(), subscriptValue.Type())
 	}
 }
 
+// SetIndex stores rvalue at subscript of baseValue, growing an array up to
+// the index and charging the growth and new hash pairs to rt.
+func SetIndex(rt *object.Runtime, baseValue, subscriptValue, rvalue object.Object) (object.Object, object.Interruption) {
+	switch base := baseValue.(type) {
+	case *object.Array:
+		subscript, ok := subscriptValue.(object.Integer)
+		if !ok {
+			return nil, toError("unknown operator: %s[%s]", baseValue.Type(), subscriptValue.Type())
+		}
+
+		index, valid := toNativeInt(subscript)
+		if !valid || index < 0 || index == math.MaxInt {
+			return nil, toError("subscript value must be a positive whole number: %s", subscript.Inspect())
+		}
+
+		if current := len(base.Elements); index >= current {
+			if err := rt.Extend(base, index+1-current); err != nil {
+				return nil, err
+			}
+			base.Elements = slices.Grow(base.Elements, index+1-current)[:index+1]
+			for i := current; i < index; i += 1 {
+				base.Elements[i] = NULL
+			}
+		}
+		base.Elements[index] = rvalue
+	case *object.Hash:
+		key, ok := subscriptValue.(object.Hashable)
+		if !ok {
+			return nil, toError("unknown operator: %s[%s]", baseValue.Type(), subscriptValue.Type())
+		}
+		hashKey := key.HashKey()
+		if _, found := base.Pairs[hashKey]; !found {
+			if err := rt.Extend(base, 1); err != nil {
+				return nil, err
+			}
+		}
+		base.Pairs[hashKey] = object.HashPair{Key: key, Value: rvalue}
+	default:
+		return nil, toError("unknown operator: %s[%s]", base.Type(), subscriptValue.Type())
+	}
+	return rvalue, nil
+}
+
 func evaluateFunctionLiteral(node *ast.FunctionLiteral, env *object.Environment) (object.Object, object.Interruption) {
-	return &object.Function{Literal: node, Closure: env}, nil
+	return allocate(&object.Function{Literal: node, Closure: env}, env)
 }
 
 func evaluateIdentifier(node *ast.Identifier, env *object.Environment) (object.Object, object.Interruption) {
-	if value, found := env.Get(node.Value); found {
+	if value, found := lookup(node, env); found {
 		return value, nil
 	}
 
-	if builtin, found := builtins[node.Value]; found {
+	if builtin, found := LookupBuiltin(node.Value); found {
 		return builtin, nil
 	}
 
 	return nil, toError("unknown identifier: %s", node.Value)
 }
 
+// lookup finds the value of ident through its resolved binding, reading an
+// empty local slot through to the enclosing scopes as it has not been
+// declared yet.
+func lookup(ident *ast.Identifier, env *object.Environment) (object.Object, bool) {
+	frame := resolvedFrame(ident.Binding, env)
+	if frame == nil {
+		return env.Get(ident.Value)
+	}
+
+	if ident.Binding.Kind == ast.LOCAL {
+		if value := frame.Slots[ident.Binding.Slot]; value != nil {
+			return value, true
+		}
+		return frame.Enclosing.Get(ident.Value)
+	}
+	return frame.Get(ident.Value)
+}
+
+// bind declares ident in the frame it was resolved to, or in env.
+func bind(ident *ast.Identifier, env *object.Environment, value object.Object) {
+	if frame := resolvedFrame(ident.Binding, env); frame != nil && ident.Binding.Kind == ast.LOCAL {
+		frame.Slots[ident.Binding.Slot] = value
+		return
+	}
+	env.Set(ident.Value, value)
+}
+
+// assign writes value through to the variable ident refers to, wherever it
+// was declared, and reports whether there was one.
+func assign(ident *ast.Identifier, env *object.Environment, value object.Object) bool {
+	if frame := resolvedFrame(ident.Binding, env); frame != nil && ident.Binding.Kind == ast.LOCAL && frame.Slots[ident.Binding.Slot] != nil {
+		frame.Slots[ident.Binding.Slot] = value
+		return true
+	}
+	return env.Assign(ident.Value, value)
+}
+
+func resolvedFrame(binding ast.Binding, env *object.Environment) *object.Environment {
+	if binding.Kind == ast.UNRESOLVED {
+		return nil
+	}
+	for i := 0; i < binding.Depth && env != nil; i += 1 {
+		env = env.Enclosing
+	}
+	if env == nil || (binding.Kind == ast.LOCAL && binding.Slot >= len(env.Slots)) {
+		return nil
+	}
+	return env
+}
+
 func evaluateArrayLiteral(node *ast.ArrayLiteral, env *object.Environment) (object.Object, object.Interruption) {
 	elements := []object.Object{}
 	for _, element := range node.Elements {
@@ -397,7 +798,7 @@ func evaluateArrayLiteral(node *ast.ArrayLiteral, env *object.Environment) (obje
 		}
 		elements = append(elements, value)
 	}
-	return &object.Array{Elements: elements}, nil
+	return allocate(&object.Array{Elements: elements}, env)
 }
 
 func evaluateHashLiteral(node *ast.HashLiteral, env *object.Environment) (object.Object, object.Interruption) {
@@ -420,7 +821,19 @@ func evaluateHashLiteral(node *ast.HashLiteral, env *object.Environment) (object
 
 		hash.Pairs[hashable.HashKey()] = object.HashPair{Key: key, Value: value}
 	}
-	return hash, nil
+	return allocate(hash, env)
+}
+
+func allocate(value object.Object, env *object.Environment) (object.Object, object.Interruption) {
+	if err := env.Runtime.Allocate(value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func LookupBuiltin(name string) (object.Builtin, bool) {
+	builtin, found := builtins[name]
+	return builtin, found
 }
 
 func toBoolean(value bool) object.Boolean {
@@ -434,18 +847,22 @@ func toError(format string, args ...any) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, args...)}
 }
 
-func toNativeInt(subscript object.Number) (int, bool) {
-	if subscript == object.Number(math.Trunc(float64(subscript))) {
+func toNativeInt(subscript object.Integer) (int, bool) {
+	if object.Integer(int(subscript)) == subscript {
 		return int(subscript), true
 	}
 	return 0, false
 }
 
-func isTruthy(o object.Object) object.Boolean {
+func IsTruthy(o object.Object) object.Boolean {
 	switch {
 	case o == FALSE:
 		return FALSE
-	case o.Type() == object.NUMBER && o.(object.Number) == 0.0:
+	case o.Type() == object.INTEGER && o.(object.Integer) == 0:
+		return FALSE
+	case o.Type() == object.FLOAT && o.(object.Float) == 0.0:
+		return FALSE
+	case o.Type() == object.DECIMAL && o.(*object.Decimal).Value.Sign() == 0:
 		return FALSE
 	case o.Type() == object.STRING && o.(object.String) == "":
 		return FALSE
@@ -8,18 +8,28 @@ import (
 )
 
 func BenchmarkEvaluate(b *testing.B) {
+	benchmarkSuites(b, nil)
+}
+
+// BenchmarkEvaluateObserved measures the cost of observing an evaluation,
+// to compare against BenchmarkEvaluate.
+func BenchmarkEvaluateObserved(b *testing.B) {
+	benchmarkSuites(b, object.BaseObserver{})
+}
+
+func benchmarkSuites(b *testing.B, observer object.Observer) {
 	for _, suite := range suites {
 		b.Run(suite.name, func(b *testing.B) {
 			for b.Loop() {
 				for i, test := range suite.tests {
-					benchmarkEvaluator(b, i, test)
+					benchmarkEvaluator(b, i, test, observer)
 				}
 			}
 		})
 	}
 }
 
-func benchmarkEvaluator(tb testing.TB, i int, test EvaluatorTest) {
+func benchmarkEvaluator(tb testing.TB, i int, test EvaluatorTest, observer object.Observer) {
 	p := parser.NewParser(test.input, false)
 	program := p.ParseProgram()
 
@@ -31,8 +41,10 @@ func benchmarkEvaluator(tb testing.TB, i int, test EvaluatorTest) {
 		tb.FailNow()
 	}
 
-	_, interrupt := Evaluate(program, object.NewEnvironment(nil))
-	if interrupt != nil {
+	env := object.NewEnvironment(nil)
+	env.Runtime.Observer = observer
+	_, interrupt := Evaluate(program, env)
+	if interrupt != nil && test.error.Message == "" {
 		tb.Errorf("test[%d] - Evaluate() (*object.Error) ==> expected: <%#v> but was: <%s>", i, nil, interrupt.(*object.Error).Message)
 		tb.Fatalf("test[%d] - <%s>", i, program.String())
 	}
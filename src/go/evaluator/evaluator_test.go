@@ -1,8 +1,15 @@
 package evaluator
 
 import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
 )
@@ -18,7 +25,10 @@ type ObjectTest interface {
 }
 
 func (f FunctionTest) object() {}
-func (n NumberTest) object()   {}
+func (n IntegerTest) object()  {}
+func (f FloatTest) object()    {}
+func (b BigIntTest) object()   {}
+func (d DecimalTest) object()  {}
 func (b BooleanTest) object()  {}
 func (s StringTest) object()   {}
 func (a ArrayTest) object()    {}
@@ -36,13 +46,18 @@ type FunctionTest struct {
 }
 
 type (
-	NumberTest  float64
+	IntegerTest int64
+	FloatTest   float64
 	BooleanTest bool
 	StringTest  string
 )
 
-func (n NumberTest) hashable() object.Hashable {
-	return object.Number(n)
+func (n IntegerTest) hashable() object.Hashable {
+	return object.Integer(n)
+}
+
+func (f FloatTest) hashable() object.Hashable {
+	return object.Float(f)
 }
 
 func (b BooleanTest) hashable() object.Hashable {
@@ -53,6 +68,13 @@ func (s StringTest) hashable() object.Hashable {
 	return object.String(s)
 }
 
+// BigIntTest and DecimalTest hold the expected Inspect of a big integer or
+// a decimal.
+type (
+	BigIntTest  string
+	DecimalTest string
+)
+
 type ArrayTest struct {
 	Elements []ObjectTest
 }
@@ -80,63 +102,237 @@ var suites = []struct {
 		tests: []EvaluatorTest{
 			{
 				input:  `5`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `10`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `-5`,
-				object: NumberTest(-5),
+				object: IntegerTest(-5),
 			},
 			{
 				input:  `-10`,
-				object: NumberTest(-10),
+				object: IntegerTest(-10),
 			},
 			{
 				input:  `5 + 5 + 5 + 5 - 10`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `2 * 2 * 2 * 2 * 2`,
-				object: NumberTest(32),
+				object: IntegerTest(32),
 			},
 			{
 				input:  `-50 + 100 + -50`,
-				object: NumberTest(0),
+				object: IntegerTest(0),
 			},
 			{
 				input:  `5 * 2 + 10`,
-				object: NumberTest(20),
+				object: IntegerTest(20),
 			},
 			{
 				input:  `5 + 2 * 10`,
-				object: NumberTest(25),
+				object: IntegerTest(25),
 			},
 			{
 				input:  `20 + 2 * -10`,
-				object: NumberTest(0),
+				object: IntegerTest(0),
 			},
 			{
 				input:  `50 / 2 * 2 + 10`,
-				object: NumberTest(60),
+				object: IntegerTest(60),
 			},
 			{
 				input:  `2 * (5 + 10)`,
-				object: NumberTest(30),
+				object: IntegerTest(30),
 			},
 			{
 				input:  `3 * 3 * 3 + 10`,
-				object: NumberTest(37),
+				object: IntegerTest(37),
 			},
 			{
 				input:  `3 * (3 * 3) + 10`,
-				object: NumberTest(37),
+				object: IntegerTest(37),
 			},
 			{
 				input:  `(5 + 10 * 2 + 15 / 3) * 2 + -10`,
-				object: NumberTest(50),
+				object: IntegerTest(50),
+			},
+		},
+	},
+	{
+		name: "TestEvaluateIntegerAndFloat",
+		tests: []EvaluatorTest{
+			{
+				input:  `7 / 2`,
+				object: IntegerTest(3),
+			},
+			{
+				input:  `-7 / 2`,
+				object: IntegerTest(-3),
+			},
+			{
+				input:  `-7 % 3`,
+				object: IntegerTest(-1),
+			},
+			{
+				input:  `7.0 / 2`,
+				object: FloatTest(3.5),
+			},
+			{
+				input:  `1 + 0.5`,
+				object: FloatTest(1.5),
+			},
+			{
+				input:  `7.5 % 2`,
+				object: FloatTest(1.5),
+			},
+			{
+				input:  `-2.5`,
+				object: FloatTest(-2.5),
+			},
+			{
+				input:  `1 == 1.0`,
+				object: BooleanTest(true),
+			},
+			{
+				input:  `2 < 2.5`,
+				object: BooleanTest(true),
+			},
+			{
+				input:  `9007199254740993 + 0`,
+				object: IntegerTest(9007199254740993),
+			},
+			{
+				input:  `{1: "int", 1.5: "float"}[1]`,
+				object: StringTest("int"),
+			},
+			{
+				input:  `{1: "int", 1.5: "float"}[1.5]`,
+				object: StringTest("float"),
+			},
+			{
+				input:  `{1: "int"}[1.0]`,
+				object: StringTest("int"),
+			},
+			{
+				input:  `{-1: "negative", 1: "positive"}[0 - 1]`,
+				object: StringTest("negative"),
+			},
+			{
+				input:  `if (0.0) { 1 } else { 2 }`,
+				object: IntegerTest(2),
+			},
+			{
+				input: `1 / 0`,
+				error: ErrorTest{"division by zero: 1 / 0"},
+			},
+			{
+				input: `1 % 0`,
+				error: ErrorTest{"division by zero: 1 % 0"},
+			},
+			{
+				input: `[1, 2][1.0]`,
+				error: ErrorTest{"unknown operator: ARRAY[FLOAT]"},
+			},
+		},
+	},
+	{
+		name: "TestEvaluateBigIntegerAndDecimal",
+		tests: []EvaluatorTest{
+			{
+				input:  `9223372036854775807 + 1`,
+				object: BigIntTest("9223372036854775808"),
+			},
+			{
+				input:  `-9223372036854775807 - 2`,
+				object: BigIntTest("-9223372036854775809"),
+			},
+			{
+				input:  `4611686018427387904 * 2`,
+				object: BigIntTest("9223372036854775808"),
+			},
+			{
+				input:  `-(-9223372036854775807 - 1)`,
+				object: BigIntTest("9223372036854775808"),
+			},
+			{
+				input:  `(-9223372036854775807 - 1) / -1`,
+				object: BigIntTest("9223372036854775808"),
+			},
+			{
+				input:  `100000000000000000000 - 99999999999999999999`,
+				object: IntegerTest(1),
+			},
+			{
+				input:  `100000000000000000000 % 7`,
+				object: IntegerTest(2),
+			},
+			{
+				input:  `100000000000000000000 > 9223372036854775807`,
+				object: BooleanTest(true),
+			},
+			{
+				input:  `100000000000000000000 * 1.5`,
+				object: FloatTest(1.5e20),
+			},
+			{
+				input:  `{100000000000000000000: "big"}[99999999999999999999 + 1]`,
+				object: StringTest("big"),
+			},
+			{
+				input:  `0.1d + 0.2d`,
+				object: DecimalTest("0.3d"),
+			},
+			{
+				input:  `0.1d + 0.2d == 0.3d`,
+				object: BooleanTest(true),
+			},
+			{
+				input:  `12.50d * 3`,
+				object: DecimalTest("37.5d"),
+			},
+			{
+				input:  `1d / 3 * 3`,
+				object: DecimalTest("1d"),
+			},
+			{
+				input:  `1d / 3`,
+				object: DecimalTest("0.33333333333333333333d"),
+			},
+			{
+				input:  `-7.5d % 2`,
+				object: DecimalTest("-1.5d"),
+			},
+			{
+				input:  `2.0d == 2`,
+				object: BooleanTest(true),
+			},
+			{
+				input:  `{2: "two"}[2.00d]`,
+				object: StringTest("two"),
+			},
+			{
+				input:  `{0.5d: "half"}[1d / 2]`,
+				object: StringTest("half"),
+			},
+			{
+				input:  `if (0.00d) { 1 } else { 2 }`,
+				object: IntegerTest(2),
+			},
+			{
+				input: `1.5d + 1.5`,
+				error: ErrorTest{"type mismatch: DECIMAL + FLOAT"},
+			},
+			{
+				input: `1d / 0`,
+				error: ErrorTest{"division by zero: 1d / 0d"},
+			},
+			{
+				input: `100000000000000000000 / 0`,
+				error: ErrorTest{"division by zero: 100000000000000000000 / 0"},
 			},
 		},
 	},
@@ -250,7 +446,7 @@ var suites = []struct {
 		tests: []EvaluatorTest{
 			{
 				input:  `if (true) { 10 }`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `if (false) { 10 }`,
@@ -258,11 +454,11 @@ var suites = []struct {
 			},
 			{
 				input:  `if (1) { 10 }`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `if (1 < 2) { 10 }`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `if (1 > 2) { 10 }`,
@@ -270,11 +466,11 @@ var suites = []struct {
 			},
 			{
 				input:  `if (1 > 2) { 10 } else { 20 }`,
-				object: NumberTest(20),
+				object: IntegerTest(20),
 			},
 			{
 				input:  `if (1 < 2) { 10 } else { 20 }`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 		},
 	},
@@ -283,19 +479,19 @@ var suites = []struct {
 		tests: []EvaluatorTest{
 			{
 				input:  `return 10;`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `return 10; 9;`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `return 2 * 5; 9;`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `9; return 2 * 5; 9;`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input: `
@@ -306,7 +502,7 @@ var suites = []struct {
 
 						return 1;
 					}`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input: `
@@ -315,7 +511,7 @@ var suites = []struct {
 						x + 10;
 					};
 					f(10);`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input: `
@@ -325,7 +521,7 @@ var suites = []struct {
 						return 10;
 					};
 					f(10);`,
-				object: NumberTest(20),
+				object: IntegerTest(20),
 			},
 		},
 	},
@@ -334,19 +530,19 @@ var suites = []struct {
 		tests: []EvaluatorTest{
 			{
 				input:  `let a = 5; a;`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `let a = 5 * 5; a;`,
-				object: NumberTest(25),
+				object: IntegerTest(25),
 			},
 			{
 				input:  `let a = 5; let b = a; b;`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `let a = 5; let b = a; let c = a + b + 5; c;`,
-				object: NumberTest(15),
+				object: IntegerTest(15),
 			},
 		},
 	},
@@ -359,27 +555,27 @@ var suites = []struct {
 			},
 			{
 				input:  `let identity = fn(x) { x; }; identity(5);`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `let identity = fn(x) { return x; }; identity(5);`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `let double = fn(x) { x * 2; }; double(5);`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `let add = fn(x, y) { x + y; }; add(5, 5);`,
-				object: NumberTest(10),
+				object: IntegerTest(10),
 			},
 			{
 				input:  `let add = fn(x, y) { x + y; }; add(5 + 5, add(5, 5));`,
-				object: NumberTest(20),
+				object: IntegerTest(20),
 			},
 			{
 				input:  `fn(x) { x; }(5)`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input: `
@@ -394,7 +590,7 @@ var suites = []struct {
 					};
 
 					ourFunction(20) + first + second;`,
-				object: NumberTest(70),
+				object: IntegerTest(70),
 			},
 			{
 				input: `
@@ -403,7 +599,23 @@ var suites = []struct {
 					};
 					let addTwo = newAdder(2);
 					addTwo(2);`,
-				object: NumberTest(4),
+				object: IntegerTest(4),
+			},
+			{
+				input:  `let x = 1; let f = fn() { x = 2; }; f(); x`,
+				object: IntegerTest(2),
+			},
+			{
+				input:  `let counter = fn() { let n = 0; fn() { n = n + 1 } }; let c = counter(); c(); c(); c()`,
+				object: IntegerTest(3),
+			},
+			{
+				input:  `let f = fn() { let n = 1; let g = fn() { n = n * 10 }; g(); g(); n }; f()`,
+				object: IntegerTest(100),
+			},
+			{
+				input:  `let x = 1; let f = fn(x) { x = x + 1; x }; [f(5), x]`,
+				object: ArrayTest{[]ObjectTest{IntegerTest(6), IntegerTest(1)}},
 			},
 		},
 	},
@@ -427,43 +639,43 @@ var suites = []struct {
 				input: `[1, 2 * 2, 3 + 3]`,
 				object: ArrayTest{
 					[]ObjectTest{
-						NumberTest(1),
-						NumberTest(4),
-						NumberTest(6),
+						IntegerTest(1),
+						IntegerTest(4),
+						IntegerTest(6),
 					},
 				},
 			},
 			{
 				input:  `[1, 2, 3][0]`,
-				object: NumberTest(1),
+				object: IntegerTest(1),
 			},
 			{
 				input:  `[1, 2, 3][1]`,
-				object: NumberTest(2),
+				object: IntegerTest(2),
 			},
 			{
 				input:  `[1, 2, 3][2]`,
-				object: NumberTest(3),
+				object: IntegerTest(3),
 			},
 			{
 				input:  `let i = 0; [1][i];`,
-				object: NumberTest(1),
+				object: IntegerTest(1),
 			},
 			{
 				input:  `[1, 2, 3][1 + 1];`,
-				object: NumberTest(3),
+				object: IntegerTest(3),
 			},
 			{
 				input:  `let array = [1, 2, 3]; array[2];`,
-				object: NumberTest(3),
+				object: IntegerTest(3),
 			},
 			{
 				input:  `let array = [1, 2, 3]; array[0] + array[1] + array[2];`,
-				object: NumberTest(6),
+				object: IntegerTest(6),
 			},
 			{
 				input:  `let array = [1, 2, 3]; let i = array[0]; array[i];`,
-				object: NumberTest(2),
+				object: IntegerTest(2),
 			},
 			{
 				input:  `[1, 2, 3][3]`,
@@ -473,6 +685,14 @@ var suites = []struct {
 				input:  `[1, 2, 3][-1]`,
 				object: NullTest{},
 			},
+			{
+				input:  `let array = push([1], 2); array[3] = 4; array`,
+				object: ArrayTest{[]ObjectTest{IntegerTest(1), IntegerTest(2), NullTest{}, IntegerTest(4)}},
+			},
+			{
+				input:  `let array = push([1], 2); array[4] = 5; len(array)`,
+				object: IntegerTest(5),
+			},
 		},
 	},
 	{
@@ -490,18 +710,18 @@ var suites = []struct {
 					}`,
 				object: HashTest{
 					map[HashableTest]ObjectTest{
-						StringTest("one"):   NumberTest(1),
-						StringTest("two"):   NumberTest(2),
-						StringTest("three"): NumberTest(3),
-						NumberTest(4):       NumberTest(4),
-						BooleanTest(true):   NumberTest(5),
-						BooleanTest(false):  NumberTest(6),
+						StringTest("one"):   IntegerTest(1),
+						StringTest("two"):   IntegerTest(2),
+						StringTest("three"): IntegerTest(3),
+						IntegerTest(4):      IntegerTest(4),
+						BooleanTest(true):   IntegerTest(5),
+						BooleanTest(false):  IntegerTest(6),
 					},
 				},
 			},
 			{
 				input:  `{"foo": 5}["foo"]`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `{"foo": 5}["bar"]`,
@@ -509,7 +729,7 @@ var suites = []struct {
 			},
 			{
 				input:  `let key = "foo"; {"foo": 5}[key]`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `{}["foo"]`,
@@ -517,15 +737,15 @@ var suites = []struct {
 			},
 			{
 				input:  `{5: 5}[5]`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `{true: 5}[true]`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 			{
 				input:  `{false: 5}[false]`,
-				object: NumberTest(5),
+				object: IntegerTest(5),
 			},
 		},
 	},
@@ -579,6 +799,18 @@ var suites = []struct {
 				input: `foobar`,
 				error: ErrorTest{"unknown identifier: foobar"},
 			},
+			{
+				input: `let f = fn(a, b) { a }; f(1)`,
+				error: ErrorTest{"wrong number of arguments: want=2, got=1"},
+			},
+			{
+				input: `let pick = fn(c, x) { if (c) { x } else { 0 } }; pick(false, nope)`,
+				error: ErrorTest{"unknown identifier: nope"},
+			},
+			{
+				input: `let f = fn(a, b) { a }; let g = fn() { f() }; g()`,
+				error: ErrorTest{"wrong number of arguments: want=2, got=0"},
+			},
 			{
 				input: `{"name": "Monkey"}[fn(x) { x }];`,
 				error: ErrorTest{"unknown operator: HASH[FUNCTION]"},
@@ -594,15 +826,15 @@ var suites = []struct {
 		tests: []EvaluatorTest{
 			{
 				input:  `len("")`,
-				object: NumberTest(0),
+				object: IntegerTest(0),
 			},
 			{
 				input:  `len("four")`,
-				object: NumberTest(4),
+				object: IntegerTest(4),
 			},
 			{
 				input:  `len("hello world")`,
-				object: NumberTest(11),
+				object: IntegerTest(11),
 			},
 			{
 				input: `len(1)`,
@@ -614,11 +846,11 @@ var suites = []struct {
 			},
 			{
 				input:  `len([1, 2, 3])`,
-				object: NumberTest(3),
+				object: IntegerTest(3),
 			},
 			{
 				input:  `len([])`,
-				object: NumberTest(0),
+				object: IntegerTest(0),
 			},
 			{
 				input:  `puts("hello", "world!")`,
@@ -626,7 +858,7 @@ var suites = []struct {
 			},
 			{
 				input:  `first([1, 2, 3])`,
-				object: NumberTest(1),
+				object: IntegerTest(1),
 			},
 			{
 				input:  `first([])`,
@@ -638,7 +870,7 @@ var suites = []struct {
 			},
 			{
 				input:  `last([1, 2, 3])`,
-				object: NumberTest(3),
+				object: IntegerTest(3),
 			},
 			{
 				input:  `last([])`,
@@ -652,8 +884,8 @@ var suites = []struct {
 				input: `rest([1, 2, 3])`,
 				object: ArrayTest{
 					[]ObjectTest{
-						NumberTest(2),
-						NumberTest(3),
+						IntegerTest(2),
+						IntegerTest(3),
 					},
 				},
 			},
@@ -665,7 +897,7 @@ var suites = []struct {
 				input: `push([], 1)`,
 				object: ArrayTest{
 					[]ObjectTest{
-						NumberTest(1),
+						IntegerTest(1),
 					},
 				},
 			},
@@ -673,6 +905,82 @@ var suites = []struct {
 				input: `push(1, 1)`,
 				error: ErrorTest{"argument(s) to `push` not supported: (INTEGER, INTEGER)"},
 			},
+			{
+				input:  `int(-2.9)`,
+				object: IntegerTest(-2),
+			},
+			{
+				input:  `int(7.99d)`,
+				object: IntegerTest(7),
+			},
+			{
+				input:  `int("100000000000000000000")`,
+				object: BigIntTest("100000000000000000000"),
+			},
+			{
+				input:  `int(100000000000000000000.0)`,
+				object: BigIntTest("100000000000000000000"),
+			},
+			{
+				input: `int("twelve")`,
+				error: ErrorTest{`cannot convert "twelve" to INTEGER`},
+			},
+			{
+				input:  `float(3) / 2`,
+				object: FloatTest(1.5),
+			},
+			{
+				input:  `float(0.25d)`,
+				object: FloatTest(0.25),
+			},
+			{
+				input:  `float("2.5")`,
+				object: FloatTest(2.5),
+			},
+			{
+				input:  `decimal(0.1) + decimal("0.2")`,
+				object: DecimalTest("0.3d"),
+			},
+			{
+				input:  `decimal(5) / 4`,
+				object: DecimalTest("1.25d"),
+			},
+			{
+				input: `decimal(1.0 / 0)`,
+				error: ErrorTest{"cannot convert +Inf to DECIMAL"},
+			},
+			{
+				input: `decimal(true)`,
+				error: ErrorTest{"argument(s) to `decimal` not supported: (BOOLEAN)"},
+			},
+			{
+				input:  `str(12.50d) + " / " + str(2.0) + " / " + str(7)`,
+				object: StringTest("12.5 / 2.0 / 7"),
+			},
+			{
+				input:  `12.50d == 12.5d`,
+				object: BooleanTest(true),
+			},
+			{
+				input:  `str(1d / 3d)`,
+				object: StringTest("0.33333333333333333333"),
+			},
+			{
+				input:  `decimal(str(1d / 3d)) == 1d / 3d`,
+				object: BooleanTest(false),
+			},
+			{
+				input:  `decimal("-2.5E-2") + decimal("1e3")`,
+				object: DecimalTest("999.975d"),
+			},
+			{
+				input: `decimal("1e50000000")`,
+				error: ErrorTest{"cannot convert \"1e50000000\" to DECIMAL: exponent out of range"},
+			},
+			{
+				input: `decimal("1e-99999999999999999999")`,
+				error: ErrorTest{"cannot convert \"1e-99999999999999999999\" to DECIMAL: exponent out of range"},
+			},
 		},
 	},
 	{
@@ -744,17 +1052,27 @@ var suites = []struct {
 	},
 }
 
+type engine func(program *ast.Program) (object.Object, object.Interruption)
+
+func evaluate(program *ast.Program) (object.Object, object.Interruption) {
+	return Evaluate(program, object.NewEnvironment(nil))
+}
+
 func TestEvaluate(t *testing.T) {
+	runSuites(t, evaluate)
+}
+
+func runSuites(t *testing.T, run engine) {
 	for _, suite := range suites {
 		t.Run(suite.name, func(t *testing.T) {
 			for i, test := range suite.tests {
-				testEvaluator(t, i, test)
+				testEvaluator(t, i, test, run)
 			}
 		})
 	}
 }
 
-func testEvaluator(tb testing.TB, i int, test EvaluatorTest) {
+func testEvaluator(tb testing.TB, i int, test EvaluatorTest, run engine) {
 	p := parser.NewParser(test.input, false)
 	program := p.ParseProgram()
 
@@ -766,7 +1084,7 @@ func testEvaluator(tb testing.TB, i int, test EvaluatorTest) {
 		tb.Fatalf("test[%d] - %s", i, test.input)
 	}
 
-	value, interrupt := Evaluate(program, object.NewEnvironment(nil))
+	value, interrupt := run(program)
 	if test.error.Message == "" {
 		if interrupt != nil {
 			tb.Errorf("test[%d] - interrupt ==> expected: <%#v> but was: <%s>", i, nil, interrupt.(*object.Error).Message)
@@ -790,8 +1108,20 @@ func testObject(tb testing.TB, i int, expected ObjectTest, actual object.Object)
 		if !testFunction(tb, i, expected, actual) {
 			return false
 		}
-	case NumberTest:
-		if !testNumber(tb, i, expected, actual) {
+	case IntegerTest:
+		if !testInteger(tb, i, expected, actual) {
+			return false
+		}
+	case FloatTest:
+		if !testFloat(tb, i, expected, actual) {
+			return false
+		}
+	case BigIntTest:
+		if !testInspect(tb, i, string(expected), &object.BigInt{}, actual) {
+			return false
+		}
+	case DecimalTest:
+		if !testInspect(tb, i, string(expected), &object.Decimal{}, actual) {
 			return false
 		}
 	case BooleanTest:
@@ -839,15 +1169,44 @@ func testFunction(tb testing.TB, i int, expected FunctionTest, actual object.Obj
 	return true
 }
 
-func testNumber(tb testing.TB, i int, expected NumberTest, actual object.Object) bool {
-	value, ok := actual.(object.Number)
+func testInteger(tb testing.TB, i int, expected IntegerTest, actual object.Object) bool {
+	value, ok := actual.(object.Integer)
+	if !ok {
+		tb.Errorf("test[%d] - actual.(object.Integer) ==> unexpected type, expected: <%T> but was: <%T>", i, object.Integer(0), actual)
+		return false
+	}
+
+	if int64(expected) != int64(value) {
+		tb.Errorf("test[%d] - object.Integer ==> expected: <%d> but was: <%d>", i, int64(expected), int64(value))
+		return false
+	}
+
+	return true
+}
+
+func testFloat(tb testing.TB, i int, expected FloatTest, actual object.Object) bool {
+	value, ok := actual.(object.Float)
 	if !ok {
-		tb.Errorf("test[%d] - actual.(object.Number) ==> unexpected type, expected: <%T> but was: <%T>", i, object.Number(0.0), actual)
+		tb.Errorf("test[%d] - actual.(object.Float) ==> unexpected type, expected: <%T> but was: <%T>", i, object.Float(0.0), actual)
 		return false
 	}
 
 	if float64(expected) != float64(value) {
-		tb.Errorf("test[%d] - object.Number ==> expected: <%f> but was: <%f>", i, float64(expected), float64(value))
+		tb.Errorf("test[%d] - object.Float ==> expected: <%f> but was: <%f>", i, float64(expected), float64(value))
+		return false
+	}
+
+	return true
+}
+
+func testInspect(tb testing.TB, i int, expected string, prototype object.Object, actual object.Object) bool {
+	if actual == nil || actual.Type() != prototype.Type() {
+		tb.Errorf("test[%d] - actual ==> unexpected type, expected: <%T> but was: <%T>", i, prototype, actual)
+		return false
+	}
+
+	if expected != actual.Inspect() {
+		tb.Errorf("test[%d] - actual.Inspect() ==> expected: <%s> but was: <%s>", i, expected, actual.Inspect())
 		return false
 	}
 
@@ -975,3 +1334,332 @@ func testQuote(tb testing.TB, i int, expected QuoteTest, actual object.Object) b
 
 	return true
 }
+
+func TestEvaluateTailCalls(t *testing.T) {
+	tests := []EvaluatorTest{
+		{
+			input: `
+				let countdown = fn(n) { if (n == 0) { 0 } else { countdown(n - 1) } };
+				countdown(100000);`,
+			object: IntegerTest(0),
+		},
+		{
+			input: `
+				let sum = fn(n, acc) {
+					if (n == 0) {
+						return acc;
+					}
+					return sum(n - 1, acc + n);
+				};
+				sum(100000, 0);`,
+			object: IntegerTest(5000050000),
+		},
+		{
+			input: `
+				let even = fn(n) { if (n == 0) { true } else { odd(n - 1) } };
+				let odd = fn(n) { if (n == 0) { false } else { even(n - 1) } };
+				even(100001);`,
+			object: BooleanTest(false),
+		},
+		{
+			input: `
+				let loop = fn(n) { if (n > 0) { let m = n - 1; loop(m) } else { len("done") } };
+				loop(100000);`,
+			object: IntegerTest(4),
+		},
+		{
+			input: `
+				let f = fn(n) { n * 2 };
+				return f(21);`,
+			object: IntegerTest(42),
+		},
+		{
+			input: `
+				let f = fn(n) { if (n == 0) { return 1 + true; } f(n - 1) };
+				f(100000);`,
+			error: ErrorTest{"type mismatch: INTEGER + BOOLEAN"},
+		},
+	}
+
+	defer debug.SetMaxStack(debug.SetMaxStack(4 << 20))
+	for i, test := range tests {
+		testEvaluator(t, i, test, evaluate)
+	}
+}
+
+func TestEvaluateCallDepth(t *testing.T) {
+	tests := []struct {
+		input   string
+		limits  object.Limits
+		message string
+		frames  int
+	}{
+		{"let f = fn(n) { 1 + f(n + 1) }; f(0);", object.DefaultLimits, "maximum call depth 10000 exceeded", 10000},
+		{"let f = fn(n) { 1 + f(n + 1) }; f(0);", object.Limits{MaxDepth: 100}, "maximum call depth 100 exceeded", 100},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(100);", object.Limits{MaxDepth: 100}, "maximum call depth 100 exceeded", 100},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(99);", object.Limits{MaxDepth: 100}, "", 0},
+		{"let f = fn(n) { if (n == 0) { 0 } else { f(n - 1) } }; f(100000);", object.Limits{MaxDepth: 10}, "", 0},
+		{"let f = fn(n) { if (n == 0) { 0 } else { 1 + f(n - 1) } }; f(20000);", object.Limits{}, "", 0},
+	}
+
+	for i, test := range tests {
+		env := object.NewEnvironmentWithLimits(test.limits)
+		_, interrupt := Evaluate(parser.NewParser(test.input, false).ParseProgram(), env)
+
+		if test.message == "" {
+			if interrupt != nil {
+				t.Errorf("test[%d] - Evaluate() ==> expected: <nil> but was: <%s>", i, interrupt.Inspect())
+			}
+		} else if err, ok := interrupt.(*object.Error); !ok {
+			t.Errorf("test[%d] - Evaluate() ==> expected: <*object.Error> but was: <%T>", i, interrupt)
+		} else {
+			if test.message != err.Message {
+				t.Errorf("test[%d] - Error.Message ==> expected: <%s> but was: <%s>", i, test.message, err.Message)
+			}
+			if test.frames != len(err.Frames) {
+				t.Errorf("test[%d] - len(Error.Frames) ==> expected: <%d> but was: <%d>", i, test.frames, len(err.Frames))
+			}
+		}
+
+		if env.Runtime.Depth() != 0 {
+			t.Errorf("test[%d] - Runtime.Depth() ==> expected: <0> but was: <%d>", i, env.Runtime.Depth())
+		}
+	}
+}
+
+func TestEvaluateContextLimits(t *testing.T) {
+	tests := []struct {
+		input  string
+		limits object.Limits
+		limit  object.Limit
+	}{
+		{"let f = fn() { f() }; f();", object.Limits{MaxSteps: 1000}, object.STEP_LIMIT},
+		{"let f = fn(a) { f([a]) }; f(1);", object.Limits{MaxAllocations: 100}, object.ALLOCATION_LIMIT},
+		{"let f = fn(s) { f(s + s) }; f(\"a\");", object.Limits{MaxBytes: 1 << 16}, object.MEMORY_LIMIT},
+		{"let f = fn(s) { f(s + s) }; f(\"a\");", object.Limits{MaxStringLength: 1000}, object.STRING_LENGTH_LIMIT},
+		{"let f = fn(a) { f(push(a, 1)) }; f([]);", object.Limits{MaxArraySize: 100}, object.ARRAY_SIZE_LIMIT},
+		{"[1, 2, 3, 4]", object.Limits{MaxArraySize: 3}, object.ARRAY_SIZE_LIMIT},
+		{"let a = []; a[5000000] = 1;", object.Limits{MaxArraySize: 10}, object.ARRAY_SIZE_LIMIT},
+		{"let a = []; a[5000000] = 1;", object.Limits{MaxBytes: 1000}, object.MEMORY_LIMIT},
+		{"let h = {}; let f = fn(n) { h[n] = n; f(n + 1) }; f(0);", object.Limits{MaxBytes: 1 << 12}, object.MEMORY_LIMIT},
+	}
+
+	for i, test := range tests {
+		env := object.NewEnvironmentWithLimits(test.limits)
+		_, interrupt := EvaluateContext(context.Background(), parser.NewParser(test.input, false).ParseProgram(), env)
+
+		err, ok := interrupt.(*object.LimitError)
+		if !ok {
+			t.Errorf("test[%d] - EvaluateContext() ==> expected: <*object.LimitError> but was: <%T>", i, interrupt)
+			continue
+		}
+		if test.limit != err.Limit {
+			t.Errorf("test[%d] - LimitError.Limit ==> expected: <%s> but was: <%s>", i, test.limit, err.Limit)
+		}
+	}
+}
+
+func TestEvaluateContextBudgetPerEvaluation(t *testing.T) {
+	env := object.NewEnvironmentWithLimits(object.Limits{MaxSteps: 100})
+	program := parser.NewParser("let x = [1, 2, 3]; len(x) + 1", false).ParseProgram()
+
+	for i := 0; i < 10; i += 1 {
+		if _, interrupt := EvaluateContext(context.Background(), program, env); interrupt != nil {
+			t.Fatalf("test[%d] - EvaluateContext() ==> expected: <nil> but was: <%s>", i, interrupt.Inspect())
+		}
+	}
+}
+
+func TestEvaluateContextCancellation(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	tests := []struct {
+		ctx   context.Context
+		cause error
+	}{
+		{cancelled, context.Canceled},
+		{expired, context.DeadlineExceeded},
+	}
+
+	for i, test := range tests {
+		env := object.NewEnvironment(nil)
+		program := parser.NewParser("let f = fn(n) { f(n + 1) }; f(0);", false).ParseProgram()
+		_, interrupt := EvaluateContext(test.ctx, program, env)
+
+		err, ok := interrupt.(error)
+		if !ok {
+			t.Fatalf("test[%d] - EvaluateContext() ==> expected: <error> but was: <%T>", i, interrupt)
+		}
+
+		var limitError *object.LimitError
+		if !errors.As(err, &limitError) || limitError.Limit != object.CANCELLED {
+			t.Errorf("test[%d] - EvaluateContext() ==> expected: <%s> but was: <%s>", i, object.CANCELLED, err)
+		}
+		if !errors.Is(err, test.cause) {
+			t.Errorf("test[%d] - EvaluateContext() ==> expected: <%s> but was: <%s>", i, test.cause, err)
+		}
+		if env.Runtime.Context != nil {
+			t.Errorf("test[%d] - Runtime.Context ==> expected: <nil> but was: <%v>", i, env.Runtime.Context)
+		}
+	}
+}
+
+func TestEvaluateErrorFrames(t *testing.T) {
+	tests := []struct {
+		input    string
+		position string
+		frames   []string
+	}{
+		{"1 + true", "1:3", []string{}},
+		{"let f = fn(x) { x + 1 };\nf(true);", "1:19", []string{"f at 2:1"}},
+		{"let f = fn(x) { len(x) };\nlet g = fn(x) { 1 + f(x) };\ng(1);", "1:17", []string{"g at 3:1", "f at 2:21"}},
+		{"let f = fn(n) { if (n == 0) { -true } else { f(n - 1) } };\nf(3);", "1:31", []string{"f at 1:46"}},
+		{"let apply = fn(g) { 1 + g() };\napply(fn() { 1 + true });", "2:16", []string{"apply at 2:1", "<anonymous> at 1:25"}},
+	}
+
+	for i, test := range tests {
+		_, interrupt := evaluate(parser.NewParser(test.input, false).ParseProgram())
+		err, ok := interrupt.(*object.Error)
+		if !ok {
+			t.Fatalf("test[%d] - Evaluate() ==> expected: <*object.Error> but was: <%T>", i, interrupt)
+		}
+
+		if test.position != err.Position.String() {
+			t.Errorf("test[%d] - Error.Position ==> expected: <%s> but was: <%s>", i, test.position, err.Position)
+		}
+
+		frames := []string{}
+		for _, frame := range err.Frames {
+			frames = append(frames, frame.Name()+" at "+frame.Position.String())
+		}
+		if !slices.Equal(test.frames, frames) {
+			t.Errorf("test[%d] - Error.Frames ==> expected: <%v> but was: <%v>", i, test.frames, frames)
+		}
+	}
+}
+
+func TestEvaluateConcurrency(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let square = fn(x) { x * x }; let tasks = [spawn(square, 3), spawn(square, 4)]; await(tasks[0]) + await(tasks[1])", "25"},
+		{"let t = spawn(len, [1, 2]); [await(t), await(t)]", "[2, 2]"},
+		{`
+let ch = channel();
+let produce = fn(n) { if (n == 0) { close(ch) } else { send(ch, n); produce(n - 1) } };
+spawn(produce, 3);
+let drain = fn(acc) { let v = recv(ch); if (!v) { acc } else { drain(push(acc, v)) } };
+drain([])`, "[3, 2, 1]"},
+		{"let ch = channel(2); send(ch, 1); send(ch, 2); close(ch); [recv(ch), recv(ch), recv(ch)]", "[1, 2, null]"},
+		{"let ch = channel(1); [select([ch, [ch, 5]]), select([ch]), select([ch], false)]", "[[1, null], [0, 5], [-1, null]]"},
+		{`
+let requests = channel();
+let replies = channel();
+spawn(fn() { send(replies, recv(requests) * 2) });
+select([[requests, 21]]);
+recv(replies)`, "42"},
+		{"let a = [1, 2]; let t = spawn(fn(a) { a[0] = 99; a }, a); [await(t), a]", "[[99, 2], [1, 2]]"},
+		{"let h = {\"n\": 1}; let t = spawn(fn() { h[\"n\"] = 2; h[\"n\"] }); [await(t), h[\"n\"]]", "[2, 1]"},
+		{"let ch = channel(1); let a = [1]; send(ch, a); a[0] = 2; [recv(ch), a]", "[[1], [2]]"},
+		{"recv(channel())", "ERROR: deadlock: all tasks are blocked"},
+		{"let a = channel(); let b = channel(); spawn(fn() { recv(a); send(b, 1) }); recv(b)", "ERROR: deadlock: all tasks are blocked"},
+		{"let t = spawn(fn() { recv(channel()) }); await(t)", "ERROR: deadlock: all tasks are blocked"},
+		{"await(spawn(fn() { 1 + true }))", "ERROR: task 1 failed: type mismatch: INTEGER + BOOLEAN"},
+		{"let ch = channel(); close(ch); send(ch, 1)", "ERROR: send on closed channel"},
+		{"let ch = channel(); close(ch); close(ch)", "ERROR: close of closed channel"},
+		{"select([1])", "ERROR: `select` case 0 is not a channel or [channel, value]"},
+		{"spawn(1)", "ERROR: argument(s) to `spawn` not supported: (INTEGER)"},
+	}
+
+	for i, test := range tests {
+		env := object.NewEnvironment(nil)
+		value, interrupt := EvaluateContext(context.Background(), parser.NewParser(test.input, false).ParseProgram(), env)
+		actual := ""
+		if interrupt != nil {
+			actual = interrupt.Inspect()
+		} else {
+			actual = value.Inspect()
+		}
+		if test.expected != actual {
+			t.Errorf("test[%d] - EvaluateContext() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+}
+
+func TestEvaluateConcurrencyCancellation(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	env := object.NewEnvironment(nil)
+	program := parser.NewParser("let ch = channel(); spawn(fn() { let f = fn() { f() }; f() }); recv(ch)", false).ParseProgram()
+	_, interrupt := EvaluateContext(ctx, program, env)
+	if err, ok := interrupt.(error); !ok || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("EvaluateContext() ==> expected: <%s> but was: <%v>", context.DeadlineExceeded, interrupt)
+	}
+}
+
+func TestEvaluateGenerators(t *testing.T) {
+	prelude := `
+let count = fn(i, n) { if (i < n) { yield i; count(i + 1, n) } };
+let from = fn(i) { yield i; from(i + 1) };
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let g = count(0, 2); [next(g), next(g), next(g), next(g)]", "[0, 1, null, null]"},
+		{"take(from(5), 3)", "[5, 6, 7]"},
+		{"take(count(0, 100000), 100000)[99999]", "99999"},
+		{"let g = from(0); [take(g, 2), take(g, 2)]", "[[0, 1], [2, 3]]"},
+		{"take(map(from(1), fn(x) { x * x }), 4)", "[1, 4, 9, 16]"},
+		{"take(filter(from(1), fn(x) { x % 3 == 0 }), 3)", "[3, 6, 9]"},
+		{"map([1, 2, 3], fn(x) { x * 2 })", "[2, 4, 6]"},
+		{"filter([1, 2, 3, 4], fn(x) { x % 2 == 0 })", "[2, 4]"},
+		{"take([1, 2, 3], 5)", "[1, 2, 3]"},
+		{"let g = fn() { let a = yield 1; yield a; return 3; yield 4 }; take(g(), 10)", "[1, null]"},
+		{"let g = fn() { yield 1; [7, 8] }; take(g(), 10)", "[1]"},
+		{"let g = fn() { yield 0; map(count(1, 3), fn(x) { x * 10 }) }; take(g(), 10)", "[0, 10, 20]"},
+		{"let ch = channel(2); send(ch, 1); send(ch, 2); close(ch); take(map(ch, fn(x) { x + 1 }), 5)", "[2, 3]"},
+		{"let g = fn() { yield 1; 1 + true }; let it = g(); [next(it), next(it)]", "ERROR: type mismatch: INTEGER + BOOLEAN"},
+		{"let g = fn() { yield next(it) }; let it = g(); next(it)", "ERROR: generator is already running"},
+		{"let g = fn() { yield 1 }; let it = g(); await(spawn(fn() { next(it) }))", "ERROR: task 1 failed: generator belongs to another task"},
+		{"let ch = channel(1); send(ch, 1); let it = map(ch, fn(x) { x }); await(spawn(fn() { next(it) }))", "ERROR: task 1 failed: iterator belongs to another task"},
+		{"yield 1", "ERROR: yield outside a generator"},
+		{"take(from(0), -1)", "ERROR: argument(s) to `take` not supported: (ITERATOR, INTEGER)"},
+	}
+
+	for i, test := range tests {
+		env := object.NewEnvironment(nil)
+		value, interrupt := EvaluateContext(context.Background(), parser.NewParser(prelude+test.input, false).ParseProgram(), env)
+		actual := ""
+		if interrupt != nil {
+			actual = interrupt.Inspect()
+		} else {
+			actual = value.Inspect()
+		}
+		if test.expected != actual {
+			t.Errorf("test[%d] - EvaluateContext() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+}
+
+func TestEvaluateGeneratorsAbandoned(t *testing.T) {
+	before := runtime.NumGoroutine()
+	env := object.NewEnvironment(nil)
+	program := parser.NewParser("let from = fn(i) { yield i; from(i + 1) }; let f = fn(n) { if (n > 0) { next(from(n)); f(n - 1) } }; f(100)", false).ParseProgram()
+	if _, interrupt := EvaluateContext(context.Background(), program, env); interrupt != nil {
+		t.Fatalf("EvaluateContext() ==> %s", interrupt.Inspect())
+	}
+
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i += 1 {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("runtime.NumGoroutine() ==> expected: <%d> but was: <%d>", before, after)
+	}
+}
@@ -0,0 +1,12 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+)
+
+func RunSuites(t *testing.T, run func(program *ast.Program) (object.Object, object.Interruption)) {
+	runSuites(t, run)
+}
@@ -0,0 +1,255 @@
+package evaluator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+// Loader imports modules from the directories of its search path, in
+// order. Each module is parsed and has its macros defined once, and is
+// evaluated once in an environment of its own the first time it is
+// imported; later imports of the same file, by its canonical path, share
+// the same namespace.
+//
+// A Loader is safe for concurrent use: the tasks of an evaluation share it
+// and take turns to import. A module that waits for tasks of its own while
+// it is evaluated must not have them import. Tasks that import a module
+// share its namespace, unlike one handed to them, which is copied, so they
+// must not mutate what it exports.
+type Loader struct {
+	Path []string
+
+	mu      sync.Mutex
+	modules map[string]*module
+	loading []*module
+
+	// running holds the runtimes modules are being evaluated on by the
+	// goroutine that holds mu, which import without locking it again.
+	runningMu sync.Mutex
+	running   map[*object.Runtime]bool
+}
+
+type module struct {
+	name    string
+	program ast.Node
+	macros  *object.Environment
+
+	namespace *object.Module
+	evaluated bool
+	failure   object.Interruption
+}
+
+func NewLoader(path ...string) *Loader {
+	return &Loader{Path: path, modules: map[string]*module{}, running: map[*object.Runtime]bool{}}
+}
+
+// SearchPath splits a list of directories joined by the separator of the
+// platform, as in $PATH, dropping empty entries.
+func SearchPath(list string) []string {
+	dirs := []string{}
+	for _, dir := range filepath.SplitList(list) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func (l *Loader) ImportMacros(name string) (*object.Module, object.Interruption) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.importMacros(name)
+}
+
+func (l *Loader) Import(name string, rt *object.Runtime) (*object.Module, object.Interruption) {
+	if !l.runs(rt) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+	}
+	return l.load(name, rt)
+}
+
+func (l *Loader) importMacros(name string) (*object.Module, object.Interruption) {
+	m, err := l.define(name)
+	if err != nil {
+		return nil, err
+	}
+	return m.namespace, nil
+}
+
+func (l *Loader) load(name string, rt *object.Runtime) (*object.Module, object.Interruption) {
+	m, err := l.define(name)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, loading := range l.loading {
+		if loading == m {
+			names := []string{}
+			for _, loading := range l.loading[i:] {
+				names = append(names, loading.name)
+			}
+			return nil, toError("import cycle: %s -> %s", strings.Join(names, " -> "), name)
+		}
+	}
+
+	if !m.evaluated && m.failure == nil {
+		l.loading = append(l.loading, m)
+		l.evaluate(m, rt)
+		l.loading = l.loading[:len(l.loading)-1]
+	}
+
+	switch failure := m.failure.(type) {
+	case nil:
+		return m.namespace, nil
+	case *object.Error:
+		// Every import raises the error afresh, at its own statement.
+		return nil, &object.Error{Message: failure.Message}
+	default:
+		return nil, failure
+	}
+}
+
+// define parses the module imported as name and defines its macros, unless
+// it has been already.
+func (l *Loader) define(name string) (*module, *object.Error) {
+	path, err := l.resolve(name)
+	if err != nil {
+		return nil, err
+	}
+	if m, found := l.modules[path]; found {
+		return m, nil
+	}
+
+	m := &module{name: name, namespace: &object.Module{Path: name, Members: map[string]object.Object{}}}
+	l.modules[path] = m
+
+	src, readErr := os.ReadFile(path)
+	if readErr != nil {
+		m.failure = toError("%s", readErr)
+		return m, nil
+	}
+
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 0 {
+		m.failure = toError("%s: %s", name, errs[0])
+		return m, nil
+	}
+	if errs := Check(program); len(errs) != 0 {
+		m.failure = toError("%s:%s: %s", name, errs[0].Position, errs[0].Message)
+		return m, nil
+	}
+
+	m.macros = object.NewEnvironment(nil)
+	m.macros.Runtime.Importer = locked{l}
+	expanded, interrupt := ExpandMacros(DefineMacros(program, m.macros), m.macros)
+	if interrupt != nil {
+		if err, ok := interrupt.(*object.Error); ok {
+			interrupt = toError("%s:%s: %s", name, err.Position, err.Message)
+		}
+		m.failure = interrupt
+		return m, nil
+	}
+	m.program = expanded
+	for _, stmt := range program.Statements {
+		if macro, ok := stmt.(*ast.MacroStatement); ok && macro.Exported {
+			m.namespace.Members[macro.Name.Value], _ = m.macros.Get(macro.Name.Value)
+		}
+	}
+	return m, nil
+}
+
+// evaluate runs the program of m with the limits and context of rt, and
+// adds the values it exports to its namespace.
+func (l *Loader) evaluate(m *module, rt *object.Runtime) {
+	env := object.NewEnvironmentWithLimits(rt.Limits)
+	env.Runtime.Importer = l
+	l.setRunning(env.Runtime, true)
+	defer l.setRunning(env.Runtime, false)
+
+	ctx := rt.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if _, interrupt := EvaluateContext(ctx, m.program, env); interrupt != nil {
+		if err, ok := interrupt.(*object.Error); ok {
+			interrupt = toError("%s:%s: %s", m.name, err.Position, err.Message)
+		}
+		m.failure = interrupt
+		return
+	}
+
+	if program, ok := m.program.(*ast.Program); ok {
+		for _, stmt := range program.Statements {
+			if let, ok := stmt.(*ast.LetDeclaration); ok && let.Exported {
+				m.namespace.Members[let.Name.Value], _ = env.Get(let.Name.Value)
+			}
+		}
+	}
+	m.evaluated = true
+}
+
+func (l *Loader) runs(rt *object.Runtime) bool {
+	l.runningMu.Lock()
+	defer l.runningMu.Unlock()
+	return l.running[rt]
+}
+
+func (l *Loader) setRunning(rt *object.Runtime, running bool) {
+	l.runningMu.Lock()
+	defer l.runningMu.Unlock()
+	if running {
+		l.running[rt] = true
+	} else {
+		delete(l.running, rt)
+	}
+}
+
+// locked is the importer of the macros of modules, which are expanded by
+// the goroutine that holds the lock of the Loader.
+type locked struct {
+	l *Loader
+}
+
+func (i locked) ImportMacros(name string) (*object.Module, object.Interruption) {
+	return i.l.importMacros(name)
+}
+
+func (i locked) Import(name string, rt *object.Runtime) (*object.Module, object.Interruption) {
+	return i.l.load(name, rt)
+}
+
+// resolve finds the file imported as name in the search path and returns
+// its canonical path.
+func (l *Loader) resolve(name string) (string, *object.Error) {
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = candidates[:0]
+		for _, dir := range l.Path {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err != nil || info.IsDir() {
+			continue
+		}
+		path, err := filepath.Abs(candidate)
+		if err == nil {
+			path, err = filepath.EvalSymlinks(path)
+		}
+		if err != nil {
+			return "", toError("%s", err)
+		}
+		return path, nil
+	}
+	return "", toError("module not found: %q (search path: %s)", name, strings.Join(l.Path, string(filepath.ListSeparator)))
+}
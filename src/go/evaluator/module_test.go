@@ -0,0 +1,106 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestEvaluateModules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"lib/util.mk": `export let bang = fn(s) { s + "!" };`,
+		"lib/strings.mk": `
+import "lib/util.mk" as u;
+let helper = fn(s) { u["bang"](s) };
+export let shout = fn(s) { helper(s) };
+export macro unless(cond, then) { quote(if (!(unquote(cond))) { unquote(then) }) };`,
+		"state.mk":         `export let items = [0];`,
+		"shadow.mk":        `export let where = "dir";`,
+		"first/shadow.mk":  `export let where = "first";`,
+		"a.mk":             `import "b.mk" as b; export let x = 1;`,
+		"b.mk":             `import "a.mk" as a; export let y = 2;`,
+		"bad.mk":           `let = 1;`,
+		"fail.mk":          `export let x = 1; 1 + true;`,
+		"unknown.mk":       `export let f = fn() { nope };`,
+		"lib/directory.mk": "",
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(src), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink(filepath.Join(dir, "state.mk"), filepath.Join(dir, "link.mk")); err != nil {
+		t.Fatal(err)
+	}
+	search := []string{filepath.Join(dir, "first"), dir}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "lib/strings.mk" as s; s["shout"]("hi")`, `"hi!"`},
+		{`import "lib/strings.mk" as s; s`, `<module "lib/strings.mk">`},
+		{`import "lib/strings.mk" as s; s["unless"](false, 5)`, "5"},
+		{`import "lib/strings.mk" as s; s["helper"]`, `ERROR: module "lib/strings.mk" does not export helper`},
+		{`import "lib/strings.mk" as s; s[0]`, "ERROR: unknown operator: MODULE[INTEGER]"},
+		{`import "state.mk" as a; import "link.mk" as b; a["items"][0] = 7; b["items"]`, "[7]"},
+		{`import "shadow.mk" as s; s["where"]`, `"first"`},
+		{fmt.Sprintf(`import %q as s; s["where"]`, filepath.Join(dir, "shadow.mk")), `"dir"`},
+		{`import "a.mk" as a; a["x"]`, "ERROR: a.mk:1:1: b.mk:1:1: import cycle: a.mk -> b.mk -> a.mk"},
+		{`import "missing.mk" as m`, fmt.Sprintf(`ERROR: module not found: "missing.mk" (search path: %s%c%s)`, search[0], filepath.ListSeparator, search[1])},
+		{`import "lib" as m`, fmt.Sprintf(`ERROR: module not found: "lib" (search path: %s%c%s)`, search[0], filepath.ListSeparator, search[1])},
+		{`import "bad.mk" as m`, "ERROR: bad.mk: expected next token to be <IDENT> but was <ASSIGN>"},
+		{`import "fail.mk" as m`, "ERROR: fail.mk:1:21: type mismatch: INTEGER + BOOLEAN"},
+		{`import "unknown.mk" as m`, "ERROR: unknown.mk:1:23: unknown identifier: nope"},
+		{`await(spawn(fn() { import "lib/strings.mk" as s; s["shout"]("hi") }))`, `"hi!"`},
+		{`import "state.mk" as s; let t = spawn(fn() { s["items"][0] = 5; s["items"] }); [await(t), s["items"]]`, "[[5], [0]]"},
+		{`let f = fn() { import "lib/strings.mk" as s; s["shout"]("hi") }; let ts = [spawn(f), spawn(f), spawn(f)]; [await(ts[0]), await(ts[1]), await(ts[2])]`, `["hi!", "hi!", "hi!"]`},
+	}
+
+	for i, test := range tests {
+		actual := evaluateModule(NewLoader(search...), test.input)
+		if test.expected != actual {
+			t.Errorf("test[%d] - EvaluateContext() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+
+	loader := NewLoader(search...)
+	evaluateModule(loader, `import "state.mk" as s; s["items"][0] = 1`)
+	if actual := evaluateModule(loader, `import "link.mk" as s; s["items"]`); actual != "[1]" {
+		t.Errorf("EvaluateContext() ==> expected: <%s> but was: <%s>", "[1]", actual)
+	}
+	if actual := evaluateModule(nil, `import "state.mk" as s`); actual != `ERROR: cannot import "state.mk": no module search path is set` {
+		t.Errorf("EvaluateContext() ==> expected: <%s> but was: <%s>", `ERROR: cannot import "state.mk": no module search path is set`, actual)
+	}
+}
+
+// evaluateModule runs input as a script that imports from loader, expanding
+// its macros first.
+func evaluateModule(loader *Loader, input string) string {
+	macros := object.NewEnvironment(nil)
+	env := object.NewEnvironment(nil)
+	if loader != nil {
+		macros.Runtime.Importer = loader
+		env.Runtime.Importer = loader
+	}
+
+	program, interrupt := ExpandMacros(DefineMacros(parser.NewParser(input, false).ParseProgram(), macros), macros)
+	if interrupt != nil {
+		return interrupt.Inspect()
+	}
+	value, interrupt := EvaluateContext(context.Background(), program, env)
+	if interrupt != nil {
+		return interrupt.Inspect()
+	}
+	return value.Inspect()
+}
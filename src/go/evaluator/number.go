@@ -0,0 +1,339 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+)
+
+// Numbers promote along two lines: integers widen to big integers when a
+// result does not fit in 64 bits, and mix with decimals exactly; floats
+// absorb integers but never decimals, whose exactness they would lose.
+func numberBinary(operator string, left, right object.Object) (object.Object, object.Interruption, bool) {
+	switch {
+	case left.Type() == object.INTEGER && right.Type() == object.INTEGER:
+		return integerBinary(operator, left.(object.Integer), right.(object.Integer))
+	case left.Type() == object.FLOAT || right.Type() == object.FLOAT:
+		if left.Type() == object.DECIMAL || right.Type() == object.DECIMAL {
+			return nil, toError("type mismatch: %s %s %s", left.Type(), operator, right.Type()), true
+		}
+		result, ok := floatBinary(operator, toFloat(left), toFloat(right))
+		return result, nil, ok
+	case left.Type() == object.DECIMAL || right.Type() == object.DECIMAL:
+		return decimalBinary(operator, toRat(left), toRat(right))
+	default:
+		return bigBinary(operator, toBig(left), toBig(right))
+	}
+}
+
+// integerBinary keeps integer arithmetic exact: division truncates towards
+// zero and a result that does not fit in 64 bits is redone with big
+// integers.
+func integerBinary(operator string, left, right object.Integer) (object.Object, object.Interruption, bool) {
+	var result object.Integer
+	overflow := false
+	switch operator {
+	case "+":
+		result = left + right
+		overflow = (right > 0 && result < left) || (right < 0 && result > left)
+	case "-":
+		result = left - right
+		overflow = (right > 0 && result > left) || (right < 0 && result < left)
+	case "*":
+		result = left * right
+		overflow = left != 0 && (result/left != right || (left == -1 && right == math.MinInt64))
+	case "/":
+		if right == 0 {
+			return nil, divisionByZero(left, operator, right), true
+		}
+		result = left / right
+		overflow = left == math.MinInt64 && right == -1
+	case "%":
+		if right == 0 {
+			return nil, divisionByZero(left, operator, right), true
+		}
+		result = left % right
+	case "<":
+		return toBoolean(left < right), nil, true
+	case ">":
+		return toBoolean(left > right), nil, true
+	case "==":
+		return toBoolean(left == right), nil, true
+	case "!=":
+		return toBoolean(left != right), nil, true
+	default:
+		return nil, nil, false
+	}
+
+	if overflow {
+		return bigBinary(operator, big.NewInt(int64(left)), big.NewInt(int64(right)))
+	}
+	return result, nil, true
+}
+
+func bigBinary(operator string, left, right *big.Int) (object.Object, object.Interruption, bool) {
+	result := new(big.Int)
+	switch operator {
+	case "+":
+		result.Add(left, right)
+	case "-":
+		result.Sub(left, right)
+	case "*":
+		result.Mul(left, right)
+	case "/":
+		if right.Sign() == 0 {
+			return nil, divisionByZero(object.NewInteger(left), operator, object.NewInteger(right)), true
+		}
+		result.Quo(left, right)
+	case "%":
+		if right.Sign() == 0 {
+			return nil, divisionByZero(object.NewInteger(left), operator, object.NewInteger(right)), true
+		}
+		result.Rem(left, right)
+	case "<":
+		return toBoolean(left.Cmp(right) < 0), nil, true
+	case ">":
+		return toBoolean(left.Cmp(right) > 0), nil, true
+	case "==":
+		return toBoolean(left.Cmp(right) == 0), nil, true
+	case "!=":
+		return toBoolean(left.Cmp(right) != 0), nil, true
+	default:
+		return nil, nil, false
+	}
+	return object.NewInteger(result), nil, true
+}
+
+func decimalBinary(operator string, left, right *big.Rat) (object.Object, object.Interruption, bool) {
+	result := new(big.Rat)
+	switch operator {
+	case "+":
+		result.Add(left, right)
+	case "-":
+		result.Sub(left, right)
+	case "*":
+		result.Mul(left, right)
+	case "/":
+		if right.Sign() == 0 {
+			return nil, divisionByZero(&object.Decimal{Value: left}, operator, &object.Decimal{Value: right}), true
+		}
+		result.Quo(left, right)
+	case "%":
+		if right.Sign() == 0 {
+			return nil, divisionByZero(&object.Decimal{Value: left}, operator, &object.Decimal{Value: right}), true
+		}
+		// the remainder keeps the sign of left, as it does for integers
+		quotient := new(big.Rat).Quo(left, right)
+		truncated := new(big.Int).Quo(quotient.Num(), quotient.Denom())
+		result.Sub(left, new(big.Rat).Mul(right, new(big.Rat).SetInt(truncated)))
+	case "<":
+		return toBoolean(left.Cmp(right) < 0), nil, true
+	case ">":
+		return toBoolean(left.Cmp(right) > 0), nil, true
+	case "==":
+		return toBoolean(left.Cmp(right) == 0), nil, true
+	case "!=":
+		return toBoolean(left.Cmp(right) != 0), nil, true
+	default:
+		return nil, nil, false
+	}
+	return &object.Decimal{Value: result}, nil, true
+}
+
+func floatBinary(operator string, left, right object.Float) (object.Object, bool) {
+	switch operator {
+	case "+":
+		return left + right, true
+	case "-":
+		return left - right, true
+	case "*":
+		return left * right, true
+	case "/":
+		return left / right, true
+	case "%":
+		return object.Float(math.Mod(float64(left), float64(right))), true
+	case "<":
+		return toBoolean(left < right), true
+	case ">":
+		return toBoolean(left > right), true
+	case "==":
+		return toBoolean(left == right), true
+	case "!=":
+		return toBoolean(left != right), true
+	}
+	return nil, false
+}
+
+func negate(o object.Object) object.Object {
+	switch o := o.(type) {
+	case object.Integer:
+		if o == math.MinInt64 {
+			return object.NewInteger(new(big.Int).Neg(big.NewInt(int64(o))))
+		}
+		return -o
+	case *object.BigInt:
+		return object.NewInteger(new(big.Int).Neg(o.Value))
+	case object.Float:
+		return -o
+	case *object.Decimal:
+		return &object.Decimal{Value: new(big.Rat).Neg(o.Value)}
+	}
+	panic(fmt.Errorf("unexpected number type: %T", o))
+}
+
+func divisionByZero(left object.Object, operator string, right object.Object) *object.Error {
+	return toError("division by zero: %s %s %s", left.Inspect(), operator, right.Inspect())
+}
+
+func isNumber(o object.Object) bool {
+	switch o.Type() {
+	case object.INTEGER, object.BIG_INTEGER, object.FLOAT, object.DECIMAL:
+		return true
+	}
+	return false
+}
+
+func integerLiteral(node *ast.IntegerLiteral) object.Object {
+	if node.Big != nil {
+		return &object.BigInt{Value: node.Big}
+	}
+	return object.Integer(node.Value)
+}
+
+func toFloat(o object.Object) object.Float {
+	switch o := o.(type) {
+	case object.Integer:
+		return object.Float(o)
+	case *object.BigInt:
+		value, _ := new(big.Float).SetInt(o.Value).Float64()
+		return object.Float(value)
+	case object.Float:
+		return o
+	case *object.Decimal:
+		value, _ := o.Value.Float64()
+		return object.Float(value)
+	}
+	panic(fmt.Errorf("unexpected number type: %T", o))
+}
+
+func toBig(o object.Object) *big.Int {
+	switch o := o.(type) {
+	case object.Integer:
+		return big.NewInt(int64(o))
+	case *object.BigInt:
+		return o.Value
+	}
+	panic(fmt.Errorf("unexpected integer type: %T", o))
+}
+
+func toRat(o object.Object) *big.Rat {
+	switch o := o.(type) {
+	case object.Integer:
+		return new(big.Rat).SetInt64(int64(o))
+	case *object.BigInt:
+		return new(big.Rat).SetInt(o.Value)
+	case *object.Decimal:
+		return o.Value
+	}
+	panic(fmt.Errorf("unexpected number type: %T", o))
+}
+
+// convertInteger truncates numbers towards zero and parses strings in base
+// 10.
+func convertInteger(o object.Object) (object.Object, *object.Error) {
+	switch o := o.(type) {
+	case object.Integer, *object.BigInt:
+		return o, nil
+	case object.Float:
+		if math.IsInf(float64(o), 0) || math.IsNaN(float64(o)) {
+			return nil, toError("cannot convert %s to INTEGER", o.Inspect())
+		}
+		value, _ := big.NewFloat(float64(o)).Int(nil)
+		return object.NewInteger(value), nil
+	case *object.Decimal:
+		return object.NewInteger(new(big.Int).Quo(o.Value.Num(), o.Value.Denom())), nil
+	case object.String:
+		value, ok := new(big.Int).SetString(strings.TrimSpace(string(o)), 10)
+		if !ok {
+			return nil, toError("cannot convert %s to INTEGER", o.Inspect())
+		}
+		return object.NewInteger(value), nil
+	}
+	return nil, toBuiltinError("int", []object.Object{o})
+}
+
+func convertFloat(o object.Object) (object.Object, *object.Error) {
+	switch o := o.(type) {
+	case object.Integer, *object.BigInt, object.Float, *object.Decimal:
+		return toFloat(o), nil
+	case object.String:
+		value, err := strconv.ParseFloat(strings.TrimSpace(string(o)), 64)
+		if err != nil {
+			return nil, toError("cannot convert %s to FLOAT", o.Inspect())
+		}
+		return object.Float(value), nil
+	}
+	return nil, toBuiltinError("float", []object.Object{o})
+}
+
+// maxDecimalExponent bounds the exponent of a string given to decimal, as
+// its value is expanded in full: 1e50000000 would be a 50 million digit
+// integer.
+const maxDecimalExponent = 10000
+
+// convertDecimal takes floats at their shortest decimal representation, so
+// decimal(0.1) is 0.1d rather than the binary fraction nearest to it.
+func convertDecimal(o object.Object) (object.Object, *object.Error) {
+	var text string
+	switch o := o.(type) {
+	case object.Integer, *object.BigInt:
+		return &object.Decimal{Value: new(big.Rat).Set(toRat(o))}, nil
+	case *object.Decimal:
+		return o, nil
+	case object.Float:
+		if math.IsInf(float64(o), 0) || math.IsNaN(float64(o)) {
+			return nil, toError("cannot convert %s to DECIMAL", o.Inspect())
+		}
+		text = strconv.FormatFloat(float64(o), 'g', -1, 64)
+	case object.String:
+		text = strings.TrimSuffix(strings.TrimSpace(string(o)), "d")
+		if !validExponent(text) {
+			return nil, toError("cannot convert %s to DECIMAL: exponent out of range", o.Inspect())
+		}
+	default:
+		return nil, toBuiltinError("decimal", []object.Object{o})
+	}
+
+	value, ok := new(big.Rat).SetString(text)
+	if !ok {
+		return nil, toError("cannot convert %s to DECIMAL", o.Inspect())
+	}
+	return &object.Decimal{Value: value}, nil
+}
+
+// validExponent reports whether the exponent of text, if it has one, is
+// within maxDecimalExponent. An exponent that is not a number is left for
+// big.Rat to reject.
+func validExponent(text string) bool {
+	mantissa := strings.TrimLeft(text, "+-")
+	markers := "eEpP"
+	if strings.HasPrefix(mantissa, "0x") || strings.HasPrefix(mantissa, "0X") {
+		markers = "pP"
+	}
+
+	i := strings.LastIndexAny(text, markers)
+	if i < 0 {
+		return true
+	}
+	exponent, err := strconv.ParseInt(text[i+1:], 10, 64)
+	if err != nil {
+		return !errors.Is(err, strconv.ErrRange)
+	}
+	return -maxDecimalExponent <= exponent && exponent <= maxDecimalExponent
+}
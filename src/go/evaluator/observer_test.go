@@ -0,0 +1,210 @@
+package evaluator_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+type recorder struct {
+	events []string
+	depth  int
+}
+
+func (r *recorder) Enter(node ast.Node, env *object.Environment) {
+	r.depth += 1
+}
+
+func (r *recorder) Exit(node ast.Node, env *object.Environment, value object.Object, interrupt object.Interruption) {
+	r.depth -= 1
+}
+
+func (r *recorder) Call(fn *object.Function, args []object.Object, position token.Position, env *object.Environment) {
+	r.events = append(r.events, fmt.Sprintf("call %s%s @%d:%d", object.Frame{Function: fn}.Name(), inspect(args), position.Line, position.Column))
+}
+
+func (r *recorder) Return(fn *object.Function, value object.Object, interrupt object.Interruption) {
+	switch {
+	case interrupt != nil:
+		r.events = append(r.events, fmt.Sprintf("return %s %s", object.Frame{Function: fn}.Name(), interrupt.Type()))
+	default:
+		r.events = append(r.events, fmt.Sprintf("return %s %s", object.Frame{Function: fn}.Name(), value.Inspect()))
+	}
+}
+
+func (r *recorder) CallBuiltin(name string, args []object.Object, position token.Position, env *object.Environment) {
+	r.events = append(r.events, fmt.Sprintf("builtin %s%s @%d:%d", name, inspect(args), position.Line, position.Column))
+}
+
+func (r *recorder) Raise(err *object.Error, env *object.Environment) {
+	r.events = append(r.events, fmt.Sprintf("raise %s @%d:%d", err.Message, err.Position.Line, err.Position.Column))
+}
+
+func inspect(args []object.Object) string {
+	values := []string{}
+	for _, arg := range args {
+		values = append(values, arg.Inspect())
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+func TestObserver(t *testing.T) {
+	evaluator.RunSuites(t, func(program *ast.Program) (object.Object, object.Interruption) {
+		env := object.NewEnvironment(nil)
+		r := &recorder{}
+		env.Runtime.Observer = r
+		value, interrupt := evaluator.Evaluate(program, env)
+		if r.depth != 0 {
+			t.Errorf("depth ==> expected: <0> but was: <%d>", r.depth)
+		}
+		return value, interrupt
+	})
+}
+
+func TestObserverEvents(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"let add = fn(a, b) { a + b };\nadd(1, len(\"ab\"));",
+			[]string{
+				`builtin len("ab") @2:8`,
+				"call add(1, 2) @2:1",
+				"return add 3",
+			},
+		},
+		{
+			"let count = fn(n) { if (n == 0) { 0 } else { count(n - 1) } };\ncount(2);",
+			[]string{
+				"call count(2) @2:1",
+				"return count TAIL_CALL",
+				"call count(1) @1:46",
+				"return count TAIL_CALL",
+				"call count(0) @1:46",
+				"return count 0",
+			},
+		},
+		{
+			"let f = fn(x) { return x * 2; 0 };\nf(f(1));",
+			[]string{
+				"call f(1) @2:3",
+				"return f 2",
+				"call f(2) @2:1",
+				"return f 4",
+			},
+		},
+		{
+			"let f = fn(x) { x + true };\nf(1);",
+			[]string{
+				"call f(1) @2:1",
+				"raise type mismatch: INTEGER + BOOLEAN @1:19",
+				"return f ERROR",
+			},
+		},
+		{
+			"let f = fn() { first(1) };\n1 + f();",
+			[]string{
+				"call f() @2:5",
+				"builtin first(1) @1:16",
+				"raise argument(s) to `first` not supported: (INTEGER) @1:16",
+				"return f ERROR",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		p := parser.NewParser(test.input, false)
+		program := p.ParseProgram()
+		if len(p.Errors()) != 0 {
+			t.Fatalf("test[%d] - p.Errors() ==> %v", i, p.Errors())
+		}
+
+		env := object.NewEnvironment(nil)
+		r := &recorder{}
+		env.Runtime.Observer = r
+		evaluator.Evaluate(program, env)
+
+		if strings.Join(test.expected, "\n") != strings.Join(r.events, "\n") {
+			t.Errorf("test[%d] - events ==> expected: <\n%s\n> but was: <\n%s\n>", i, strings.Join(test.expected, "\n"), strings.Join(r.events, "\n"))
+		}
+		if r.depth != 0 {
+			t.Errorf("test[%d] - depth ==> expected: <0> but was: <%d>", i, r.depth)
+		}
+	}
+}
+
+func TestObserverTasks(t *testing.T) {
+	p := parser.NewParser("let f = fn(x) { x * 2 };\nlet tasks = [spawn(f, 1), spawn(f, 2)];\nawait(tasks[0]) + await(tasks[1]);", false)
+	program := p.ParseProgram()
+
+	env := object.NewEnvironment(nil)
+	r := &recorder{}
+	env.Runtime.Observer = r
+	value, interrupt := evaluator.Evaluate(program, env)
+	if interrupt != nil || value.Inspect() != "6" {
+		t.Fatalf("Evaluate() ==> expected: <6> but was: <%v %v>", value, interrupt)
+	}
+
+	returns := []string{}
+	for _, event := range r.events {
+		if strings.HasPrefix(event, "return f ") {
+			returns = append(returns, event)
+		}
+	}
+	slices.Sort(returns)
+	if expected := "return f 2, return f 4"; expected != strings.Join(returns, ", ") {
+		t.Errorf("returns ==> expected: <%s> but was: <%s>", expected, strings.Join(returns, ", "))
+	}
+	if r.depth != 0 {
+		t.Errorf("depth ==> expected: <0> but was: <%d>", r.depth)
+	}
+}
+
+func TestObserverNodes(t *testing.T) {
+	p := parser.NewParser("let x = 1 + 2;", false)
+	program := p.ParseProgram()
+
+	entered := []string{}
+	exited := []string{}
+	observer := &nodeRecorder{enter: func(node ast.Node) {
+		entered = append(entered, node.Type().String())
+	}, exit: func(node ast.Node, value object.Object) {
+		if value != nil {
+			exited = append(exited, node.Type().String()+"="+value.Inspect())
+		}
+	}}
+	env := object.NewEnvironment(nil)
+	env.Runtime.Observer = observer
+	evaluator.Evaluate(program, env)
+
+	expected := "PROGRAM LET_DECLARATION BINARY_EXPRESSION INTEGER_LITERAL INTEGER_LITERAL"
+	if expected != strings.Join(entered, " ") {
+		t.Errorf("entered ==> expected: <%s> but was: <%s>", expected, strings.Join(entered, " "))
+	}
+	expected = "INTEGER_LITERAL=1 INTEGER_LITERAL=2 BINARY_EXPRESSION=3"
+	if !strings.HasPrefix(strings.Join(exited, " "), expected) {
+		t.Errorf("exited ==> expected: <%s...> but was: <%s>", expected, strings.Join(exited, " "))
+	}
+}
+
+type nodeRecorder struct {
+	object.BaseObserver
+	enter func(ast.Node)
+	exit  func(ast.Node, object.Object)
+}
+
+func (n *nodeRecorder) Enter(node ast.Node, env *object.Environment) {
+	n.enter(node)
+}
+
+func (n *nodeRecorder) Exit(node ast.Node, env *object.Environment, value object.Object, interrupt object.Interruption) {
+	n.exit(node, value)
+}
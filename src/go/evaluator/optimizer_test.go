@@ -0,0 +1,67 @@
+package evaluator_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/optimizer"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestOptimizer(t *testing.T) {
+	configurations := map[string][]optimizer.Pass{}
+	for _, pass := range optimizer.Passes {
+		configurations[pass.Name] = []optimizer.Pass{pass}
+	}
+	for level := 0; level <= optimizer.MaxLevel; level += 1 {
+		passes, err := optimizer.Level(level)
+		if err != nil {
+			t.Fatalf("Level(%d) ==> unexpected error: %s", level, err)
+		}
+		configurations[fmt.Sprintf("O%d", level)] = passes
+	}
+
+	for name, passes := range configurations {
+		t.Run(name, func(t *testing.T) {
+			evaluator.RunSuites(t, func(program *ast.Program) (object.Object, object.Interruption) {
+				return evaluator.Evaluate(optimizer.Optimize(program, passes...), object.NewEnvironment(nil))
+			})
+		})
+	}
+}
+
+func TestOptimizerFrames(t *testing.T) {
+	input := "let f = fn(a) { a + true }; f(1)"
+	tests := []struct {
+		passes []optimizer.Pass
+		frames []string
+	}{
+		{nil, []string{"f"}},
+		// an inlined call leaves no frame
+		{[]optimizer.Pass{optimizer.Inline}, []string{}},
+	}
+
+	for i, test := range tests {
+		program := parser.NewParser(input, false).ParseProgram()
+		_, interrupt := evaluator.Evaluate(optimizer.Optimize(program, test.passes...), object.NewEnvironment(nil))
+
+		err, ok := interrupt.(*object.Error)
+		if !ok {
+			t.Fatalf("test[%d] - Evaluate() ==> expected: <*object.Error> but was: <%T>", i, interrupt)
+		}
+		if err.Message != "type mismatch: INTEGER + BOOLEAN" {
+			t.Errorf("test[%d] - Message ==> expected: <type mismatch: INTEGER + BOOLEAN> but was: <%s>", i, err.Message)
+		}
+		frames := []string{}
+		for _, frame := range err.Frames {
+			frames = append(frames, frame.Name())
+		}
+		if !slices.Equal(test.frames, frames) {
+			t.Errorf("test[%d] - Frames ==> expected: <%v> but was: <%v>", i, test.frames, frames)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
@@ -8,68 +9,139 @@ import (
 )
 
 func DefineMacros(program *ast.Program, env *object.Environment) *ast.Program {
-	defs := []int{}
+	statements := []ast.Statement{}
 
-	for i, stmt := range program.Statements {
+	for _, stmt := range program.Statements {
 		if macro, ok := stmt.(*ast.MacroStatement); ok {
-			defs = append(defs, i)
 			object := &object.Macro{
 				Declaration: macro,
 				Environment: env,
 			}
 			env.Set(macro.Name.Value, object)
+			continue
 		}
+		if imp, ok := stmt.(*ast.ImportStatement); ok && env.Runtime.Importer != nil {
+			// A module that fails to load raises its error once the import
+			// itself is evaluated.
+			if module, interrupt := env.Runtime.Importer.ImportMacros(imp.Path.Value); interrupt == nil {
+				env.Set(imp.Name.Value, module)
+			}
+		}
+		statements = append(statements, stmt)
 	}
 
-	for i := len(defs) - 1; i >= 0; i -= 1 {
-		program.Statements = append(program.Statements[:defs[i]], program.Statements[defs[i]+1:]...)
+	if len(statements) == len(program.Statements) {
+		return program
 	}
+	return &ast.Program{Statements: statements}
+}
+
+// ExpandMacrosContext expands the macros of program like ExpandMacros, with
+// a fresh budget, aborting with an *object.LimitError once ctx is done or
+// any of env's limits is exceeded.
+func ExpandMacrosContext(ctx context.Context, program ast.Node, env *object.Environment) (ast.Node, object.Interruption) {
+	runtime := env.Runtime
+	previous := runtime.Context
+	runtime.Context = ctx
+	defer func() { runtime.Context = previous }()
+	runtime.Enter()
+	defer runtime.Leave()
 
-	return program
+	runtime.Reset()
+	if err := runtime.Cancelled(); err != nil {
+		return nil, err
+	}
+	return ExpandMacros(program, env)
 }
 
-func ExpandMacros(program ast.Node, env *object.Environment) ast.Node {
-	return ast.Modify(program, func(node ast.Node) ast.Node {
-		call, ok := node.(*ast.CallExpression)
-		if !ok {
+// ExpandMacros replaces every call to a macro defined in env with the quote
+// its body returns. Expansion stops at the first macro that raises an error
+// or does not return a quote, and that error is returned.
+func ExpandMacros(program ast.Node, env *object.Environment) (ast.Node, object.Interruption) {
+	var failure object.Interruption
+	expanded := ast.Rewrite(program, func(node ast.Node) ast.Node {
+		if failure != nil {
 			return node
 		}
 
-		ident, ok := call.Callee.(*ast.Identifier)
+		call, ok := node.(*ast.CallExpression)
 		if !ok {
 			return node
 		}
 
-		value, ok := env.Get(ident.Value)
+		macro, ok := lookupMacro(call.Callee, env)
 		if !ok {
 			return node
 		}
 
-		macro, ok := value.(*object.Macro)
-		if !ok {
+		parameters := macro.Declaration.Parameters
+		if len(call.Arguments) < len(parameters) {
+			failure = &object.Error{
+				Message:  fmt.Sprintf("wrong number of arguments: want=%d, got=%d", len(parameters), len(call.Arguments)),
+				Position: ast.Position(call),
+			}
 			return node
 		}
 
-		args := []*object.Quote{}
-		for _, arg := range call.Arguments {
-			args = append(args, &object.Quote{Node: arg})
-		}
-
 		environemnt := object.NewEnvironment(env)
-		for i, param := range macro.Declaration.Parameters {
-			environemnt.Set(param.Value, args[i])
+		for i, param := range parameters {
+			environemnt.Set(param.Value, &object.Quote{Node: call.Arguments[i]})
 		}
 
-		result, interrput := Evaluate(macro.Declaration.Body, environemnt)
-		if interrput != nil {
-			panic("unexpected interruption during macro expansion")
+		result, interrupt := Evaluate(macro.Declaration.Body, environemnt)
+		if interrupt != nil {
+			failure = interrupt
+			return node
 		}
 
 		quote, ok := result.(*object.Quote)
 		if !ok {
-			panic(fmt.Errorf("unsupported type returned from macro expansion: %s", result.Type()))
+			failure = &object.Error{
+				Message:  fmt.Sprintf("unsupported type returned from macro expansion: %s", result.Type()),
+				Position: ast.Position(call),
+			}
+			return node
 		}
 
-		return quote.Node
+		return ast.Clone(quote.Node)
 	})
+	if failure != nil {
+		return nil, failure
+	}
+	return expanded, nil
+}
+
+// lookupMacro finds the macro that callee names: a macro defined in env, or
+// one exported by a module imported into it.
+func lookupMacro(callee ast.Expression, env *object.Environment) (*object.Macro, bool) {
+	switch callee := callee.(type) {
+	case *ast.Identifier:
+		value, ok := env.Get(callee.Value)
+		if !ok {
+			return nil, false
+		}
+		macro, ok := value.(*object.Macro)
+		return macro, ok
+	case *ast.SubscriptExpression:
+		base, ok := callee.Base.(*ast.Identifier)
+		if !ok {
+			return nil, false
+		}
+		name, ok := callee.Subscript.(*ast.StringLiteral)
+		if !ok {
+			return nil, false
+		}
+		value, ok := env.Get(base.Value)
+		if !ok {
+			return nil, false
+		}
+		module, ok := value.(*object.Module)
+		if !ok {
+			return nil, false
+		}
+		macro, ok := module.Members[name.Value].(*object.Macro)
+		return macro, ok
+	default:
+		return nil, false
+	}
 }
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"context"
 	"testing"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
@@ -111,11 +112,101 @@ func TestExpandMacros(t *testing.T) {
 			t.Fatalf("test[%d] - %s", i, test.input)
 		}
 
-		program = DefineMacros(program, env)
-		program = ExpandMacros(program, env).(*ast.Program)
+		expanded, interrupt := ExpandMacros(DefineMacros(program, env), env)
+		if interrupt != nil {
+			t.Fatalf("test[%d] - ExpandMacros() ==> unexpected interruption: %s", i, interrupt.Inspect())
+		}
+		program = expanded.(*ast.Program)
 
 		if test.object != program.String() {
 			t.Errorf("test[%d] - program.String() ==> expected: <%s> but was: <%s>", i, test.object, program.String())
 		}
 	}
 }
+
+func TestExpandMacrosIsNonDestructive(t *testing.T) {
+	input := `
+	macro twice(f) { quote([unquote(f), unquote(f)]); };
+
+	twice(fn(x) { x; });
+	twice(fn(y) { y; });`
+
+	env := object.NewEnvironment(nil)
+	p := parser.NewParser(input, false)
+	program := p.ParseProgram()
+
+	if 0 != len(p.Errors()) {
+		t.Fatalf("len(p.Errors()) ==> expected: <%d> but was: <%d>", 0, len(p.Errors()))
+	}
+
+	source := program.String()
+	defined := DefineMacros(program, env)
+	node, interrupt := ExpandMacros(defined, env)
+	if interrupt != nil {
+		t.Fatalf("ExpandMacros() ==> unexpected interruption: %s", interrupt.Inspect())
+	}
+	expanded := node.(*ast.Program)
+
+	if source != program.String() {
+		t.Errorf("program.String() ==> expected: <%s> but was: <%s>", source, program.String())
+	}
+
+	if "twice(fn(x){x;});twice(fn(y){y;});" != defined.String() {
+		t.Errorf("defined.String() ==> expected: <%s> but was: <%s>", "twice(fn(x){x;});twice(fn(y){y;});", defined.String())
+	}
+
+	expected := "[fn(x){x;},fn(x){x;}];[fn(y){y;},fn(y){y;}];"
+	if expected != expanded.String() {
+		t.Fatalf("expanded.String() ==> expected: <%s> but was: <%s>", expected, expanded.String())
+	}
+
+	elements := expanded.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.ArrayLiteral).Elements
+	if elements[0] == elements[1] {
+		t.Errorf("expanded elements ==> expected distinct function literals")
+	}
+
+	macro, _ := env.Get("twice")
+	if "{quote([unquote(f),unquote(f)]);}" != macro.(*object.Macro).Declaration.Body.String() {
+		t.Errorf("macro body ==> expected: <%s> but was: <%s>", "{quote([unquote(f),unquote(f)]);}", macro.(*object.Macro).Declaration.Body.String())
+	}
+}
+
+func TestExpandMacrosErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"macro m() { 1 }; m()", "unsupported type returned from macro expansion: INTEGER"},
+		{"macro m(a, b) { quote(unquote(a)) }; m(1)", "wrong number of arguments: want=2, got=1"},
+		{"macro m() { 1 + true }; m()", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for i, test := range tests {
+		env := object.NewEnvironment(nil)
+		program := parser.NewParser(test.input, false).ParseProgram()
+		_, interrupt := ExpandMacros(DefineMacros(program, env), env)
+
+		err, ok := interrupt.(*object.Error)
+		if !ok {
+			t.Errorf("test[%d] - ExpandMacros() ==> expected: <*object.Error> but was: <%T>", i, interrupt)
+			continue
+		}
+		if test.expected != err.Message {
+			t.Errorf("test[%d] - ExpandMacros() ==> expected: <%s> but was: <%s>", i, test.expected, err.Message)
+		}
+	}
+}
+
+func TestExpandMacrosContext(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	env := object.NewEnvironment(nil)
+	program := parser.NewParser("macro spin() { let f = fn(n) { f(n + 1) }; f(0) }; spin()", false).ParseProgram()
+	_, interrupt := ExpandMacrosContext(cancelled, DefineMacros(program, env), env)
+
+	err, ok := interrupt.(*object.LimitError)
+	if !ok || err.Limit != object.CANCELLED {
+		t.Errorf("ExpandMacrosContext() ==> expected: <%s> but was: <%v>", object.CANCELLED, interrupt)
+	}
+}
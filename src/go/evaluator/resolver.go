@@ -0,0 +1,289 @@
+package evaluator
+
+import (
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+)
+
+// Resolve binds every identifier of program to a slot of the function that
+// declares it, or to the global environment, and lays out the slots of
+// every function literal, so the evaluator can index frames instead of
+// walking the environment chain by name.
+func Resolve(program *ast.Program) {
+	r := &resolver{}
+	r.resolveProgram(program)
+}
+
+// Check reports the identifiers of program that are neither declared by the
+// program nor builtins, before anything is evaluated.
+func Check(program *ast.Program) []*object.Error {
+	r := &resolver{check: true, errors: []*object.Error{}}
+	r.resolveProgram(program)
+	return r.errors
+}
+
+type scope struct {
+	names     []string
+	parent    *scope
+	generator bool
+}
+
+// slot finds the last slot named name, so a repeated parameter refers to
+// the argument bound last.
+func (s *scope) slot(name string) (int, bool) {
+	for i := len(s.names) - 1; i >= 0; i -= 1 {
+		if s.names[i] == name {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (s *scope) declare(name string) {
+	if _, found := s.slot(name); !found {
+		s.names = append(s.names, name)
+	}
+}
+
+type resolver struct {
+	current *scope
+	globals map[string]bool
+	macros  map[string]bool
+
+	check  bool
+	errors []*object.Error
+}
+
+func (r *resolver) resolveProgram(program *ast.Program) {
+	if r.check {
+		r.declareGlobals(program)
+	}
+	for _, stmt := range program.Statements {
+		r.resolveStatement(stmt)
+	}
+}
+
+func (r *resolver) declareGlobals(program *ast.Program) {
+	r.globals = map[string]bool{}
+	r.macros = map[string]bool{}
+	for _, stmt := range program.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.LetDeclaration:
+			r.globals[stmt.Name.Value] = true
+		case *ast.MacroStatement:
+			r.globals[stmt.Name.Value] = true
+			r.macros[stmt.Name.Value] = true
+		case *ast.ImportStatement:
+			r.globals[stmt.Name.Value] = true
+		}
+	}
+}
+
+func (r *resolver) resolveStatement(stmt ast.Statement) {
+	switch stmt := stmt.(type) {
+	case *ast.LetDeclaration:
+		r.resolveExpression(stmt.Value)
+		r.resolveIdentifier(stmt.Name, false)
+	case *ast.ReturnStatement:
+		r.resolveExpression(stmt.ReturnValue)
+	case *ast.ExpressionStatement:
+		r.resolveExpression(stmt.Expression)
+	case *ast.BlockStatement:
+		r.resolveBlock(stmt)
+	case *ast.ImportStatement:
+		r.resolveIdentifier(stmt.Name, false)
+		if r.check && r.current != nil {
+			r.errors = append(r.errors, &object.Error{
+				Message:  "import inside a function",
+				Position: stmt.Token.Position,
+			})
+		}
+	}
+}
+
+func (r *resolver) resolveBlock(block *ast.BlockStatement) {
+	if block == nil {
+		return
+	}
+	for _, stmt := range block.Statements {
+		r.resolveStatement(stmt)
+	}
+}
+
+func (r *resolver) resolveExpression(expr ast.Expression) {
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		r.resolveIdentifier(expr, true)
+	case *ast.UnaryExpression:
+		r.resolveExpression(expr.Right)
+	case *ast.BinaryExpression:
+		r.resolveExpression(expr.Left)
+		r.resolveExpression(expr.Right)
+	case *ast.LogicalExpression:
+		r.resolveExpression(expr.Left)
+		r.resolveExpression(expr.Right)
+	case *ast.ConditionalExpression:
+		r.resolveExpression(expr.Condition)
+		r.resolveBlock(expr.Consequence)
+		r.resolveBlock(expr.Alternative)
+	case *ast.FunctionLiteral:
+		r.resolveFunction(expr)
+	case *ast.CallExpression:
+		r.resolveExpression(expr.Callee)
+		if r.isMacroCall(expr) {
+			return
+		}
+		for _, arg := range expr.Arguments {
+			r.resolveExpression(arg)
+		}
+	case *ast.AssignmentExpression:
+		r.resolveExpression(expr.RValue)
+		switch lvalue := expr.LValue.(type) {
+		case *ast.Identifier:
+			r.resolveIdentifier(lvalue, true)
+		default:
+			r.resolveExpression(lvalue)
+		}
+	case *ast.SubscriptExpression:
+		r.resolveExpression(expr.Base)
+		r.resolveExpression(expr.Subscript)
+	case *ast.ArrayLiteral:
+		for _, element := range expr.Elements {
+			r.resolveExpression(element)
+		}
+	case *ast.HashLiteral:
+		for _, key := range expr.Keys {
+			r.resolveExpression(key)
+			r.resolveExpression(expr.Pairs[key])
+		}
+	case *ast.YieldExpression:
+		r.resolveExpression(expr.Value)
+		if r.check && r.current == nil {
+			r.errors = append(r.errors, &object.Error{
+				Message:  "yield outside a function",
+				Position: expr.Token.Position,
+			})
+		}
+	}
+}
+
+func (r *resolver) resolveFunction(fn *ast.FunctionLiteral) {
+	s := &scope{names: make([]string, 0, len(fn.Parameters)), parent: r.current}
+	for _, param := range fn.Parameters {
+		s.names = append(s.names, param.Value)
+	}
+	r.declareBlock(fn.Body, s)
+	fn.Scope = &ast.Scope{Names: s.names, Generator: s.generator}
+
+	r.current = s
+	r.resolveBlock(fn.Body)
+	r.current = s.parent
+}
+
+// declareBlock adds every name that block binds in the function being
+// resolved: its let declarations and imports. Assignments bind nothing; they
+// write through to the variable they name, wherever it was declared.
+func (r *resolver) declareBlock(block *ast.BlockStatement, s *scope) {
+	if block == nil {
+		return
+	}
+	for _, stmt := range block.Statements {
+		switch stmt := stmt.(type) {
+		case *ast.LetDeclaration:
+			r.declareExpression(stmt.Value, s)
+			s.declare(stmt.Name.Value)
+		case *ast.ReturnStatement:
+			r.declareExpression(stmt.ReturnValue, s)
+		case *ast.ExpressionStatement:
+			r.declareExpression(stmt.Expression, s)
+		case *ast.BlockStatement:
+			r.declareBlock(stmt, s)
+		case *ast.ImportStatement:
+			s.declare(stmt.Name.Value)
+		}
+	}
+}
+
+func (r *resolver) declareExpression(expr ast.Expression, s *scope) {
+	switch expr := expr.(type) {
+	case *ast.UnaryExpression:
+		r.declareExpression(expr.Right, s)
+	case *ast.BinaryExpression:
+		r.declareExpression(expr.Left, s)
+		r.declareExpression(expr.Right, s)
+	case *ast.LogicalExpression:
+		r.declareExpression(expr.Left, s)
+		r.declareExpression(expr.Right, s)
+	case *ast.ConditionalExpression:
+		r.declareExpression(expr.Condition, s)
+		r.declareBlock(expr.Consequence, s)
+		r.declareBlock(expr.Alternative, s)
+	case *ast.CallExpression:
+		r.declareExpression(expr.Callee, s)
+		if r.isMacroCall(expr) {
+			return
+		}
+		for _, arg := range expr.Arguments {
+			r.declareExpression(arg, s)
+		}
+	case *ast.AssignmentExpression:
+		r.declareExpression(expr.RValue, s)
+		r.declareExpression(expr.LValue, s)
+	case *ast.SubscriptExpression:
+		r.declareExpression(expr.Base, s)
+		r.declareExpression(expr.Subscript, s)
+	case *ast.ArrayLiteral:
+		for _, element := range expr.Elements {
+			r.declareExpression(element, s)
+		}
+	case *ast.HashLiteral:
+		for _, key := range expr.Keys {
+			r.declareExpression(key, s)
+			r.declareExpression(expr.Pairs[key], s)
+		}
+	case *ast.YieldExpression:
+		s.generator = true
+		r.declareExpression(expr.Value, s)
+	}
+}
+
+func (r *resolver) resolveIdentifier(ident *ast.Identifier, reference bool) {
+	depth := 0
+	for s := r.current; s != nil; s = s.parent {
+		if slot, found := s.slot(ident.Value); found {
+			ident.Binding = ast.Binding{Kind: ast.LOCAL, Depth: depth, Slot: slot}
+			return
+		}
+		depth += 1
+	}
+	ident.Binding = ast.Binding{Kind: ast.GLOBAL, Depth: depth}
+
+	if !r.check || !reference || r.globals[ident.Value] {
+		return
+	}
+	if _, found := LookupBuiltin(ident.Value); !found {
+		r.errors = append(r.errors, &object.Error{
+			Message:  "unknown identifier: " + ident.Value,
+			Position: ident.Token.Position,
+		})
+	}
+}
+
+// isMacroCall reports calls whose arguments are syntax rather than values:
+// those of the builtin macros and, before expansion, of declared macros.
+func (r *resolver) isMacroCall(call *ast.CallExpression) bool {
+	ident, ok := call.Callee.(*ast.Identifier)
+	if !ok {
+		return false
+	}
+	for s := r.current; s != nil; s = s.parent {
+		if _, found := s.slot(ident.Value); found {
+			return false
+		}
+	}
+	if builtin, found := LookupBuiltin(ident.Value); found {
+		_, ok := builtin.(*object.BuiltinMacro)
+		return ok
+	}
+	return r.macros[ident.Value]
+}
@@ -0,0 +1,212 @@
+package evaluator
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func TestResolve(t *testing.T) {
+	program := parser.NewParser(`
+		let x = 1;
+		let f = fn(a, b) {
+			let c = a;
+			fn(d) { c = d; a + x + len(d) }
+		};
+		quote(a);`, false).ParseProgram()
+	Resolve(program)
+
+	f := program.Statements[1].(*ast.LetDeclaration).Value.(*ast.FunctionLiteral)
+	if expected := []string{"a", "b", "c"}; !slices.Equal(expected, f.Scope.Names) {
+		t.Errorf("f.Scope.Names ==> expected: <%v> but was: <%v>", expected, f.Scope.Names)
+	}
+
+	inner := f.Body.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+	if expected := []string{"d"}; !slices.Equal(expected, inner.Scope.Names) {
+		t.Errorf("inner.Scope.Names ==> expected: <%v> but was: <%v>", expected, inner.Scope.Names)
+	}
+
+	identifiers := map[string]ast.Binding{}
+	ast.Walk(inner.Body, ast.WalkFuncs{Pre: func(node ast.Node, _ []ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			identifiers[ident.Value] = ident.Binding
+		}
+		return true
+	}})
+
+	tests := []struct {
+		name     string
+		expected ast.Binding
+	}{
+		{"c", ast.Binding{Kind: ast.LOCAL, Depth: 1, Slot: 2}},
+		{"d", ast.Binding{Kind: ast.LOCAL, Depth: 0, Slot: 0}},
+		{"a", ast.Binding{Kind: ast.LOCAL, Depth: 1, Slot: 0}},
+		{"x", ast.Binding{Kind: ast.GLOBAL, Depth: 2}},
+		{"len", ast.Binding{Kind: ast.GLOBAL, Depth: 2}},
+	}
+
+	for i, test := range tests {
+		if actual := identifiers[test.name]; test.expected != actual {
+			t.Errorf("test[%d] - %s.Binding ==> expected: <%+v> but was: <%+v>", i, test.name, test.expected, actual)
+		}
+	}
+
+	quoted := program.Statements[2].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[0].(*ast.Identifier)
+	if quoted.Binding.Kind != ast.UNRESOLVED {
+		t.Errorf("quoted.Binding.Kind ==> expected: <%d> but was: <%d>", ast.UNRESOLVED, quoted.Binding.Kind)
+	}
+}
+
+func TestResolvedScoping(t *testing.T) {
+	tests := []EvaluatorTest{
+		{
+			input:  "let x = 1; let f = fn() { let y = x; let x = 2; [y, x] }; f()",
+			object: ArrayTest{[]ObjectTest{IntegerTest(1), IntegerTest(2)}},
+		},
+		{
+			input:  "let x = 1; let f = fn() { x = x + 1; x }; [f(), f(), x]",
+			object: ArrayTest{[]ObjectTest{IntegerTest(2), IntegerTest(3), IntegerTest(3)}},
+		},
+		{
+			input:  "let f = fn(x, x) { x }; f(1, 2)",
+			object: IntegerTest(2),
+		},
+		{
+			input:  "let f = fn() { g() }; let g = fn() { 7 }; f()",
+			object: IntegerTest(7),
+		},
+		{
+			input:  "let counter = fn() { let n = 0; fn() { n = n + 1; n } }; let c = counter(); c(); c()",
+			object: IntegerTest(2),
+		},
+		{
+			input:  "let f = fn(n) { if (n > 0) { let m = n * 2; } m }; f(2)",
+			object: IntegerTest(4),
+		},
+		{
+			input:  "let len = fn(x) { 42 }; let f = fn() { len([]) }; f()",
+			object: IntegerTest(42),
+		},
+		{
+			input:  "let f = fn(a) { quote(unquote(a) + 1) }; f(2)",
+			object: QuoteTest{"(2+1)"},
+		},
+		{
+			input: "let f = fn() { y = 1 }; f()",
+			error: ErrorTest{"unknown identifier: y"},
+		},
+	}
+
+	for i, test := range tests {
+		testEvaluator(t, i, test, evaluate)
+	}
+}
+
+func TestResolvedAcrossPrograms(t *testing.T) {
+	env := object.NewEnvironment(nil)
+	inputs := []string{
+		"let f = fn(x) { g(x) + y };",
+		"let g = fn(x) { x * 2 }; let y = 1;",
+		"f(20)",
+	}
+
+	var value object.Object
+	for i, input := range inputs {
+		var interrupt object.Interruption
+		value, interrupt = Evaluate(parser.NewParser(input, false).ParseProgram(), env)
+		if interrupt != nil {
+			t.Fatalf("test[%d] - Evaluate() ==> expected: <nil> but was: <%s>", i, interrupt.Inspect())
+		}
+	}
+
+	if value != object.Integer(41) {
+		t.Errorf("Evaluate() ==> expected: <41> but was: <%s>", value.Inspect())
+	}
+}
+
+func TestEvaluateLeavesProgramUnresolved(t *testing.T) {
+	macros := object.NewEnvironment(nil)
+	program := parser.NewParser("macro twice(x) { quote(unquote(x) + unquote(x)) }; let f = fn(a) { twice(a) }; f(2)", false).ParseProgram()
+	expanded, interrupt := ExpandMacros(DefineMacros(program, macros), macros)
+	if interrupt != nil {
+		t.Fatalf("ExpandMacros() ==> unexpected interruption: %s", interrupt.Inspect())
+	}
+
+	if value, interrupt := Evaluate(expanded, object.NewEnvironment(nil)); interrupt != nil || value != object.Integer(4) {
+		t.Fatalf("Evaluate() ==> expected: <4> but was: <%v %v>", value, interrupt)
+	}
+
+	for _, node := range []ast.Node{program, expanded} {
+		ast.Inspect(node, func(node ast.Node) bool {
+			switch node := node.(type) {
+			case *ast.Identifier:
+				if node.Binding.Kind != ast.UNRESOLVED {
+					t.Errorf("%s.Binding ==> expected: <UNRESOLVED> but was: <%+v>", node.Value, node.Binding)
+				}
+			case *ast.FunctionLiteral:
+				if node.Scope != nil {
+					t.Errorf("%s.Scope ==> expected: <nil> but was: <%+v>", node, node.Scope)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"let x = 1; x + len([])", []string{}},
+		{"let f = fn() { g() }; let g = fn() { f() };", []string{}},
+		{"let f = fn(a) { a + b };\nc", []string{"1:21: unknown identifier: b", "2:1: unknown identifier: c"}},
+		{"let f = fn() { let a = 1; a = 2 }; a", []string{"1:36: unknown identifier: a"}},
+		{"quote(anything); macro m(a) { quote(unquote(a) + z) }; m(w)", []string{}},
+		{"let g = fn() { yield 1 };\nyield 2", []string{"2:1: yield outside a function"}},
+		{`import "lib.mk" as l; l["f"](l)`, []string{}},
+		{`let f = fn() { import "lib.mk" as l; l }`, []string{"1:16: import inside a function"}},
+	}
+
+	for i, test := range tests {
+		errors := []string{}
+		for _, err := range Check(parser.NewParser(test.input, false).ParseProgram()) {
+			errors = append(errors, err.Position.String()+": "+err.Message)
+		}
+		if !slices.Equal(test.expected, errors) {
+			t.Errorf("test[%d] - Check() ==> expected: <%v> but was: <%v>", i, test.expected, errors)
+		}
+	}
+}
+
+func TestResolveGenerators(t *testing.T) {
+	program := parser.NewParser(`
+		let g = fn() { let h = fn() { 1 }; h(yield 1) };
+		let f = fn() { fn() { yield 2 } };
+		let q = fn() { quote(yield 3) };`, false).ParseProgram()
+	Resolve(program)
+
+	literal := func(i int) *ast.FunctionLiteral {
+		return program.Statements[i].(*ast.LetDeclaration).Value.(*ast.FunctionLiteral)
+	}
+	inner := literal(1).Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.FunctionLiteral)
+
+	tests := []struct {
+		name     string
+		fn       *ast.FunctionLiteral
+		expected bool
+	}{
+		{"g", literal(0), true},
+		{"f", literal(1), false},
+		{"inner", inner, true},
+		{"q", literal(2), false},
+	}
+	for i, test := range tests {
+		if test.expected != test.fn.Scope.Generator {
+			t.Errorf("test[%d] - %s.Scope.Generator ==> expected: <%t> but was: <%t>", i, test.name, test.expected, test.fn.Scope.Generator)
+		}
+	}
+}
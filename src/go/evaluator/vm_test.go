@@ -0,0 +1,21 @@
+package evaluator_test
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/compiler"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/vm"
+)
+
+func TestVirtualMachine(t *testing.T) {
+	evaluator.RunSuites(t, func(program *ast.Program) (object.Object, object.Interruption) {
+		c := compiler.New()
+		if err := c.Compile(program); err != nil {
+			t.Fatalf("Compile() ==> unexpected error: %s", err)
+		}
+		return vm.New(c.Bytecode()).Run()
+	})
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/formatter"
+)
+
+func format(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	check := flags.Bool("check", false, "report files whose formatting differs and exit non-zero")
+	write := flags.Bool("w", false, "write the result to the source file instead of stdout")
+	width := flags.Int("width", formatter.DefaultOptions.Width, "preferred maximum line width")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	opts := formatter.DefaultOptions
+	opts.Width = *width
+
+	if flags.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		formatted, err := formatter.SourceWithOptions(src, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "<stdin>: %s\n", err)
+			return 1
+		}
+		if *check {
+			if !bytes.Equal(src, formatted) {
+				fmt.Println("<stdin>")
+				return 1
+			}
+			return 0
+		}
+		os.Stdout.Write(formatted)
+		return 0
+	}
+
+	status := 0
+	for _, filename := range flags.Args() {
+		src, err := os.ReadFile(filename)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			status = 1
+			continue
+		}
+
+		formatted, err := formatter.SourceWithOptions(src, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", filename, err)
+			status = 1
+			continue
+		}
+
+		switch {
+		case *check:
+			if !bytes.Equal(src, formatted) {
+				fmt.Println(filename)
+				status = 1
+			}
+		case *write:
+			if !bytes.Equal(src, formatted) {
+				if err := os.WriteFile(filename, formatted, 0644); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+					status = 1
+				}
+			}
+		default:
+			os.Stdout.Write(formatted)
+		}
+	}
+	return status
+}
@@ -0,0 +1,543 @@
+package formatter
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/lexer"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+type Options struct {
+	Width    int
+	TabWidth int
+}
+
+var DefaultOptions = Options{Width: 80, TabWidth: 4}
+
+type ParseError struct {
+	Errors []string
+}
+
+func (pe *ParseError) Error() string {
+	return "parser errors:\n\t" + strings.Join(pe.Errors, "\n\t")
+}
+
+func Source(src []byte) ([]byte, error) {
+	return SourceWithOptions(src, DefaultOptions)
+}
+
+func SourceWithOptions(src []byte, opts Options) ([]byte, error) {
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Errors: p.Errors()}
+	}
+
+	pr := newPrinter(opts)
+	pr.attach(string(src), p.Comments())
+	return []byte(pr.program(program)), nil
+}
+
+func Node(node ast.Node) string {
+	return NodeWithOptions(node, DefaultOptions)
+}
+
+func NodeWithOptions(node ast.Node, opts Options) string {
+	pr := newPrinter(opts)
+	switch node := node.(type) {
+	case *ast.Program:
+		return strings.TrimSuffix(pr.program(node), "\n")
+	case ast.Statement:
+		return strings.TrimSuffix(pr.statements([]ast.Statement{node}, 0, -1), "\n")
+	case ast.Expression:
+		return pr.expression(node, 0, 0, 0)
+	default:
+		return node.String()
+	}
+}
+
+type printer struct {
+	opts Options
+
+	source   string
+	tokens   []token.Token
+	closes   map[int]int
+	comments []comment
+	next     int
+}
+
+type comment struct {
+	token    token.Token
+	trailing bool
+}
+
+func newPrinter(opts Options) *printer {
+	if opts.Width <= 0 {
+		opts.Width = DefaultOptions.Width
+	}
+	if opts.TabWidth <= 0 {
+		opts.TabWidth = DefaultOptions.TabWidth
+	}
+	return &printer{opts: opts, closes: map[int]int{}}
+}
+
+func (p *printer) attach(source string, comments []token.Token) {
+	p.source = source
+	p.tokens = lexer.NewLexer(source).Tokens()
+
+	opens := []int{}
+	for _, tok := range p.tokens {
+		switch tok.Type {
+		case token.LBRACE, token.LPAREN, token.LBRACK:
+			opens = append(opens, tok.Position.Offset)
+		case token.RBRACE, token.RPAREN, token.RBRACK:
+			if len(opens) > 0 {
+				p.closes[opens[len(opens)-1]] = tok.Position.Offset
+				opens = opens[:len(opens)-1]
+			}
+		}
+	}
+
+	for _, c := range comments {
+		trailing := false
+		for _, tok := range p.tokens {
+			if tok.Position.Offset >= c.Position.Offset {
+				break
+			}
+			if tok.Position.Line == c.Position.Line {
+				trailing = true
+			}
+		}
+		p.comments = append(p.comments, comment{token: c, trailing: trailing})
+	}
+}
+
+func (p *printer) program(program *ast.Program) string {
+	return p.statements(program.Statements, 0, len(p.source)+1)
+}
+
+func (p *printer) statements(stmts []ast.Statement, indent, end int) string {
+	var out strings.Builder
+	lastLine := 0
+
+	for i, stmt := range stmts {
+		start := position(stmt)
+
+		lastLine = p.flush(&out, indent, start.Offset, lastLine)
+		if lastLine > 0 && start.Line-lastLine > 1 && out.Len() > 0 {
+			out.WriteString("\n")
+		}
+
+		out.WriteString(p.indent(indent))
+		out.WriteString(p.statement(stmt, indent))
+		if terminated(stmt, stmts, i) {
+			out.WriteString(";")
+		}
+		out.WriteString("\n")
+
+		boundary := end
+		if i+1 < len(stmts) {
+			boundary = position(stmts[i+1]).Offset
+		}
+		lastLine = p.endLine(start.Offset, boundary)
+	}
+
+	if end >= 0 {
+		p.flush(&out, indent, end, lastLine)
+	}
+
+	return out.String()
+}
+
+func (p *printer) flush(out *strings.Builder, indent, offset, lastLine int) int {
+	for p.next < len(p.comments) && p.comments[p.next].token.Position.Offset < offset {
+		c := p.comments[p.next]
+		p.next += 1
+
+		current := out.String()
+		if c.trailing && strings.HasSuffix(current, "\n") && !strings.HasSuffix(current, "\n\n") && strings.TrimSpace(lastLineOf(current)) != "" {
+			trimmed := strings.TrimSuffix(current, "\n")
+			out.Reset()
+			out.WriteString(trimmed)
+			out.WriteString(" ")
+			out.WriteString(c.token.Literal)
+			out.WriteString("\n")
+		} else {
+			if lastLine > 0 && c.token.Position.Line-lastLine > 1 && out.Len() > 0 {
+				out.WriteString("\n")
+			}
+			out.WriteString(p.indent(indent))
+			out.WriteString(c.token.Literal)
+			out.WriteString("\n")
+		}
+		lastLine = c.token.Position.Line
+	}
+	return lastLine
+}
+
+// pending reports whether a comment before offset has yet to be printed.
+func (p *printer) pending(offset int) bool {
+	return p.next < len(p.comments) && p.comments[p.next].token.Position.Offset < offset
+}
+
+func (p *printer) endLine(start, boundary int) int {
+	i := sort.Search(len(p.tokens), func(i int) bool {
+		return p.tokens[i].Position.Offset >= boundary || p.tokens[i].Type == token.EOF
+	})
+	if i == 0 {
+		return 0
+	}
+	last := p.tokens[i-1]
+	if last.Position.Offset < start {
+		return 0
+	}
+	return last.Position.Line + strings.Count(last.Literal, "\n")
+}
+
+func (p *printer) statement(stmt ast.Statement, indent int) string {
+	col := indent * p.opts.TabWidth
+
+	switch stmt := stmt.(type) {
+	case *ast.LetDeclaration:
+		prefix := "let " + stmt.Name.Value + " = "
+		if stmt.Exported {
+			prefix = "export " + prefix
+		}
+		if stmt.Value == nil {
+			return strings.TrimSuffix(prefix, " = ")
+		}
+		return prefix + p.expression(stmt.Value, 0, indent, col+len(prefix))
+	case *ast.ReturnStatement:
+		if stmt.ReturnValue == nil {
+			return "return"
+		}
+		return "return " + p.expression(stmt.ReturnValue, 0, indent, col+len("return "))
+	case *ast.ExpressionStatement:
+		if stmt.Expression == nil {
+			return ""
+		}
+		return p.expression(stmt.Expression, 0, indent, col)
+	case *ast.BlockStatement:
+		return p.block(stmt, indent)
+	case *ast.MacroStatement:
+		prefix := "macro "
+		if stmt.Exported {
+			prefix = "export " + prefix
+		}
+		return prefix + stmt.Name.Value + "(" + identifiers(stmt.Parameters) + ") " + p.block(stmt.Body, indent)
+	case *ast.ImportStatement:
+		return "import " + stmt.Path.Token.Literal + " as " + stmt.Name.Value
+	default:
+		return stmt.String()
+	}
+}
+
+func terminated(stmt ast.Statement, stmts []ast.Statement, i int) bool {
+	switch stmt := stmt.(type) {
+	case *ast.MacroStatement, *ast.BlockStatement:
+		return false
+	case *ast.ExpressionStatement:
+		if _, ok := stmt.Expression.(*ast.ConditionalExpression); !ok {
+			return true
+		}
+		if i+1 >= len(stmts) {
+			return false
+		}
+		next, ok := stmts[i+1].(*ast.ExpressionStatement)
+		if !ok {
+			return false
+		}
+		switch next.Token.Type {
+		case token.LPAREN, token.LBRACK, token.MINUS:
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func (p *printer) block(block *ast.BlockStatement, indent int) string {
+	end := p.close(block.Token.Position)
+	if len(block.Statements) == 0 && !p.pending(end) {
+		return "{}"
+	}
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	out.WriteString(p.statements(block.Statements, indent+1, end))
+	out.WriteString(p.indent(indent))
+	out.WriteString("}")
+	return out.String()
+}
+
+// close returns the offset of the bracket that closes the one at open, or
+// -1 when the source is unknown.
+func (p *printer) close(open token.Position) int {
+	end, ok := p.closes[open.Offset]
+	if !ok || !open.IsValid() {
+		return -1
+	}
+	return end
+}
+
+const (
+	_ int = iota
+	NONE
+	ASSIGNMENT
+	OR
+	AND
+	EQUALITY
+	COMPARISON
+	TERM
+	FACTOR
+	UNARY
+	CALL
+	SUBSCRIPT
+	PRIMARY
+)
+
+var binaries = map[string]int{
+	"or":  OR,
+	"and": AND,
+	"==":  EQUALITY,
+	"!=":  EQUALITY,
+	"<":   COMPARISON,
+	">":   COMPARISON,
+	"+":   TERM,
+	"-":   TERM,
+	"*":   FACTOR,
+	"/":   FACTOR,
+	"%":   FACTOR,
+}
+
+func precedence(expr ast.Expression) int {
+	switch expr := expr.(type) {
+	case *ast.AssignmentExpression, *ast.YieldExpression:
+		return ASSIGNMENT
+	case *ast.BinaryExpression:
+		return binaries[expr.Operator]
+	case *ast.LogicalExpression:
+		return binaries[expr.Operator]
+	case *ast.UnaryExpression:
+		return UNARY
+	case *ast.CallExpression:
+		return CALL
+	case *ast.SubscriptExpression:
+		return SUBSCRIPT
+	default:
+		return PRIMARY
+	}
+}
+
+func (p *printer) expression(expr ast.Expression, context, indent, col int) string {
+	if precedence(expr) < context {
+		return "(" + p.expression(expr, 0, indent, col+1) + ")"
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Identifier:
+		return expr.Value
+	case *ast.IntegerLiteral:
+		return expr.String()
+	case *ast.FloatLiteral:
+		return expr.String()
+	case *ast.DecimalLiteral:
+		return expr.String()
+	case *ast.StringLiteral:
+		if expr.Token.Literal == "" {
+			return "\"" + expr.Value + "\""
+		}
+		return expr.Token.Literal
+	case *ast.BooleanLiteral:
+		if expr.Value {
+			return "true"
+		}
+		return "false"
+	case *ast.NullLiteral:
+		return "null"
+	case *ast.UnaryExpression:
+		return expr.Operator + p.expression(expr.Right, UNARY, indent, col+len(expr.Operator))
+	case *ast.BinaryExpression:
+		return p.binary(expr.Left, expr.Operator, expr.Right, indent, col)
+	case *ast.LogicalExpression:
+		return p.binary(expr.Left, expr.Operator, expr.Right, indent, col)
+	case *ast.AssignmentExpression:
+		left := p.expression(expr.LValue, CALL, indent, col)
+		return left + " = " + p.expression(expr.RValue, ASSIGNMENT, indent, p.column(left, col)+3)
+	case *ast.YieldExpression:
+		return "yield " + p.expression(expr.Value, ASSIGNMENT, indent, col+len("yield "))
+	case *ast.ConditionalExpression:
+		condition := p.expression(expr.Condition, 0, indent, col+len("if ("))
+		out := "if (" + condition + ") " + p.block(expr.Consequence, indent)
+		if expr.Alternative == nil {
+			return out
+		}
+		// Comments between the blocks stay there, with else on a line
+		// of its own after them.
+		if at := expr.Alternative.Token.Position; at.IsValid() && p.pending(at.Offset) {
+			var b strings.Builder
+			b.WriteString(out + "\n")
+			p.flush(&b, indent, at.Offset, 0)
+			out = b.String() + p.indent(indent) + "else "
+		} else {
+			out += " else "
+		}
+		return out + p.block(expr.Alternative, indent)
+	case *ast.FunctionLiteral:
+		return "fn(" + identifiers(expr.Parameters) + ") " + p.block(expr.Body, indent)
+	case *ast.CallExpression:
+		callee := p.expression(expr.Callee, CALL, indent, col)
+		return callee + p.list("(", ")", expr.Token.Position, expr.Arguments, nil, indent, p.column(callee, col))
+	case *ast.SubscriptExpression:
+		base := p.expression(expr.Base, CALL, indent, col)
+		return base + "[" + p.expression(expr.Subscript, 0, indent, p.column(base, col)+1) + "]"
+	case *ast.ArrayLiteral:
+		return p.list("[", "]", expr.Token.Position, expr.Elements, nil, indent, col)
+	case *ast.HashLiteral:
+		return p.list("{", "}", expr.Token.Position, expr.Keys, expr.Pairs, indent, col)
+	default:
+		return expr.String()
+	}
+}
+
+func (p *printer) binary(left ast.Expression, operator string, right ast.Expression, indent, col int) string {
+	prec := binaries[operator]
+	lhs := p.expression(left, prec, indent, col)
+	return lhs + " " + operator + " " + p.expression(right, prec+1, indent, p.column(lhs, col)+len(operator)+2)
+}
+
+// list prints exprs between open and close, the bracket at position, on
+// one line if they fit and there are no comments among them, and one per
+// line otherwise, each after the comments that precede it.
+func (p *printer) list(open, close string, position token.Position, exprs []ast.Expression, pairs map[ast.Expression]ast.Expression, indent, col int) string {
+	end := p.close(position)
+	if len(exprs) == 0 && !p.pending(end) {
+		return open + close
+	}
+
+	item := func(expr ast.Expression, indent, col int) string {
+		if pairs == nil {
+			return p.expression(expr, 0, indent, col)
+		}
+		key := p.expression(expr, 0, indent, col)
+		return key + ": " + p.expression(pairs[expr], 0, indent, p.column(key, col)+2)
+	}
+
+	// Comments within the items are printed with them, so any comment
+	// still pending before an item is between the items.
+	mark := p.next
+	flat := open
+	commented := false
+	for i, expr := range exprs {
+		commented = commented || p.pending(start(expr).Offset)
+		if i > 0 {
+			flat += ", "
+		}
+		flat += item(expr, indent, p.column(flat, col))
+	}
+	flat += close
+	commented = commented || p.pending(end)
+
+	if !commented && p.fits(flat, col) {
+		return flat
+	}
+	p.next = mark
+
+	inner := (indent + 1) * p.opts.TabWidth
+	var out strings.Builder
+	out.WriteString(open)
+	out.WriteString("\n")
+	for i, expr := range exprs {
+		p.flush(&out, indent+1, start(expr).Offset, 0)
+		out.WriteString(p.indent(indent + 1))
+		out.WriteString(item(expr, indent+1, inner))
+		if i < len(exprs)-1 {
+			out.WriteString(",")
+		}
+		out.WriteString("\n")
+	}
+	p.flush(&out, indent+1, end, 0)
+	out.WriteString(p.indent(indent))
+	out.WriteString(close)
+	return out.String()
+}
+
+func (p *printer) fits(s string, col int) bool {
+	first, _, _ := strings.Cut(s, "\n")
+	return col+p.width(first) <= p.opts.Width
+}
+
+func (p *printer) column(s string, col int) int {
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return p.width(s[i+1:])
+	}
+	return col + p.width(s)
+}
+
+func (p *printer) width(s string) int {
+	return len(s) + strings.Count(s, "\t")*(p.opts.TabWidth-1)
+}
+
+func (p *printer) indent(level int) string {
+	return strings.Repeat("\t", level)
+}
+
+func identifiers(idents []*ast.Identifier) string {
+	names := []string{}
+	for _, ident := range idents {
+		names = append(names, ident.Value)
+	}
+	return strings.Join(names, ", ")
+}
+
+func lastLineOf(s string) string {
+	s = strings.TrimSuffix(s, "\n")
+	if i := strings.LastIndex(s, "\n"); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
+
+// start returns where expr begins in the source, which for an operator or a
+// call is where its left operand does.
+func start(expr ast.Expression) token.Position {
+	switch expr := expr.(type) {
+	case *ast.BinaryExpression:
+		return start(expr.Left)
+	case *ast.LogicalExpression:
+		return start(expr.Left)
+	case *ast.AssignmentExpression:
+		return start(expr.LValue)
+	case *ast.SubscriptExpression:
+		return start(expr.Base)
+	case *ast.CallExpression:
+		return start(expr.Callee)
+	default:
+		return ast.Position(expr)
+	}
+}
+
+func position(stmt ast.Statement) token.Position {
+	switch stmt := stmt.(type) {
+	case *ast.LetDeclaration:
+		return stmt.Token.Position
+	case *ast.ReturnStatement:
+		return stmt.Token.Position
+	case *ast.ExpressionStatement:
+		return stmt.Token.Position
+	case *ast.BlockStatement:
+		return stmt.Token.Position
+	case *ast.MacroStatement:
+		return stmt.Token.Position
+	case *ast.ImportStatement:
+		return stmt.Token.Position
+	case *ast.Error:
+		return stmt.Token.Position
+	default:
+		return token.Position{}
+	}
+}
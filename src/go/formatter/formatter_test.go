@@ -0,0 +1,175 @@
+package formatter
+
+import (
+	"testing"
+)
+
+func TestSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let x=1", "let x = 1;\n"},
+		{"let add=fn(a,b){a+b};", "let add = fn(a, b) {\n\ta + b;\n};\n"},
+		{"return  5", "return 5;\n"},
+		{"(1+2)*3", "(1 + 2) * 3;\n"},
+		{"1+(2*3)", "1 + 2 * 3;\n"},
+		{"1-(2-3)", "1 - (2 - 3);\n"},
+		{"(1-2)-3", "1 - 2 - 3;\n"},
+		{"-(1+2)", "-(1 + 2);\n"},
+		{"x=y=3", "x = y = 3;\n"},
+		{"true or (false and true)", "true or false and true;\n"},
+		{"(true or false) and true", "(true or false) and true;\n"},
+		{`{"a":1,"b":[1,2]}["b"][0]`, "{\"a\": 1, \"b\": [1, 2]}[\"b\"][0];\n"},
+		{"if(x){1}else{2}", "if (x) {\n\t1;\n} else {\n\t2;\n}\n"},
+		{"let f=fn(){}", "let f = fn() {};\n"},
+		{"macro m(a){quote(unquote(a))}", "macro m(a) {\n\tquote(unquote(a));\n}\n"},
+		{"let a=1;\n\n\n\nlet b=2;let c=3;", "let a = 1;\n\nlet b = 2;\nlet c = 3;\n"},
+		{"let g=fn(n){yield n*2;1+(yield n)}", "let g = fn(n) {\n\tyield n * 2;\n\t1 + (yield n);\n};\n"},
+		{`import "lib.mk" as l;export let x=l["y"];export macro m(a){a}`, "import \"lib.mk\" as l;\nexport let x = l[\"y\"];\nexport macro m(a) {\n\ta;\n}\n"},
+	}
+
+	for i, test := range tests {
+		actual, err := Source([]byte(test.input))
+		if err != nil {
+			t.Fatalf("test[%d] - Source() ==> unexpected error: %s", i, err)
+		}
+		if string(actual) != test.expected {
+			t.Errorf("test[%d] - Source() ==> expected: <%q> but was: <%q>", i, test.expected, string(actual))
+		}
+	}
+}
+
+func TestSourceComments(t *testing.T) {
+	input := `// header
+let x=1; // one
+
+// before y
+let y=fn(){
+  // inside
+  x
+};
+// footer
+`
+	expected := `// header
+let x = 1; // one
+
+// before y
+let y = fn() {
+	// inside
+	x;
+};
+// footer
+`
+
+	actual, err := Source([]byte(input))
+	if err != nil {
+		t.Fatalf("Source() ==> unexpected error: %s", err)
+	}
+	if string(actual) != expected {
+		t.Fatalf("Source() ==> expected: <%q> but was: <%q>", expected, string(actual))
+	}
+}
+
+func TestSourceCommentsInPlace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			"let xs = [1, // one\n2];",
+			"let xs = [\n\t1, // one\n\t2\n];\n",
+		},
+		{
+			"let h = {\n// first\n\"a\": 1,\n\"b\": [2, 3] // last\n};\nlet y = 2;",
+			"let h = {\n\t// first\n\t\"a\": 1,\n\t\"b\": [2, 3] // last\n};\nlet y = 2;\n",
+		},
+		{
+			"puts(1, // why\n2, [3,\n// four\n4]);",
+			"puts(\n\t1, // why\n\t2,\n\t[\n\t\t3,\n\t\t// four\n\t\t4\n\t]\n);\n",
+		},
+		{
+			"map(xs, fn(x) {\n// keeps the call flat\nx\n});",
+			"map(xs, fn(x) {\n\t// keeps the call flat\n\tx;\n});\n",
+		},
+		{
+			"if (x) { 1 } // then\nelse { 2 }",
+			"if (x) {\n\t1;\n} // then\nelse {\n\t2;\n}\n",
+		},
+		{
+			"if (x) { 1 }\n// otherwise\nelse { 2 }",
+			"if (x) {\n\t1;\n}\n// otherwise\nelse {\n\t2;\n}\n",
+		},
+	}
+
+	for i, test := range tests {
+		actual, err := Source([]byte(test.input))
+		if err != nil {
+			t.Fatalf("test[%d] - Source() ==> unexpected error: %s", i, err)
+		}
+		if string(actual) != test.expected {
+			t.Errorf("test[%d] - Source() ==> expected: <%q> but was: <%q>", i, test.expected, string(actual))
+		}
+		if twice, _ := Source(actual); string(twice) != string(actual) {
+			t.Errorf("test[%d] - Source(Source()) ==> expected: <%q> but was: <%q>", i, string(actual), string(twice))
+		}
+	}
+}
+
+func TestSourceWrapping(t *testing.T) {
+	input := `let xs = [1, 2, 3];
+let ys = ["a long string value", "another long string value", "and one more"];
+puts("a long string value", "another long string value", "and one more", 1);`
+	expected := `let xs = [1, 2, 3];
+let ys = [
+	"a long string value",
+	"another long string value",
+	"and one more"
+];
+puts(
+	"a long string value",
+	"another long string value",
+	"and one more",
+	1
+);
+`
+
+	actual, err := SourceWithOptions([]byte(input), Options{Width: 60})
+	if err != nil {
+		t.Fatalf("SourceWithOptions() ==> unexpected error: %s", err)
+	}
+	if string(actual) != expected {
+		t.Fatalf("SourceWithOptions() ==> expected: <%q> but was: <%q>", expected, string(actual))
+	}
+}
+
+func TestSourceIdempotent(t *testing.T) {
+	inputs := []string{
+		"let add=fn(a,b){a+b};   // adds\nlet r = add(1,2);",
+		`let h={"a":1,"c":fn(x){ if(x>1){return x*(2+3)} else { -x } }};`,
+		"macro unless(c, a, b) { quote(if (!(unquote(c))) { unquote(a) } else { unquote(b) }) };",
+		"// only a comment\n",
+		"if (true) { 1 };\n(1 + 2) * 3;",
+	}
+
+	for i, input := range inputs {
+		once, err := Source([]byte(input))
+		if err != nil {
+			t.Fatalf("test[%d] - Source() ==> unexpected error: %s", i, err)
+		}
+		twice, err := Source(once)
+		if err != nil {
+			t.Fatalf("test[%d] - Source() ==> unexpected error: %s", i, err)
+		}
+		if string(once) != string(twice) {
+			t.Errorf("test[%d] - Source() ==> expected: <%q> but was: <%q>", i, string(once), string(twice))
+		}
+	}
+}
+
+func TestSourceParseError(t *testing.T) {
+	_, err := Source([]byte("let = 5;"))
+	if _, ok := err.(*ParseError); !ok {
+		t.Fatalf("Source() ==> expected: <*formatter.ParseError> but was: <%T>", err)
+	}
+}
@@ -11,14 +11,25 @@ type Lexer struct {
 	start   int
 	current int
 
+	line   int
+	column int
+	pos    token.Position
+
 	ch  byte
 	eof bool
 
-	tokens []token.Token
+	tokens   []token.Token
+	comments []token.Token
 }
 
 func NewLexer(input string) *Lexer {
-	return &Lexer{input: input, tokens: []token.Token{}}
+	return NewLexerAtLine(input, 1)
+}
+
+// NewLexerAtLine numbers the lines of input starting at line, for input that
+// continues an earlier chunk of source such as a REPL session.
+func NewLexerAtLine(input string, line int) *Lexer {
+	return &Lexer{input: input, line: line, column: 1, tokens: []token.Token{}, comments: []token.Token{}}
 }
 
 func (l *Lexer) BufferLength() int {
@@ -47,8 +58,12 @@ func (l *Lexer) ensure(n int) bool {
 	return true
 }
 
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
 func (l *Lexer) Tokens() []token.Token {
-	for l.tokens[len(l.tokens)-1].Type != token.EOF {
+	for len(l.tokens) == 0 || l.tokens[len(l.tokens)-1].Type != token.EOF {
 		l.NextToken()
 	}
 	return l.tokens
@@ -60,7 +75,7 @@ func (l *Lexer) NextToken() token.Token {
 	}
 
 	for !l.isEOF() {
-		l.start = l.current
+		l.mark()
 
 		l.ch = l.peek0()
 		switch l.ch {
@@ -86,10 +101,17 @@ func (l *Lexer) NextToken() token.Token {
 			return l.emit(token.BANG)
 		case '/':
 			l.next()
+			if l.ch == '/' {
+				l.comment()
+				continue
+			}
 			return l.emit(token.SLASH)
 		case '*':
 			l.next()
 			return l.emit(token.STAR)
+		case '%':
+			l.next()
+			return l.emit(token.PERCENT)
 		case '<':
 			l.next()
 			return l.emit(token.LT)
@@ -137,7 +159,7 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	}
 
-	l.start = l.current
+	l.mark()
 	l.eof = true
 	return l.emit(token.EOF)
 }
@@ -148,8 +170,9 @@ func (l *Lexer) ident() token.Token {
 	}
 	ident := l.input[l.start:l.current]
 	tok := token.Token{
-		Type:    token.LookupIdent(ident),
-		Literal: ident,
+		Type:     token.LookupIdent(ident),
+		Literal:  ident,
+		Position: l.pos,
 	}
 	l.tokens = append(l.tokens, tok)
 	return tok
@@ -160,11 +183,13 @@ func (l *Lexer) number() token.Token {
 		l.next()
 	}
 
+	typ := token.INT
 	if l.ch == '.' {
 		if !isNumber(l.peek1()) {
 			return token.Token{
-				Type:    token.ILLEGAL,
-				Literal: l.input[l.start:l.current],
+				Type:     token.ILLEGAL,
+				Literal:  l.input[l.start:l.current],
+				Position: l.pos,
 			}
 		}
 
@@ -172,11 +197,18 @@ func (l *Lexer) number() token.Token {
 		for isNumber(l.ch) {
 			l.next()
 		}
+		typ = token.FLOAT
+	}
+
+	if l.ch == 'd' && !isAlphaNumeric(l.peek1()) {
+		l.next()
+		typ = token.DECIMAL
 	}
 
 	tok := token.Token{
-		Type:    token.NUMBER,
-		Literal: l.input[l.start:l.current],
+		Type:     typ,
+		Literal:  l.input[l.start:l.current],
+		Position: l.pos,
 	}
 	l.tokens = append(l.tokens, tok)
 	return tok
@@ -190,25 +222,39 @@ func (l *Lexer) string() token.Token {
 
 	if l.ch != '"' {
 		return token.Token{
-			Type:    token.ILLEGAL,
-			Literal: l.input[l.start:l.current],
+			Type:     token.ILLEGAL,
+			Literal:  l.input[l.start:l.current],
+			Position: l.pos,
 		}
 	}
 
 	l.next()
 
 	tok := token.Token{
-		Type:    token.STRING,
-		Literal: l.input[l.start:l.current],
+		Type:     token.STRING,
+		Literal:  l.input[l.start:l.current],
+		Position: l.pos,
 	}
 	l.tokens = append(l.tokens, tok)
 	return tok
 }
 
+func (l *Lexer) comment() {
+	for l.ch != '\n' && !l.isEOF() {
+		l.next()
+	}
+	l.comments = append(l.comments, token.Token{
+		Type:     token.COMMENT,
+		Literal:  l.input[l.start:l.current],
+		Position: l.pos,
+	})
+}
+
 func (l *Lexer) emit(ttype token.TokenType) token.Token {
 	tok := token.Token{
-		Type:    ttype,
-		Literal: l.input[l.start:l.current],
+		Type:     ttype,
+		Literal:  l.input[l.start:l.current],
+		Position: l.pos,
 	}
 	l.tokens = append(l.tokens, tok)
 	return tok
@@ -247,6 +293,13 @@ func (l *Lexer) next() {
 		return
 	}
 
+	if l.input[l.current] == '\n' {
+		l.line += 1
+		l.column = 1
+	} else {
+		l.column += 1
+	}
+
 	l.current += 1
 	if l.isEOF() {
 		l.ch = 0
@@ -255,6 +308,11 @@ func (l *Lexer) next() {
 	}
 }
 
+func (l *Lexer) mark() {
+	l.start = l.current
+	l.pos = token.Position{Offset: l.current, Line: l.line, Column: l.column}
+}
+
 func (l *Lexer) isEOF() bool {
 	return l.current >= len(l.input)
 }
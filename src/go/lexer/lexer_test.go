@@ -39,12 +39,12 @@ func TestNextToken(t *testing.T) {
 				{Type: token.LET, Literal: "let"},
 				{Type: token.IDENT, Literal: "five"},
 				{Type: token.ASSIGN, Literal: "="},
-				{Type: token.NUMBER, Literal: "5"},
+				{Type: token.INT, Literal: "5"},
 				{Type: token.SEMI, Literal: ";"},
 				{Type: token.LET, Literal: "let"},
 				{Type: token.IDENT, Literal: "ten"},
 				{Type: token.ASSIGN, Literal: "="},
-				{Type: token.NUMBER, Literal: "10"},
+				{Type: token.INT, Literal: "10"},
 				{Type: token.SEMI, Literal: ";"},
 				{Type: token.LET, Literal: "let"},
 				{Type: token.IDENT, Literal: "add"},
@@ -82,7 +82,7 @@ func TestNextToken(t *testing.T) {
 				{Type: token.MINUS, Literal: "-"},
 				{Type: token.SLASH, Literal: "/"},
 				{Type: token.STAR, Literal: "*"},
-				{Type: token.NUMBER, Literal: "5"},
+				{Type: token.INT, Literal: "5"},
 				{Type: token.SEMI, Literal: ";"},
 				{Type: token.EOF, Literal: ""},
 			},
@@ -90,11 +90,11 @@ func TestNextToken(t *testing.T) {
 		{
 			input: `5 < 10 > 5;`,
 			tokens: []token.Token{
-				{Type: token.NUMBER, Literal: "5"},
+				{Type: token.INT, Literal: "5"},
 				{Type: token.LT, Literal: "<"},
-				{Type: token.NUMBER, Literal: "10"},
+				{Type: token.INT, Literal: "10"},
 				{Type: token.GT, Literal: ">"},
-				{Type: token.NUMBER, Literal: "5"},
+				{Type: token.INT, Literal: "5"},
 				{Type: token.SEMI, Literal: ";"},
 				{Type: token.EOF, Literal: ""},
 			},
@@ -109,9 +109,9 @@ func TestNextToken(t *testing.T) {
 			tokens: []token.Token{
 				{Type: token.IF, Literal: "if"},
 				{Type: token.LPAREN, Literal: "("},
-				{Type: token.NUMBER, Literal: "5"},
+				{Type: token.INT, Literal: "5"},
 				{Type: token.LT, Literal: "<"},
-				{Type: token.NUMBER, Literal: "10"},
+				{Type: token.INT, Literal: "10"},
 				{Type: token.RPAREN, Literal: ")"},
 				{Type: token.LBRACE, Literal: "{"},
 				{Type: token.RETURN, Literal: "return"},
@@ -132,13 +132,13 @@ func TestNextToken(t *testing.T) {
 			10 == 10;
 			10 != 9;`,
 			tokens: []token.Token{
-				{Type: token.NUMBER, Literal: "10"},
+				{Type: token.INT, Literal: "10"},
 				{Type: token.EQ, Literal: "=="},
-				{Type: token.NUMBER, Literal: "10"},
+				{Type: token.INT, Literal: "10"},
 				{Type: token.SEMI, Literal: ";"},
-				{Type: token.NUMBER, Literal: "10"},
+				{Type: token.INT, Literal: "10"},
 				{Type: token.NOT_EQ, Literal: "!="},
-				{Type: token.NUMBER, Literal: "9"},
+				{Type: token.INT, Literal: "9"},
 				{Type: token.SEMI, Literal: ";"},
 				{Type: token.EOF, Literal: ""},
 			},
@@ -157,14 +157,32 @@ func TestNextToken(t *testing.T) {
 			input: `[1, 2];`,
 			tokens: []token.Token{
 				{Type: token.LBRACK, Literal: "["},
-				{Type: token.NUMBER, Literal: "1"},
+				{Type: token.INT, Literal: "1"},
 				{Type: token.COMMA, Literal: ","},
-				{Type: token.NUMBER, Literal: "2"},
+				{Type: token.INT, Literal: "2"},
 				{Type: token.RBRACK, Literal: "]"},
 				{Type: token.SEMI, Literal: ";"},
 				{Type: token.EOF, Literal: ""},
 			},
 		},
+		{
+			input: `12.50d 3d do`,
+			tokens: []token.Token{
+				{Type: token.DECIMAL, Literal: "12.50d"},
+				{Type: token.DECIMAL, Literal: "3d"},
+				{Type: token.IDENT, Literal: "do"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
+		{
+			input: `7 % 2.5`,
+			tokens: []token.Token{
+				{Type: token.INT, Literal: "7"},
+				{Type: token.PERCENT, Literal: "%"},
+				{Type: token.FLOAT, Literal: "2.5"},
+				{Type: token.EOF, Literal: ""},
+			},
+		},
 		{
 			input: `{"foo": "bar"}`,
 			tokens: []token.Token{
@@ -349,3 +367,114 @@ func TestTokenLookahead(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPosition(t *testing.T) {
+	input := "let x = 5;\n  x + \"a\";"
+	expected := []token.Position{
+		{Offset: 0, Line: 1, Column: 1},
+		{Offset: 4, Line: 1, Column: 5},
+		{Offset: 6, Line: 1, Column: 7},
+		{Offset: 8, Line: 1, Column: 9},
+		{Offset: 9, Line: 1, Column: 10},
+		{Offset: 13, Line: 2, Column: 3},
+		{Offset: 15, Line: 2, Column: 5},
+		{Offset: 17, Line: 2, Column: 7},
+		{Offset: 20, Line: 2, Column: 10},
+		{Offset: 21, Line: 2, Column: 11},
+	}
+
+	l := NewLexer(input)
+	for i, position := range expected {
+		actual := l.NextToken()
+		if position != actual.Position {
+			t.Errorf("test[%d] - %q.Position ==> expected: <%s> but was: <%s>", i, actual.Literal, position, actual.Position)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		input    string
+		tokens   []token.Token
+		comments []token.Token
+	}{
+		{
+			input: "let x = 1; // one\nx",
+			tokens: []token.Token{
+				{Type: token.LET, Literal: "let"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.ASSIGN, Literal: "="},
+				{Type: token.INT, Literal: "1"},
+				{Type: token.SEMI, Literal: ";"},
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.EOF, Literal: ""},
+			},
+			comments: []token.Token{
+				{Type: token.COMMENT, Literal: "// one", Position: token.Position{Offset: 11, Line: 1, Column: 12}},
+			},
+		},
+		{
+			input: "// first\n// second\n4 / 2",
+			tokens: []token.Token{
+				{Type: token.INT, Literal: "4"},
+				{Type: token.SLASH, Literal: "/"},
+				{Type: token.INT, Literal: "2"},
+				{Type: token.EOF, Literal: ""},
+			},
+			comments: []token.Token{
+				{Type: token.COMMENT, Literal: "// first", Position: token.Position{Offset: 0, Line: 1, Column: 1}},
+				{Type: token.COMMENT, Literal: "// second", Position: token.Position{Offset: 9, Line: 2, Column: 1}},
+			},
+		},
+		{
+			input: "x // end",
+			tokens: []token.Token{
+				{Type: token.IDENT, Literal: "x"},
+				{Type: token.EOF, Literal: ""},
+			},
+			comments: []token.Token{
+				{Type: token.COMMENT, Literal: "// end", Position: token.Position{Offset: 2, Line: 1, Column: 3}},
+			},
+		},
+		{
+			input: "//",
+			tokens: []token.Token{
+				{Type: token.EOF, Literal: ""},
+			},
+			comments: []token.Token{
+				{Type: token.COMMENT, Literal: "//", Position: token.Position{Offset: 0, Line: 1, Column: 1}},
+			},
+		},
+		{
+			input: `"a // b"; "//"`,
+			tokens: []token.Token{
+				{Type: token.STRING, Literal: `"a // b"`},
+				{Type: token.SEMI, Literal: ";"},
+				{Type: token.STRING, Literal: `"//"`},
+				{Type: token.EOF, Literal: ""},
+			},
+			comments: []token.Token{},
+		},
+	}
+
+	for i, test := range tests {
+		l := NewLexer(test.input)
+		for j, expected := range test.tokens {
+			actual := l.NextToken()
+			if expected.Type != actual.Type || expected.Literal != actual.Literal {
+				t.Errorf("test[%d][%d] - NextToken() ==> expected: <%s %q> but was: <%s %q>", i, j, expected.Type, expected.Literal, actual.Type, actual.Literal)
+			}
+		}
+
+		comments := l.Comments()
+		if len(test.comments) != len(comments) {
+			t.Errorf("test[%d] - len(Comments()) ==> expected: <%d> but was: <%d>", i, len(test.comments), len(comments))
+			continue
+		}
+		for j, expected := range test.comments {
+			if expected != comments[j] {
+				t.Errorf("test[%d][%d] - Comments() ==> expected: <%+v> but was: <%+v>", i, j, expected, comments[j])
+			}
+		}
+	}
+}
@@ -1,14 +1,45 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"os/user"
 
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/repl"
 )
 
+var commands = map[string]func(args []string) int{
+	"fmt":   format,
+	"ast":   dumpAST,
+	"run":   run,
+	"test":  test,
+	"debug": debug,
+	"dap":   debugAdapter,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			os.Exit(command(os.Args[2:]))
+		}
+	}
+
+	name := flag.String("engine", engine.Default, "execution engine: eval or vm")
+	flag.Parse()
+
+	e, err := engine.New(*name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if eval, ok := e.(*engine.Evaluator); ok {
+		eval.SetModulePath(append([]string{"."}, evaluator.SearchPath(os.Getenv("MONKEYPATH"))...))
+	}
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -19,5 +50,5 @@ func main() {
 		user.Username,
 	)
 	fmt.Printf("Feel free to type in commands\n")
-	repl.Start(os.Stdin, os.Stdout)
+	repl.StartWithEngine(os.Stdin, os.Stdout, e)
 }
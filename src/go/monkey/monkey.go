@@ -0,0 +1,204 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+// ParseError holds every message of a source that did not parse.
+type ParseError struct {
+	Messages []string
+}
+
+func (pe *ParseError) Error() string {
+	return "parser errors:\n\t" + strings.Join(pe.Messages, "\n\t")
+}
+
+// Interpreter evaluates Monkey sources for a Go host. Each Interpreter owns
+// its globals, macros and registered functions, which persist from one run
+// to the next; it is not safe for concurrent use.
+type Interpreter struct {
+	builtins *object.Environment
+	globals  *object.Environment
+	macros   *object.Environment
+
+	converter object.Converter
+	running   int
+}
+
+func New() *Interpreter {
+	builtins := object.NewEnvironment(nil)
+	// Macros expand under the runtime of the globals, so its context and
+	// limits bound the expansion as well.
+	macros := object.NewEnvironment(nil)
+	macros.Runtime = builtins.Runtime
+	i := &Interpreter{
+		builtins: builtins,
+		globals:  object.NewEnvironment(builtins),
+		macros:   macros,
+	}
+	i.converter = object.Converter{Call: i.call}
+	return i
+}
+
+// SetLimits bounds every later run and call.
+func (i *Interpreter) SetLimits(limits object.Limits) {
+	i.globals.Runtime.Limits = limits
+}
+
+// SetModulePath lets later runs import modules from the directories of
+// path, in order. Imports fail until it is called.
+func (i *Interpreter) SetModulePath(path ...string) {
+	i.globals.Runtime.Importer = evaluator.NewLoader(path...)
+}
+
+// Run parses src, expands its macros and evaluates it in the globals,
+// returning the value of its last statement. Expansion and evaluation stop
+// with an *object.LimitError once ctx is done. A panic while running is
+// returned as an error rather than raised in the host.
+func (i *Interpreter) Run(ctx context.Context, src string) (object.Object, error) {
+	program, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+	return i.run(ctx, program)
+}
+
+// Eval evaluates a single expression in the globals.
+func (i *Interpreter) Eval(expr string) (object.Object, error) {
+	program, err := parse(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(program.Statements) != 1 {
+		return nil, errors.New("not an expression: " + expr)
+	}
+	if _, ok := program.Statements[0].(*ast.ExpressionStatement); !ok {
+		return nil, errors.New("not an expression: " + expr)
+	}
+	return i.run(context.Background(), program)
+}
+
+func (i *Interpreter) run(ctx context.Context, program *ast.Program) (value object.Object, err error) {
+	defer recovered(&err)
+
+	i.running += 1
+	defer func() { i.running -= 1 }()
+	var node ast.Node
+	var interrupt object.Interruption
+	defined := evaluator.DefineMacros(program, i.macros)
+	if i.running > 1 {
+		if node, interrupt = evaluator.ExpandMacros(defined, i.macros); interrupt == nil {
+			value, interrupt = evaluator.Evaluate(node, i.globals)
+		}
+	} else {
+		if node, interrupt = evaluator.ExpandMacrosContext(ctx, defined, i.macros); interrupt == nil {
+			value, interrupt = evaluator.EvaluateContext(ctx, node, i.globals)
+		}
+	}
+	return value, toError(interrupt)
+}
+
+// SetGlobal binds name to value in the globals, converting it with
+// object.FromGo.
+func (i *Interpreter) SetGlobal(name string, value any) error {
+	converted, err := i.converter.FromGo(value)
+	if err != nil {
+		return err
+	}
+	i.globals.Set(name, converted)
+	return nil
+}
+
+// GetGlobal returns the value name is bound to in the globals; registered
+// functions and builtins are not globals.
+func (i *Interpreter) GetGlobal(name string) (object.Object, bool) {
+	value, ok := i.globals.Values[name]
+	return value, ok
+}
+
+// RegisterFunc makes the Go func fn callable from Monkey as name, shadowing
+// a builtin of the same name; globals shadow it in turn. Its arguments and
+// results are converted as by object.FromGo and object.ToGo, and an error it
+// returns last is raised in the script.
+func (i *Interpreter) RegisterFunc(name string, fn any) error {
+	builtin, err := i.converter.FromGo(fn)
+	if err != nil {
+		return err
+	}
+	if builtin.Type() != object.BUILTIN {
+		return fmt.Errorf("RegisterFunc(%s) ==> expected a func but was: %T", name, fn)
+	}
+	i.builtins.Set(name, builtin)
+	return nil
+}
+
+// Call calls fn, a Monkey function or builtin, with args converted by
+// object.FromGo. Called from a registered function, it runs within the
+// evaluation that called it.
+func (i *Interpreter) Call(fn object.Object, args ...any) (object.Object, error) {
+	objects := make([]object.Object, len(args))
+	for j, arg := range args {
+		converted, err := i.converter.FromGo(arg)
+		if err != nil {
+			return nil, err
+		}
+		objects[j] = converted
+	}
+	return i.call(fn, objects...)
+}
+
+func (i *Interpreter) call(fn object.Object, args ...object.Object) (value object.Object, err error) {
+	defer recovered(&err)
+
+	i.running += 1
+	defer func() { i.running -= 1 }()
+	var interrupt object.Interruption
+	if i.running > 1 {
+		value, interrupt = evaluator.Apply(fn, args, i.globals)
+	} else {
+		value, interrupt = evaluator.ApplyContext(context.Background(), fn, args, i.globals)
+	}
+	return value, toError(interrupt)
+}
+
+// ToGo converts obj into what target points to as object.ToGo does, and
+// Monkey functions into Go funcs that call them through the interpreter.
+func (i *Interpreter) ToGo(obj object.Object, target any) error {
+	return i.converter.ToGo(obj, target)
+}
+
+func parse(src string) (*ast.Program, error) {
+	p := parser.NewParser(src, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, &ParseError{Messages: p.Errors()}
+	}
+	return program, nil
+}
+
+// recovered turns a panic of the run or call that defers it into its error,
+// so a script cannot take the host down with it.
+func recovered(err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("internal error: %v", r)
+	}
+}
+
+func toError(interrupt object.Interruption) error {
+	switch interrupt := interrupt.(type) {
+	case nil:
+		return nil
+	case error:
+		return interrupt
+	default:
+		return errors.New(interrupt.Inspect())
+	}
+}
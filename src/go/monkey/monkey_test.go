@@ -0,0 +1,313 @@
+package monkey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      string
+	}{
+		{"1 + 2", "3", ""},
+		{"let x = 5; x * 2", "10", ""},
+		{"let f = fn(n) { if (n < 1) { return 0; } n + f(n - 1) }; f(4)", "10", ""},
+		{"macro twice(x) { quote(unquote(x) + unquote(x)) }; twice(21)", "42", ""},
+		{"1 + true", "", "type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn(a, b) { a }; f(1)", "", "wrong number of arguments: want=2, got=1"},
+		{"macro m() { 1 }; m()", "", "unsupported type returned from macro expansion: INTEGER"},
+		{"macro m(x) { x }; m()", "", "wrong number of arguments: want=1, got=0"},
+		{"let = 1", "", "parser errors:\n\texpected next token to be <IDENT> but was <ASSIGN>\n\tno prefix parse function defined for ASSIGN"},
+	}
+
+	for i, test := range tests {
+		value, err := New().Run(context.Background(), test.input)
+		if test.err != "" {
+			if err == nil || test.err != err.Error() {
+				t.Errorf("test[%d] - Run() ==> expected: <%s> but was: <%v>", i, test.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("test[%d] - Run() ==> unexpected error: %v", i, err)
+			continue
+		}
+		if test.expected != value.Inspect() {
+			t.Errorf("test[%d] - Run() ==> expected: <%s> but was: <%s>", i, test.expected, value.Inspect())
+		}
+	}
+}
+
+func TestRunRecovers(t *testing.T) {
+	interpreter := New()
+	interpreter.RegisterFunc("explode", func(args ...object.Object) (object.Object, error) {
+		panic("boom")
+	})
+
+	if _, err := interpreter.Run(context.Background(), "explode()"); err == nil || err.Error() != "internal error: boom" {
+		t.Errorf("Run() ==> expected: <internal error: boom> but was: <%v>", err)
+	}
+	explode, _ := interpreter.builtins.Get("explode")
+	if _, err := interpreter.Call(explode); err == nil || err.Error() != "internal error: boom" {
+		t.Errorf("Call() ==> expected: <internal error: boom> but was: <%v>", err)
+	}
+	if value, err := interpreter.Eval("1 + 1"); err != nil || value != object.Integer(2) {
+		t.Errorf("Eval() ==> expected: <2> but was: <%v %v>", value, err)
+	}
+}
+
+func TestRunMacroLimits(t *testing.T) {
+	loop := "macro spin() { let f = fn(n) { f(n + 1) }; f(0) }; spin()"
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := New().Run(ctx, loop); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() ==> expected: <%s> but was: <%v>", context.DeadlineExceeded, err)
+	}
+
+	interpreter := New()
+	interpreter.SetLimits(object.Limits{MaxSteps: 1000})
+	var limitError *object.LimitError
+	if _, err := interpreter.Run(context.Background(), loop); !errors.As(err, &limitError) || limitError.Limit != object.STEP_LIMIT {
+		t.Errorf("Run() ==> expected: <%s> but was: <%v>", object.STEP_LIMIT, err)
+	}
+	for i := 0; i < 3; i += 1 {
+		if _, err := interpreter.Run(context.Background(), "macro one() { quote(1) }; one()"); err != nil {
+			t.Errorf("test[%d] - Run() ==> unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestGlobals(t *testing.T) {
+	interpreter := New()
+	interpreter.SetGlobal("greeting", object.String("hello"))
+	if _, err := interpreter.Run(context.Background(), `let message = greeting + ", world"; macro twice(x) { quote(unquote(x) * 2) };`); err != nil {
+		t.Fatalf("Run() ==> %v", err)
+	}
+
+	message, ok := interpreter.GetGlobal("message")
+	if !ok || message.Inspect() != `"hello, world"` {
+		t.Errorf("GetGlobal(message) ==> expected: <\"hello, world\"> but was: <%v>", message)
+	}
+	if _, ok := interpreter.GetGlobal("len"); ok {
+		t.Errorf("GetGlobal(len) ==> expected: <false> but was: <true>")
+	}
+
+	value, err := interpreter.Eval(`twice(len(message))`)
+	if err != nil || value.Inspect() != "24" {
+		t.Errorf("Eval() ==> expected: <24> but was: <%v %v>", value, err)
+	}
+	if _, err := interpreter.Eval(`let y = 1;`); err == nil {
+		t.Errorf("Eval() ==> expected an error for a statement")
+	}
+
+	other := New()
+	if _, err := other.Eval("message"); err == nil || err.Error() != "unknown identifier: message" {
+		t.Errorf("Eval() ==> expected: <unknown identifier: message> but was: <%v>", err)
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	dir := t.TempDir()
+	src := `export let double = fn(x) { x * 2 }; export macro twice(x) { quote(unquote(x) + unquote(x)) };`
+	if err := os.WriteFile(filepath.Join(dir, "math.mk"), []byte(src), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	interpreter := New()
+	if _, err := interpreter.Run(context.Background(), `import "math.mk" as m;`); err == nil || err.Error() != `cannot import "math.mk": no module search path is set` {
+		t.Errorf("Run() ==> expected: <cannot import \"math.mk\": no module search path is set> but was: <%v>", err)
+	}
+
+	interpreter.SetModulePath(dir)
+	if _, err := interpreter.Run(context.Background(), `import "math.mk" as m;`); err != nil {
+		t.Fatalf("Run() ==> %v", err)
+	}
+	value, err := interpreter.Eval(`m["twice"](m["double"](5))`)
+	if err != nil || value.Inspect() != "20" {
+		t.Errorf("Eval() ==> expected: <20> but was: <%v %v>", value, err)
+	}
+}
+
+func TestRegisterFunc(t *testing.T) {
+	interpreter := New()
+	calls := 0
+	interpreter.RegisterFunc("add", func(args ...object.Object) (object.Object, error) {
+		calls += 1
+		sum := object.Integer(0)
+		for _, arg := range args {
+			n, ok := arg.(object.Integer)
+			if !ok {
+				return nil, fmt.Errorf("add: not an integer: %s", arg.Inspect())
+			}
+			sum += n
+		}
+		return sum, nil
+	})
+	interpreter.RegisterFunc("len", func(args ...object.Object) (object.Object, error) {
+		return object.Integer(-1), nil
+	})
+	interpreter.RegisterFunc("nothing", func(args ...object.Object) (object.Object, error) {
+		return nil, nil
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"add(1, 2, 3)", "6"},
+		{"let f = fn(x) { add(x, x) }; f(4)", "8"},
+		{"len([1])", "-1"},
+		{"nothing()", "null"},
+		{`add(1, "2")`, "ERROR: add: not an integer: \"2\""},
+	}
+	for i, test := range tests {
+		value, err := interpreter.Run(context.Background(), test.input)
+		actual := ""
+		if err != nil {
+			actual = "ERROR: " + err.Error()
+		} else {
+			actual = value.Inspect()
+		}
+		if test.expected != actual {
+			t.Errorf("test[%d] - Run() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("calls ==> expected: <3> but was: <%d>", calls)
+	}
+
+	if _, err := New().Eval("add(1, 2)"); err == nil {
+		t.Errorf("Eval() ==> expected add to be unknown to another interpreter")
+	}
+}
+
+func TestCall(t *testing.T) {
+	interpreter := New()
+	_, err := interpreter.Run(context.Background(), `
+let counter = {"n": 0};
+let inc = fn(by) { counter["n"] = counter["n"] + by; counter["n"] };
+let fact = fn(n, acc) { if (n < 2) { acc } else { fact(n - 1, acc * n) } };
+`)
+	if err != nil {
+		t.Fatalf("Run() ==> %v", err)
+	}
+
+	inc, _ := interpreter.GetGlobal("inc")
+	for i := 1; i <= 3; i += 1 {
+		value, err := interpreter.Call(inc, object.Integer(2))
+		if err != nil || value != object.Integer(2*i) {
+			t.Errorf("test[%d] - Call(inc) ==> expected: <%d> but was: <%v %v>", i, 2*i, value, err)
+		}
+	}
+
+	fact, _ := interpreter.GetGlobal("fact")
+	args := []any{object.Integer(5), object.Integer(1), object.Integer(99)}
+	value, err := interpreter.Call(fact, args[:2]...)
+	if err != nil || value != object.Integer(120) || args[2] != object.Integer(99) {
+		t.Errorf("Call(fact) ==> expected: <120> but was: <%v %v %v>", value, err, args)
+	}
+
+	tests := []struct {
+		fn   object.Object
+		args []any
+		err  string
+	}{
+		{inc, nil, "wrong number of arguments: want=1, got=0"},
+		{inc, []any{true}, "type mismatch: INTEGER + BOOLEAN"},
+		{object.Integer(1), nil, "not a function: INTEGER"},
+	}
+	for i, test := range tests {
+		if _, err := interpreter.Call(test.fn, test.args...); err == nil || test.err != err.Error() {
+			t.Errorf("test[%d] - Call() ==> expected: <%s> but was: <%v>", i, test.err, err)
+		}
+	}
+}
+
+func TestCallback(t *testing.T) {
+	interpreter := New()
+	interpreter.RegisterFunc("each", func(args ...object.Object) (object.Object, error) {
+		array := args[0].(*object.Array)
+		results := []object.Object{}
+		for _, element := range array.Elements {
+			value, err := interpreter.Call(args[1], element)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, value)
+		}
+		return &object.Array{Elements: results}, nil
+	})
+
+	value, err := interpreter.Run(context.Background(), "each([1, 2, 3], fn(x) { x * x })")
+	if err != nil || value.Inspect() != "[1, 4, 9]" {
+		t.Errorf("Run() ==> expected: <[1, 4, 9]> but was: <%v %v>", value, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = interpreter.Run(ctx, "let loop = fn(x) { loop(x) }; each([1], loop)")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Run() ==> expected: <%s> but was: <%v>", context.DeadlineExceeded, err)
+	}
+}
+
+func TestConversions(t *testing.T) {
+	type user struct {
+		Name  string   `monkey:"name"`
+		Roles []string `monkey:"roles"`
+	}
+
+	interpreter := New()
+	if err := interpreter.SetGlobal("admin", user{Name: "ada", Roles: []string{"admin", "dev"}}); err != nil {
+		t.Fatalf("SetGlobal() ==> %v", err)
+	}
+	if err := interpreter.RegisterFunc("greet", func(u user, punctuation string) string {
+		return "hello " + u.Name + punctuation
+	}); err != nil {
+		t.Fatalf("RegisterFunc() ==> %v", err)
+	}
+	if err := interpreter.RegisterFunc("greeting", "hello"); err == nil {
+		t.Errorf("RegisterFunc() ==> expected an error for a string")
+	}
+
+	value, err := interpreter.Run(context.Background(), `
+let renamed = {"name": "bob", "roles": rest(admin["roles"])};
+let scale = fn(x, by) { x * by };
+[greet(admin, "!"), greet(renamed, "?")]`)
+	if err != nil {
+		t.Fatalf("Run() ==> %v", err)
+	}
+	greetings := []string{}
+	if err := interpreter.ToGo(value, &greetings); err != nil || fmt.Sprint(greetings) != "[hello ada! hello bob?]" {
+		t.Errorf("ToGo() ==> expected: <[hello ada! hello bob?]> but was: <%v %v>", greetings, err)
+	}
+
+	renamed, _ := interpreter.GetGlobal("renamed")
+	u := user{}
+	if err := interpreter.ToGo(renamed, &u); err != nil || u.Name != "bob" || fmt.Sprint(u.Roles) != "[dev]" {
+		t.Errorf("ToGo() ==> expected: <{bob [dev]}> but was: <%+v %v>", u, err)
+	}
+
+	scale, _ := interpreter.GetGlobal("scale")
+	var fn func(float64, int) (float64, error)
+	if err := interpreter.ToGo(scale, &fn); err != nil {
+		t.Fatalf("ToGo() ==> %v", err)
+	}
+	if result, err := fn(1.5, 4); result != 6 || err != nil {
+		t.Errorf("fn() ==> expected: <6> but was: <%v %v>", result, err)
+	}
+
+	if _, err := interpreter.Run(context.Background(), `greet(1, "!")`); err == nil || err.Error() != "cannot convert INTEGER to Go monkey.user at argument 1" {
+		t.Errorf("Run() ==> expected: <cannot convert INTEGER to Go monkey.user at argument 1> but was: <%v>", err)
+	}
+}
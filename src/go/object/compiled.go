@@ -0,0 +1,41 @@
+package object
+
+import (
+	"fmt"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/code"
+)
+
+type CompiledFunction struct {
+	Name         string
+	Literal      *ast.FunctionLiteral
+	Instructions code.Instructions
+	SourceMap    code.SourceMap
+
+	NumParameters int
+	NumLocals     int
+	NumCells      int
+
+	Locals   []string
+	Cells    []string
+	Captures []Capture
+}
+
+type Capture struct {
+	Cell  bool
+	Index int
+	Name  string
+}
+
+func (cf *CompiledFunction) Type() ObjectType {
+	return COMPILED_FUNCTION
+}
+
+func (cf *CompiledFunction) Inspect() string {
+	return fmt.Sprintf("<compiled fn %p>", cf)
+}
+
+type Cell struct {
+	Value Object
+}
@@ -0,0 +1,461 @@
+package object
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Tasks run concurrently on goroutines and share nothing mutable: a task
+// runs on a copy of its function, the environments it closes over and its
+// arguments, made when it is spawned, and values sent on channels or
+// returned by tasks are copied again when they are received or awaited.
+// Tasks and channels themselves are shared, and are how tasks communicate.
+// Iterators are shared too, but only the task that created one can advance
+// it.
+//
+// A Scheduler counts the tasks of an evaluation, the evaluation itself
+// included while it runs, and the ones blocked on a channel or a task. Once
+// every one of them is blocked none can ever be woken, so each is woken with
+// a deadlock error instead.
+
+const DEADLOCK_MESSAGE = "deadlock: all tasks are blocked"
+
+type Task struct {
+	ID int
+
+	scheduler *Scheduler
+	done      bool
+	value     Object
+	interrupt Interruption
+	waiters   []*waiter
+}
+
+func (t *Task) Type() ObjectType {
+	return TASK
+}
+
+func (t *Task) Inspect() string {
+	return fmt.Sprintf("<task %d>", t.ID)
+}
+
+type Channel struct {
+	Capacity int
+
+	scheduler *Scheduler
+	buffer    []Object
+	closed    bool
+	senders   []pending
+	receivers []pending
+}
+
+func (c *Channel) Type() ObjectType {
+	return CHANNEL
+}
+
+func (c *Channel) Inspect() string {
+	c.scheduler.mu.Lock()
+	defer c.scheduler.mu.Unlock()
+	return fmt.Sprintf("<channel %d/%d>", len(c.buffer), c.Capacity)
+}
+
+// A Case of a select sends Value on Channel when Send is set, and receives
+// from it otherwise.
+type Case struct {
+	Channel *Channel
+	Send    bool
+	Value   Object
+}
+
+// A waiter is a task blocked on the cases of a select or on a task, until
+// done is closed.
+type waiter struct {
+	done  chan struct{}
+	cases []Case
+
+	fired     bool
+	index     int
+	value     Object
+	ok        bool
+	interrupt Interruption
+}
+
+type pending struct {
+	waiter *waiter
+	index  int
+}
+
+type Scheduler struct {
+	mu      sync.Mutex
+	live    int
+	tasks   int
+	blocked map[*waiter]bool
+}
+
+// Scheduler returns the scheduler of the evaluation rt runs, creating it,
+// with that evaluation live, for its first task or channel.
+func (rt *Runtime) Scheduler() *Scheduler {
+	if rt.scheduler == nil {
+		rt.scheduler = &Scheduler{live: 1, blocked: map[*waiter]bool{}}
+	}
+	return rt.scheduler
+}
+
+// Enter and Leave bracket an evaluation on a runtime that already has a
+// scheduler, whose tasks may be waiting on it.
+func (rt *Runtime) Enter() {
+	if s := rt.scheduler; s != nil {
+		s.mu.Lock()
+		s.live += 1
+		s.mu.Unlock()
+	}
+}
+
+func (rt *Runtime) Leave() {
+	if s := rt.scheduler; s != nil {
+		s.mu.Lock()
+		s.live -= 1
+		s.detect()
+		s.mu.Unlock()
+	}
+}
+
+// Spawn runs run on a goroutine with a runtime of its own, which has the
+// limits, context, observer and importer of parent. The observer is shared
+// by taking turns, and the importer must be safe for concurrent use.
+func (s *Scheduler) Spawn(parent *Runtime, run func(rt *Runtime) (Object, Interruption)) *Task {
+	rt := NewRuntime(parent.Limits)
+	rt.Context = parent.Context
+	rt.Observer = share(parent)
+	rt.Importer = parent.Importer
+	rt.scheduler = s
+
+	s.mu.Lock()
+	s.live += 1
+	s.tasks += 1
+	task := &Task{ID: s.tasks, scheduler: s}
+	s.mu.Unlock()
+
+	go func() {
+		value, interrupt := run(rt)
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		task.done, task.value, task.interrupt = true, value, interrupt
+		for _, w := range task.waiters {
+			if !w.fired {
+				s.wake(w, 0, nil, true, nil)
+			}
+		}
+		task.waiters = nil
+		s.live -= 1
+		s.detect()
+	}()
+	return task
+}
+
+// Await blocks rt until task has finished and returns what it returned, or
+// the error it failed with as failure.
+func (s *Scheduler) Await(rt *Runtime, task *Task) (value Object, failure Interruption, interrupt Interruption) {
+	if task.scheduler != s {
+		return nil, nil, &Error{Message: "task belongs to another evaluation"}
+	}
+
+	s.mu.Lock()
+	if !task.done {
+		w := &waiter{done: make(chan struct{})}
+		task.waiters = append(task.waiters, w)
+		s.block(w)
+		s.mu.Unlock()
+
+		if interrupt := s.wait(rt, w); interrupt != nil {
+			return nil, nil, interrupt
+		}
+		if w.interrupt != nil {
+			return nil, nil, w.interrupt
+		}
+		s.mu.Lock()
+	}
+	value, failure = task.value, task.interrupt
+	s.mu.Unlock()
+
+	if failure != nil {
+		return nil, failure, nil
+	}
+	return Copy(value), nil, nil
+}
+
+func (s *Scheduler) NewChannel(capacity int) *Channel {
+	return &Channel{Capacity: capacity, scheduler: s}
+}
+
+// Close closes c; its receivers get null once its buffer is empty.
+func (s *Scheduler) Close(c *Channel) *Error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if c.closed {
+		return &Error{Message: "close of closed channel"}
+	}
+	c.closed = true
+	for len(c.receivers) != 0 {
+		s.wake(c.receivers[0].waiter, c.receivers[0].index, Nil, false, nil)
+	}
+	for len(c.senders) != 0 {
+		s.wake(c.senders[0].waiter, c.senders[0].index, nil, false, &Error{Message: "send on closed channel"})
+	}
+	return nil
+}
+
+// Select performs the first of cases that is ready, blocking rt until one
+// is unless block is false, and returns its index, the value it received
+// and whether that came from a send rather than a closed channel. It
+// returns an index of -1 when no case was ready and it did not block.
+func (s *Scheduler) Select(rt *Runtime, cases []Case, block bool) (int, Object, bool, Interruption) {
+	for i := range cases {
+		if cases[i].Channel.scheduler != s {
+			return 0, nil, false, &Error{Message: "channel belongs to another evaluation"}
+		}
+		if cases[i].Send {
+			cases[i].Value = Copy(cases[i].Value)
+		}
+	}
+
+	s.mu.Lock()
+	for i, c := range cases {
+		ch := c.Channel
+		if c.Send {
+			if ch.closed {
+				s.mu.Unlock()
+				return 0, nil, false, &Error{Message: "send on closed channel"}
+			}
+			if len(ch.receivers) != 0 {
+				receiver := ch.receivers[0]
+				s.wake(receiver.waiter, receiver.index, c.Value, true, nil)
+				s.mu.Unlock()
+				return i, Nil, true, nil
+			}
+			if len(ch.buffer) < ch.Capacity {
+				ch.buffer = append(ch.buffer, c.Value)
+				s.mu.Unlock()
+				return i, Nil, true, nil
+			}
+			continue
+		}
+
+		if len(ch.buffer) != 0 {
+			value := ch.buffer[0]
+			ch.buffer = ch.buffer[1:]
+			if len(ch.senders) != 0 {
+				sender := ch.senders[0]
+				ch.buffer = append(ch.buffer, sender.waiter.cases[sender.index].Value)
+				s.wake(sender.waiter, sender.index, Nil, true, nil)
+			}
+			s.mu.Unlock()
+			return i, value, true, nil
+		}
+		if len(ch.senders) != 0 {
+			sender := ch.senders[0]
+			value := sender.waiter.cases[sender.index].Value
+			s.wake(sender.waiter, sender.index, Nil, true, nil)
+			s.mu.Unlock()
+			return i, value, true, nil
+		}
+		if ch.closed {
+			s.mu.Unlock()
+			return i, Nil, false, nil
+		}
+	}
+
+	if !block {
+		s.mu.Unlock()
+		return -1, Nil, false, nil
+	}
+	w := &waiter{done: make(chan struct{}), cases: cases}
+	for i, c := range cases {
+		if c.Send {
+			c.Channel.senders = append(c.Channel.senders, pending{w, i})
+		} else {
+			c.Channel.receivers = append(c.Channel.receivers, pending{w, i})
+		}
+	}
+	s.block(w)
+	s.mu.Unlock()
+
+	if interrupt := s.wait(rt, w); interrupt != nil {
+		return 0, nil, false, interrupt
+	}
+	return w.index, w.value, w.ok, w.interrupt
+}
+
+// wait blocks until w is woken or the context of rt is done.
+func (s *Scheduler) wait(rt *Runtime, w *waiter) Interruption {
+	var cancelled <-chan struct{}
+	if rt.Context != nil {
+		cancelled = rt.Context.Done()
+	}
+	select {
+	case <-w.done:
+		return nil
+	case <-cancelled:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if w.fired {
+			return nil
+		}
+		s.wake(w, 0, nil, false, nil)
+		return rt.Cancelled()
+	}
+}
+
+func (s *Scheduler) block(w *waiter) {
+	s.blocked[w] = true
+	s.detect()
+}
+
+// detect wakes every blocked task with a deadlock error once no task is
+// left that could wake them.
+func (s *Scheduler) detect() {
+	if len(s.blocked) == 0 || len(s.blocked) < s.live {
+		return
+	}
+	for w := range s.blocked {
+		s.wake(w, 0, nil, false, &Error{Message: DEADLOCK_MESSAGE})
+	}
+}
+
+// wake completes w with the result of its case at index and withdraws its
+// other cases; it must be called with s.mu held.
+func (s *Scheduler) wake(w *waiter, index int, value Object, ok bool, interrupt Interruption) {
+	w.fired = true
+	w.index, w.value, w.ok, w.interrupt = index, value, ok, interrupt
+	for _, c := range w.cases {
+		c.Channel.senders = withdraw(c.Channel.senders, w)
+		c.Channel.receivers = withdraw(c.Channel.receivers, w)
+	}
+	delete(s.blocked, w)
+	close(w.done)
+}
+
+func withdraw(queue []pending, w *waiter) []pending {
+	kept := queue[:0]
+	for _, e := range queue {
+		if e.waiter != w {
+			kept = append(kept, e)
+		}
+	}
+	return kept
+}
+
+// Copy copies the mutable parts of obj, the arrays, hashes, modules,
+// functions and environments it reaches, preserving the sharing and cycles
+// between them.
+func Copy(obj Object) Object {
+	return CopyAll(obj)[0]
+}
+
+// CopyAll copies objects as Copy does, with the sharing between them kept.
+func CopyAll(objects ...Object) []Object {
+	c := &copier{envs: map[*Environment]*Environment{}, objects: map[Object]Object{}}
+	copies := make([]Object, len(objects))
+	for i, obj := range objects {
+		copies[i] = c.object(obj)
+	}
+	return copies
+}
+
+type copier struct {
+	envs    map[*Environment]*Environment
+	objects map[Object]Object
+	cells   map[*Cell]*Cell
+}
+
+func (c *copier) object(obj Object) Object {
+	switch obj := obj.(type) {
+	case *Array:
+		if copied, ok := c.objects[obj]; ok {
+			return copied
+		}
+		array := &Array{Elements: make([]Object, len(obj.Elements))}
+		c.objects[obj] = array
+		for i, element := range obj.Elements {
+			array.Elements[i] = c.object(element)
+		}
+		return array
+	case *Hash:
+		if copied, ok := c.objects[obj]; ok {
+			return copied
+		}
+		hash := &Hash{Pairs: make(map[HashKey]HashPair, len(obj.Pairs))}
+		c.objects[obj] = hash
+		for key, pair := range obj.Pairs {
+			hash.Pairs[key] = HashPair{Key: c.object(pair.Key), Value: c.object(pair.Value)}
+		}
+		return hash
+	case *Module:
+		if copied, ok := c.objects[obj]; ok {
+			return copied
+		}
+		module := &Module{Path: obj.Path, Members: make(map[string]Object, len(obj.Members))}
+		c.objects[obj] = module
+		for name, member := range obj.Members {
+			module.Members[name] = c.object(member)
+		}
+		return module
+	case *Function:
+		if copied, ok := c.objects[obj]; ok {
+			return copied
+		}
+		fn := &Function{Name: obj.Name, Literal: obj.Literal, Compiled: obj.Compiled}
+		c.objects[obj] = fn
+		fn.Closure = c.env(obj.Closure)
+		if obj.Free != nil {
+			fn.Free = make([]*Cell, len(obj.Free))
+			for i, cell := range obj.Free {
+				fn.Free[i] = c.cell(cell)
+			}
+		}
+		return fn
+	default:
+		return obj
+	}
+}
+
+func (c *copier) env(env *Environment) *Environment {
+	if env == nil {
+		return nil
+	}
+	if copied, ok := c.envs[env]; ok {
+		return copied
+	}
+	copied := &Environment{Runtime: env.Runtime, Names: env.Names, Quoting: env.Quoting}
+	c.envs[env] = copied
+	if env.Values != nil {
+		copied.Values = make(map[string]Object, len(env.Values))
+		for name, value := range env.Values {
+			copied.Values[name] = c.object(value)
+		}
+	}
+	if env.Slots != nil {
+		copied.Slots = make([]Object, len(env.Slots))
+		for i, value := range env.Slots {
+			copied.Slots[i] = c.object(value)
+		}
+	}
+	copied.Enclosing = c.env(env.Enclosing)
+	return copied
+}
+
+func (c *copier) cell(cell *Cell) *Cell {
+	if cell == nil {
+		return nil
+	}
+	if c.cells == nil {
+		c.cells = map[*Cell]*Cell{}
+	}
+	if copied, ok := c.cells[cell]; ok {
+		return copied
+	}
+	copied := &Cell{}
+	c.cells[cell] = copied
+	copied.Value = c.object(cell.Value)
+	return copied
+}
@@ -0,0 +1,120 @@
+package object
+
+import (
+	"testing"
+)
+
+func TestCopy(t *testing.T) {
+	shared := &Array{Elements: []Object{Integer(1)}}
+	cyclic := &Array{}
+	cyclic.Elements = []Object{cyclic, shared, shared}
+
+	globals := NewEnvironment(nil)
+	globals.Set("shared", shared)
+	fn := &Function{Name: "f", Closure: NewEnvironment(globals)}
+	fn.Closure.Set("self", fn)
+
+	copies := CopyAll(cyclic, fn, String("s"), Nil)
+	array := copies[0].(*Array)
+	if array == cyclic || array.Elements[0] != array {
+		t.Errorf("Copy(cyclic) ==> expected a copy of the cycle but was: <%p %p>", array, array.Elements[0])
+	}
+	copied := array.Elements[1].(*Array)
+	if copied == shared || array.Elements[2] != copied {
+		t.Errorf("Copy(shared) ==> expected one copy but was: <%p %p>", copied, array.Elements[2])
+	}
+
+	function := copies[1].(*Function)
+	if function == fn || function.Closure == fn.Closure || function.Closure.Enclosing == globals {
+		t.Errorf("Copy(fn) ==> expected copies of the closure and its enclosing environments")
+	}
+	if self, _ := function.Closure.Get("self"); self != function {
+		t.Errorf("Copy(fn) ==> expected: <%p> but was: <%p>", function, self)
+	}
+	if value, _ := function.Closure.Get("shared"); value != copied {
+		t.Errorf("Copy(fn) ==> expected: <%p> but was: <%p>", copied, value)
+	}
+	if copies[2] != String("s") || copies[3] != Nil {
+		t.Errorf("Copy() ==> expected values to be kept but was: <%v %v>", copies[2], copies[3])
+	}
+
+	copied.Elements[0] = Integer(2)
+	if shared.Elements[0] != Integer(1) {
+		t.Errorf("shared.Elements[0] ==> expected: <1> but was: <%s>", shared.Elements[0].Inspect())
+	}
+}
+
+func TestCopyModulesAndIterators(t *testing.T) {
+	items := &Array{Elements: []Object{Integer(0)}}
+	module := &Module{Path: "state.mk", Members: map[string]Object{"items": items, "same": items}}
+	rt := NewRuntime(DefaultLimits)
+	iterator := NewIterator(rt, func(ctx *Environment) (Object, bool, Interruption) {
+		return Integer(1), true, nil
+	})
+
+	copies := CopyAll(module, iterator)
+	copied := copies[0].(*Module)
+	if copied == module || copied.Path != module.Path {
+		t.Errorf("Copy(module) ==> expected a copy of <%s> but was: <%p %s>", module.Path, copied, copied.Path)
+	}
+	if copied.Members["items"] == items || copied.Members["items"] != copied.Members["same"] {
+		t.Errorf("Copy(module) ==> expected one copy of its members but was: <%p %p>", copied.Members["items"], copied.Members["same"])
+	}
+
+	if copies[1] != iterator {
+		t.Fatalf("Copy(iterator) ==> expected: <%p> but was: <%p>", iterator, copies[1])
+	}
+	if value, _, interrupt := iterator.Next(&Environment{Runtime: rt}); value != Integer(1) || interrupt != nil {
+		t.Errorf("Next() ==> expected: <1> but was: <%v %v>", value, interrupt)
+	}
+	_, _, interrupt := iterator.Next(&Environment{Runtime: NewRuntime(DefaultLimits)})
+	if interrupt == nil || interrupt.Inspect() != "ERROR: iterator belongs to another task" {
+		t.Errorf("Next() ==> expected: <ERROR: iterator belongs to another task> but was: <%v>", interrupt)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	rt := NewRuntime(DefaultLimits)
+	s := rt.Scheduler()
+	requests, replies := s.NewChannel(0), s.NewChannel(0)
+
+	task := s.Spawn(rt, func(rt *Runtime) (Object, Interruption) {
+		sum := Integer(0)
+		for {
+			_, value, ok, interrupt := s.Select(rt, []Case{{Channel: requests}}, true)
+			if interrupt != nil {
+				return nil, interrupt
+			}
+			if !ok {
+				return sum, nil
+			}
+			sum += value.(Integer)
+			if _, _, _, interrupt := s.Select(rt, []Case{{Channel: replies, Send: true, Value: sum}}, true); interrupt != nil {
+				return nil, interrupt
+			}
+		}
+	})
+
+	for i := 1; i <= 3; i += 1 {
+		if _, _, _, interrupt := s.Select(rt, []Case{{Channel: requests, Send: true, Value: Integer(i)}}, true); interrupt != nil {
+			t.Fatalf("test[%d] - Select(send) ==> %s", i, interrupt.Inspect())
+		}
+		_, value, _, _ := s.Select(rt, []Case{{Channel: replies}}, true)
+		if value != Integer(i*(i+1)/2) {
+			t.Errorf("test[%d] - Select(recv) ==> expected: <%d> but was: <%v>", i, i*(i+1)/2, value)
+		}
+	}
+
+	if index, _, _, _ := s.Select(rt, []Case{{Channel: replies}}, false); index != -1 {
+		t.Errorf("Select() ==> expected: <-1> but was: <%d>", index)
+	}
+	s.Close(requests)
+	if value, failure, interrupt := s.Await(rt, task); value != Integer(6) || failure != nil || interrupt != nil {
+		t.Errorf("Await() ==> expected: <6> but was: <%v %v %v>", value, failure, interrupt)
+	}
+
+	_, _, _, interrupt := s.Select(rt, []Case{{Channel: replies}}, true)
+	if interrupt == nil || interrupt.Inspect() != "ERROR: "+DEADLOCK_MESSAGE {
+		t.Errorf("Select() ==> expected: <%s> but was: <%v>", DEADLOCK_MESSAGE, interrupt)
+	}
+}
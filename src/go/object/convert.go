@@ -0,0 +1,601 @@
+package object
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ConversionError reports a value with no counterpart on the other side,
+// and where it was within the value being converted.
+type ConversionError struct {
+	From string
+	To   string
+	Path string
+}
+
+func (ce *ConversionError) Error() string {
+	if ce.Path == "" {
+		return fmt.Sprintf("cannot convert %s to %s", ce.From, ce.To)
+	}
+	return fmt.Sprintf("cannot convert %s to %s at %s", ce.From, ce.To, ce.Path)
+}
+
+// Converter converts Go values to Monkey values and back. Call, when set,
+// calls Monkey functions, which can then be converted to Go funcs; without
+// it only builtins can.
+type Converter struct {
+	Call func(fn Object, args ...Object) (Object, error)
+}
+
+// FromGo converts v to a Monkey value: bools, numbers and strings to their
+// Monkey counterparts, slices and arrays to arrays, maps to hashes, structs
+// to hashes keyed by field name or `monkey:"name"` tag, nil to null, and
+// funcs to builtins. Pointers and interfaces convert to what they hold.
+func FromGo(v any) (Object, error) {
+	return Converter{}.FromGo(v)
+}
+
+// ToGo converts obj into what target points to, following the conventions of
+// FromGo in reverse. Converted into an empty interface, integers become
+// int64, floats float64, arrays []any and hashes map[string]any, or
+// map[any]any when they have keys other than strings.
+func ToGo(obj Object, target any) error {
+	return Converter{}.ToGo(obj, target)
+}
+
+func (c Converter) FromGo(v any) (Object, error) {
+	return c.fromGo(reflect.ValueOf(v), "")
+}
+
+func (c Converter) ToGo(obj Object, target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("ToGo() ==> expected a non-nil pointer but was: %T", target)
+	}
+	return c.toGo(obj, value.Elem(), "")
+}
+
+var (
+	objectType = reflect.TypeFor[Object]()
+	errorType  = reflect.TypeFor[error]()
+	bigIntType = reflect.TypeFor[*big.Int]()
+	bigRatType = reflect.TypeFor[*big.Rat]()
+)
+
+func (c Converter) fromGo(v reflect.Value, path string) (Object, error) {
+	return c.fromValue(v, path, map[reference]bool{})
+}
+
+// reference identifies a pointer, map or slice by its address and type, so
+// converting can tell a value that contains itself.
+type reference struct {
+	address uintptr
+	typ     reflect.Type
+	length  int
+}
+
+func (c Converter) fromValue(v reflect.Value, path string, converting map[reference]bool) (Object, error) {
+	if !v.IsValid() {
+		return Nil, nil
+	}
+	if v.Type().Implements(objectType) {
+		if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+			return Nil, nil
+		}
+		return v.Interface().(Object), nil
+	}
+
+	switch v.Type() {
+	case bigIntType:
+		if v.IsNil() {
+			return Nil, nil
+		}
+		return NewInteger(new(big.Int).Set(v.Interface().(*big.Int))), nil
+	case bigRatType:
+		if v.IsNil() {
+			return Nil, nil
+		}
+		return &Decimal{Value: new(big.Rat).Set(v.Interface().(*big.Rat))}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			break
+		}
+		ref := reference{address: uintptr(v.UnsafePointer()), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			ref.length = v.Len()
+		}
+		if converting[ref] {
+			return nil, &ConversionError{From: "cyclic Go " + v.Type().String(), To: "a Monkey value", Path: path}
+		}
+		converting[ref] = true
+		defer delete(converting, ref)
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return Boolean(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Integer(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NewInteger(new(big.Int).SetUint64(v.Uint())), nil
+	case reflect.Float32, reflect.Float64:
+		return Float(v.Float()), nil
+	case reflect.String:
+		return String(v.String()), nil
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return Nil, nil
+		}
+		return c.fromValue(v.Elem(), path, converting)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return Nil, nil
+		}
+		elements := make([]Object, v.Len())
+		for i := range elements {
+			element, err := c.fromValue(v.Index(i), path+"["+strconv.Itoa(i)+"]", converting)
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = element
+		}
+		return &Array{Elements: elements}, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return Nil, nil
+		}
+		hash := &Hash{Pairs: map[HashKey]HashPair{}}
+		iter := v.MapRange()
+		for iter.Next() {
+			elementPath := path + "[" + fmt.Sprint(iter.Key().Interface()) + "]"
+			key, err := c.fromValue(iter.Key(), elementPath, converting)
+			if err != nil {
+				return nil, err
+			}
+			hashable, ok := key.(Hashable)
+			if !ok {
+				return nil, &ConversionError{From: "Go " + iter.Key().Type().String(), To: "a Monkey hash key", Path: elementPath}
+			}
+			value, err := c.fromValue(iter.Value(), elementPath, converting)
+			if err != nil {
+				return nil, err
+			}
+			hash.Pairs[hashable.HashKey()] = HashPair{Key: key, Value: value}
+		}
+		return hash, nil
+	case reflect.Struct:
+		hash := &Hash{Pairs: map[HashKey]HashPair{}}
+		for _, field := range fields(v.Type()) {
+			value, err := c.fromValue(v.FieldByIndex(field.index), path+"."+field.name, converting)
+			if err != nil {
+				return nil, err
+			}
+			key := String(field.name)
+			hash.Pairs[key.HashKey()] = HashPair{Key: key, Value: value}
+		}
+		return hash, nil
+	case reflect.Func:
+		if v.IsNil() {
+			return Nil, nil
+		}
+		return c.fromFunc(v), nil
+	default:
+		return nil, &ConversionError{From: "Go " + v.Type().String(), To: "a Monkey value", Path: path}
+	}
+}
+
+// fromFunc wraps fn as a builtin that converts its arguments to the types
+// of fn's parameters. A non-nil error fn returns last is raised as a Monkey
+// error; its other results become null, a value or an array of them.
+func (c Converter) fromFunc(fn reflect.Value) *BuiltinFunction {
+	t := fn.Type()
+	return &BuiltinFunction{
+		Fn: func(env *Environment, args ...Object) (Object, Interruption) {
+			in := t.NumIn()
+			if t.IsVariadic() && len(args) < in-1 {
+				return nil, &Error{Message: fmt.Sprintf("wrong number of arguments: want=at least %d, got=%d", in-1, len(args))}
+			}
+			if !t.IsVariadic() && len(args) != in {
+				return nil, &Error{Message: fmt.Sprintf("wrong number of arguments: want=%d, got=%d", in, len(args))}
+			}
+
+			values := make([]reflect.Value, len(args))
+			for i, arg := range args {
+				var parameter reflect.Type
+				if t.IsVariadic() && i >= in-1 {
+					parameter = t.In(in - 1).Elem()
+				} else {
+					parameter = t.In(i)
+				}
+				values[i] = reflect.New(parameter).Elem()
+				if err := c.toGo(arg, values[i], "argument "+strconv.Itoa(i+1)); err != nil {
+					return nil, &Error{Message: err.Error()}
+				}
+			}
+
+			results := fn.Call(values)
+			if n := len(results); n != 0 && t.Out(n-1) == errorType {
+				if err, _ := results[n-1].Interface().(error); err != nil {
+					var interrupt Interruption
+					if errors.As(err, &interrupt) {
+						return nil, interrupt
+					}
+					return nil, &Error{Message: err.Error()}
+				}
+				results = results[:n-1]
+			}
+
+			objects := make([]Object, len(results))
+			for i, result := range results {
+				object, err := c.fromGo(result, "result "+strconv.Itoa(i+1))
+				if err != nil {
+					return nil, &Error{Message: err.Error()}
+				}
+				objects[i] = object
+			}
+			switch len(objects) {
+			case 0:
+				return Nil, nil
+			case 1:
+				return objects[0], nil
+			default:
+				return &Array{Elements: objects}, nil
+			}
+		},
+	}
+}
+
+func (c Converter) toGo(obj Object, v reflect.Value, path string) error {
+	t := v.Type()
+	mismatch := func() error {
+		return &ConversionError{From: obj.Type().String(), To: "Go " + t.String(), Path: path}
+	}
+
+	if t.Implements(objectType) || (t.Kind() == reflect.Interface && t.NumMethod() != 0) {
+		if !reflect.TypeOf(obj).AssignableTo(t) {
+			return mismatch()
+		}
+		v.Set(reflect.ValueOf(obj))
+		return nil
+	}
+	switch t {
+	case bigIntType:
+		switch obj := obj.(type) {
+		case Integer:
+			v.Set(reflect.ValueOf(big.NewInt(int64(obj))))
+		case *BigInt:
+			v.Set(reflect.ValueOf(new(big.Int).Set(obj.Value)))
+		case *Null:
+			v.SetZero()
+		default:
+			return mismatch()
+		}
+		return nil
+	case bigRatType:
+		switch obj := obj.(type) {
+		case Integer:
+			v.Set(reflect.ValueOf(new(big.Rat).SetInt64(int64(obj))))
+		case *BigInt:
+			v.Set(reflect.ValueOf(new(big.Rat).SetInt(obj.Value)))
+		case *Decimal:
+			v.Set(reflect.ValueOf(new(big.Rat).Set(obj.Value)))
+		case *Null:
+			v.SetZero()
+		default:
+			return mismatch()
+		}
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Interface:
+		value, err := c.natural(obj, path)
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			v.SetZero()
+		} else {
+			v.Set(reflect.ValueOf(value))
+		}
+	case reflect.Bool:
+		b, ok := obj.(Boolean)
+		if !ok {
+			return mismatch()
+		}
+		v.SetBool(bool(b))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := obj.(Integer)
+		if !ok || v.OverflowInt(int64(i)) {
+			return mismatch()
+		}
+		v.SetInt(int64(i))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var n *big.Int
+		switch obj := obj.(type) {
+		case Integer:
+			n = big.NewInt(int64(obj))
+		case *BigInt:
+			n = obj.Value
+		default:
+			return mismatch()
+		}
+		if n.Sign() < 0 || !n.IsUint64() || v.OverflowUint(n.Uint64()) {
+			return mismatch()
+		}
+		v.SetUint(n.Uint64())
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		switch obj := obj.(type) {
+		case Float:
+			f = float64(obj)
+		case Integer:
+			f = float64(obj)
+		case *Decimal:
+			f, _ = obj.Value.Float64()
+		default:
+			return mismatch()
+		}
+		if t.Kind() == reflect.Float32 && !math.IsInf(f, 0) && v.OverflowFloat(f) {
+			return mismatch()
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		s, ok := obj.(String)
+		if !ok {
+			return mismatch()
+		}
+		v.SetString(string(s))
+	case reflect.Pointer:
+		if obj == Nil {
+			v.SetZero()
+			return nil
+		}
+		target := reflect.New(t.Elem())
+		if err := c.toGo(obj, target.Elem(), path); err != nil {
+			return err
+		}
+		v.Set(target)
+	case reflect.Slice:
+		if obj == Nil {
+			v.SetZero()
+			return nil
+		}
+		array, ok := obj.(*Array)
+		if !ok {
+			return mismatch()
+		}
+		slice := reflect.MakeSlice(t, len(array.Elements), len(array.Elements))
+		for i, element := range array.Elements {
+			if err := c.toGo(element, slice.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	case reflect.Array:
+		array, ok := obj.(*Array)
+		if !ok || len(array.Elements) != t.Len() {
+			return mismatch()
+		}
+		for i, element := range array.Elements {
+			if err := c.toGo(element, v.Index(i), path+"["+strconv.Itoa(i)+"]"); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		if obj == Nil {
+			v.SetZero()
+			return nil
+		}
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch()
+		}
+		m := reflect.MakeMapWithSize(t, len(hash.Pairs))
+		for _, pair := range hash.Pairs {
+			elementPath := path + "[" + pair.Key.Inspect() + "]"
+			key := reflect.New(t.Key()).Elem()
+			if err := c.toGo(pair.Key, key, elementPath); err != nil {
+				return err
+			}
+			value := reflect.New(t.Elem()).Elem()
+			if err := c.toGo(pair.Value, value, elementPath); err != nil {
+				return err
+			}
+			m.SetMapIndex(key, value)
+		}
+		v.Set(m)
+	case reflect.Struct:
+		hash, ok := obj.(*Hash)
+		if !ok {
+			return mismatch()
+		}
+		for _, field := range fields(t) {
+			pair, ok := hash.Pairs[String(field.name).HashKey()]
+			if !ok {
+				continue
+			}
+			if err := c.toGo(pair.Value, v.FieldByIndex(field.index), path+"."+field.name); err != nil {
+				return err
+			}
+		}
+	case reflect.Func:
+		if obj == Nil {
+			v.SetZero()
+			return nil
+		}
+		fn, err := c.toFunc(obj, t, path)
+		if err != nil {
+			return err
+		}
+		v.Set(fn)
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+// toFunc wraps fn as a Go func of type t that converts its arguments to
+// Monkey values and the result back, panicking when it cannot, as a Go func
+// has nowhere else to report it unless its last result is an error.
+func (c Converter) toFunc(fn Object, t reflect.Type, path string) (reflect.Value, error) {
+	call := c.Call
+	if call == nil {
+		builtin, ok := fn.(*BuiltinFunction)
+		if !ok {
+			return reflect.Value{}, &ConversionError{From: fn.Type().String(), To: "Go " + t.String() + " without an interpreter", Path: path}
+		}
+		call = func(_ Object, args ...Object) (Object, error) {
+			value, interrupt := builtin.Fn(NewEnvironment(nil), args...)
+			if interrupt != nil {
+				return nil, interruptionError(interrupt)
+			}
+			return value, nil
+		}
+	} else if fn.Type() != FUNCTION && fn.Type() != BUILTIN {
+		return reflect.Value{}, &ConversionError{From: fn.Type().String(), To: "Go " + t.String(), Path: path}
+	}
+
+	returnsError := t.NumOut() != 0 && t.Out(t.NumOut()-1) == errorType
+	results := t.NumOut()
+	if returnsError {
+		results -= 1
+	}
+	if results > 1 {
+		return reflect.Value{}, &ConversionError{From: fn.Type().String(), To: "Go " + t.String(), Path: path}
+	}
+
+	return reflect.MakeFunc(t, func(in []reflect.Value) []reflect.Value {
+		out := make([]reflect.Value, t.NumOut())
+		for i := range out {
+			out[i] = reflect.New(t.Out(i)).Elem()
+		}
+		fail := func(err error) []reflect.Value {
+			if !returnsError {
+				panic(err)
+			}
+			out[len(out)-1] = reflect.ValueOf(&err).Elem()
+			return out
+		}
+
+		if t.IsVariadic() {
+			last := in[len(in)-1]
+			in = in[:len(in)-1]
+			for i := range last.Len() {
+				in = append(in, last.Index(i))
+			}
+		}
+		args := make([]Object, len(in))
+		for i, arg := range in {
+			object, err := c.fromGo(arg, "argument "+strconv.Itoa(i+1))
+			if err != nil {
+				return fail(err)
+			}
+			args[i] = object
+		}
+
+		value, err := call(fn, args...)
+		if err != nil {
+			return fail(err)
+		}
+		if results == 1 {
+			if err := c.toGo(value, out[0], "result"); err != nil {
+				return fail(err)
+			}
+		}
+		return out
+	}), nil
+}
+
+// natural converts obj to the Go value it is most naturally.
+func (c Converter) natural(obj Object, path string) (any, error) {
+	switch obj := obj.(type) {
+	case *Null:
+		return nil, nil
+	case Integer:
+		return int64(obj), nil
+	case *BigInt:
+		return new(big.Int).Set(obj.Value), nil
+	case Float:
+		return float64(obj), nil
+	case *Decimal:
+		return new(big.Rat).Set(obj.Value), nil
+	case Boolean:
+		return bool(obj), nil
+	case String:
+		return string(obj), nil
+	case *Array:
+		elements := make([]any, len(obj.Elements))
+		for i, element := range obj.Elements {
+			value, err := c.natural(element, path+"["+strconv.Itoa(i)+"]")
+			if err != nil {
+				return nil, err
+			}
+			elements[i] = value
+		}
+		return elements, nil
+	case *Hash:
+		keyed := true
+		for _, pair := range obj.Pairs {
+			if _, ok := pair.Key.(String); !ok {
+				keyed = false
+			}
+		}
+		var target reflect.Value
+		if keyed {
+			target = reflect.ValueOf(&map[string]any{}).Elem()
+		} else {
+			target = reflect.ValueOf(&map[any]any{}).Elem()
+		}
+		if err := c.toGo(obj, target, path); err != nil {
+			return nil, err
+		}
+		return target.Interface(), nil
+	default:
+		return obj, nil
+	}
+}
+
+type field struct {
+	name  string
+	index []int
+}
+
+// fields lists the exported fields of a struct type by the name they have
+// in Monkey: their `monkey:"name"` tag, or their Go name. A tag of "-"
+// leaves a field out.
+func fields(t reflect.Type) []field {
+	fields := []field{}
+	for _, f := range reflect.VisibleFields(t) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("monkey"); ok {
+			tag, _, _ = strings.Cut(tag, ",")
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, field{name: name, index: f.Index})
+	}
+	return fields
+}
+
+func interruptionError(interrupt Interruption) error {
+	if err, ok := interrupt.(error); ok {
+		return err
+	}
+	return errors.New(interrupt.Inspect())
+}
@@ -0,0 +1,289 @@
+package object
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	X     int     `monkey:"x"`
+	Y     float64 `monkey:"y,omitempty"`
+	Label string
+	Tags  []string `monkey:"tags"`
+	Next  *point   `monkey:"next"`
+
+	Secret string `monkey:"-"`
+	hidden int
+}
+
+// sorted inspects obj with the pairs of its hashes in order.
+func sorted(obj Object) string {
+	switch obj := obj.(type) {
+	case *Array:
+		elements := []string{}
+		for _, element := range obj.Elements {
+			elements = append(elements, sorted(element))
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+	case *Hash:
+		pairs := []string{}
+		for _, pair := range obj.Pairs {
+			pairs = append(pairs, pair.Key.Inspect()+": "+sorted(pair.Value))
+		}
+		sort.Strings(pairs)
+		return "{" + strings.Join(pairs, ", ") + "}"
+	default:
+		return obj.Inspect()
+	}
+}
+
+func TestFromGo(t *testing.T) {
+	var nilPoint *point
+	var nilObject Object
+
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{nil, "null"},
+		{true, "true"},
+		{42, "42"},
+		{int8(-3), "-3"},
+		{uint64(1 << 63), "9223372036854775808"},
+		{2.5, "2.5"},
+		{float32(1), "1.0"},
+		{"monkey", `"monkey"`},
+		{big.NewInt(7), "7"},
+		{big.NewRat(5, 4), "1.25d"},
+		{[]int{1, 2, 3}, "[1, 2, 3]"},
+		{[2]bool{true, false}, "[true, false]"},
+		{[]string(nil), "null"},
+		{map[string]int{"b": 2, "a": 1}, `{"a": 1, "b": 2}`},
+		{map[int][]any{1: {"x", nil}}, `{1: ["x", null]}`},
+		{nilPoint, "null"},
+		{nilObject, "null"},
+		{Integer(9), "9"},
+		{&Array{Elements: []Object{String("kept")}}, `["kept"]`},
+		{
+			&point{X: 1, Y: 2, Label: "p", Tags: []string{"a"}, Next: &point{X: 3}, Secret: "s", hidden: 4},
+			`{"Label": "p", "next": {"Label": "", "next": null, "tags": null, "x": 3, "y": 0.0}, "tags": ["a"], "x": 1, "y": 2.0}`,
+		},
+	}
+
+	for i, test := range tests {
+		actual, err := FromGo(test.input)
+		if err != nil {
+			t.Errorf("test[%d] - FromGo() ==> unexpected error: %v", i, err)
+			continue
+		}
+		if test.expected != sorted(actual) {
+			t.Errorf("test[%d] - FromGo() ==> expected: <%s> but was: <%s>", i, test.expected, sorted(actual))
+		}
+	}
+
+	if actual, _ := FromGo(nil); actual != Nil {
+		t.Errorf("FromGo(nil) ==> expected: <Nil> but was: <%p>", actual)
+	}
+}
+
+func TestFromGoErrors(t *testing.T) {
+	tests := []struct {
+		input    any
+		expected string
+	}{
+		{make(chan int), "cannot convert Go chan int to a Monkey value"},
+		{[]any{1, complex(1, 2)}, "cannot convert Go complex128 to a Monkey value at [1]"},
+		{map[string]any{"k": struct{ C chan bool }{}}, "cannot convert Go chan bool to a Monkey value at [k].C"},
+		{map[[1]int]int{{1}: 1}, "cannot convert Go [1]int to a Monkey hash key at [[1]]"},
+		{cyclic(), "cannot convert cyclic Go *object.node to a Monkey value at .Next.Next"},
+		{selfish(), "cannot convert cyclic Go []interface {} to a Monkey value at [1]"},
+		{loop(), "cannot convert cyclic Go map[string]interface {} to a Monkey value at [self]"},
+	}
+
+	for i, test := range tests {
+		_, err := FromGo(test.input)
+		var conversion *ConversionError
+		if !errors.As(err, &conversion) || test.expected != err.Error() {
+			t.Errorf("test[%d] - FromGo() ==> expected: <%s> but was: <%v>", i, test.expected, err)
+		}
+	}
+}
+
+type node struct {
+	Value int
+	Next  *node
+}
+
+func cyclic() any {
+	n := &node{Value: 1, Next: &node{Value: 2}}
+	n.Next.Next = n
+	return n
+}
+
+func selfish() any {
+	s := []any{1, nil}
+	s[1] = s
+	return s
+}
+
+func loop() any {
+	m := map[string]any{}
+	m["self"] = m
+	return m
+}
+
+func TestFromGoShared(t *testing.T) {
+	shared := &node{Value: 1}
+	actual, err := FromGo([]*node{shared, shared})
+	if err != nil {
+		t.Fatalf("FromGo() ==> unexpected error: %v", err)
+	}
+	array, ok := actual.(*Array)
+	if !ok || len(array.Elements) != 2 || array.Elements[0].Type() != HASH || array.Elements[1].Type() != HASH {
+		t.Errorf("FromGo() ==> expected: <two hashes> but was: <%s>", actual.Inspect())
+	}
+}
+
+func TestToGo(t *testing.T) {
+	hash := func(pairs ...Object) *Hash {
+		h := &Hash{Pairs: map[HashKey]HashPair{}}
+		for i := 0; i < len(pairs); i += 2 {
+			h.Pairs[pairs[i].(Hashable).HashKey()] = HashPair{Key: pairs[i], Value: pairs[i+1]}
+		}
+		return h
+	}
+
+	tests := []struct {
+		input    Object
+		target   any
+		expected any
+	}{
+		{Integer(5), new(int), 5},
+		{Integer(200), new(uint8), uint8(200)},
+		{Integer(2), new(float64), 2.0},
+		{Float(1.5), new(float32), float32(1.5)},
+		{&Decimal{Value: big.NewRat(1, 4)}, new(float64), 0.25},
+		{&Decimal{Value: big.NewRat(1, 4)}, new(*big.Rat), big.NewRat(1, 4)},
+		{NewInteger(new(big.Int).Lsh(big.NewInt(1), 64)), new(*big.Int), new(big.Int).Lsh(big.NewInt(1), 64)},
+		{String("s"), new(string), "s"},
+		{Boolean(true), new(bool), true},
+		{&Array{Elements: []Object{Integer(1), Integer(2)}}, new([]int), []int{1, 2}},
+		{&Array{Elements: []Object{Integer(1), Integer(2)}}, new([2]int64), [2]int64{1, 2}},
+		{Nil, new([]int), []int(nil)},
+		{Nil, new(*int), (*int)(nil)},
+		{Integer(3), new(*int), func() *int { n := 3; return &n }()},
+		{hash(String("a"), Integer(1)), new(map[string]int), map[string]int{"a": 1}},
+		{hash(Integer(1), Boolean(true)), new(map[int]bool), map[int]bool{1: true}},
+		{
+			hash(String("x"), Integer(1), String("tags"), &Array{Elements: []Object{String("t")}}, String("next"), hash(String("x"), Integer(2)), String("Secret"), String("s"), String("extra"), Nil),
+			new(point),
+			point{X: 1, Tags: []string{"t"}, Next: &point{X: 2}},
+		},
+		{Integer(1), new(any), int64(1)},
+		{&Array{Elements: []Object{Float(1), Nil, String("a")}}, new(any), []any{1.0, nil, "a"}},
+		{hash(String("k"), &Array{}), new(any), map[string]any{"k": []any{}}},
+		{hash(Integer(1), String("v")), new(any), map[any]any{int64(1): "v"}},
+		{String("o"), new(Object), String("o")},
+		{&Array{}, new(*Array), &Array{}},
+	}
+
+	for i, test := range tests {
+		if err := ToGo(test.input, test.target); err != nil {
+			t.Errorf("test[%d] - ToGo() ==> unexpected error: %v", i, err)
+			continue
+		}
+		actual := reflect.ValueOf(test.target).Elem().Interface()
+		if !reflect.DeepEqual(test.expected, actual) {
+			t.Errorf("test[%d] - ToGo() ==> expected: <%#v> but was: <%#v>", i, test.expected, actual)
+		}
+	}
+}
+
+func TestToGoErrors(t *testing.T) {
+	tests := []struct {
+		input    Object
+		target   any
+		expected string
+	}{
+		{String("1"), new(int), "cannot convert STRING to Go int"},
+		{Integer(256), new(uint8), "cannot convert INTEGER to Go uint8"},
+		{Integer(-1), new(uint), "cannot convert INTEGER to Go uint"},
+		{Float(1.5), new(int), "cannot convert FLOAT to Go int"},
+		{&Array{Elements: []Object{Integer(1), String("2")}}, new([]int), "cannot convert STRING to Go int at [1]"},
+		{&Array{Elements: []Object{Integer(1)}}, new([2]int), "cannot convert ARRAY to Go [2]int"},
+		{&Hash{Pairs: map[HashKey]HashPair{String("x").HashKey(): {Key: String("x"), Value: String("one")}}}, new(point), "cannot convert STRING to Go int at .x"},
+		{Integer(1), new(*Array), "cannot convert INTEGER to Go *object.Array"},
+		{&Function{}, new(func()), "cannot convert FUNCTION to Go func() without an interpreter"},
+	}
+
+	for i, test := range tests {
+		err := ToGo(test.input, test.target)
+		var conversion *ConversionError
+		if !errors.As(err, &conversion) || test.expected != err.Error() {
+			t.Errorf("test[%d] - ToGo() ==> expected: <%s> but was: <%v>", i, test.expected, err)
+		}
+	}
+
+	if err := ToGo(Integer(1), 1); err == nil {
+		t.Errorf("ToGo() ==> expected an error for a target that is not a pointer")
+	}
+}
+
+func TestFuncs(t *testing.T) {
+	builtin, err := FromGo(func(sep string, parts ...string) (string, error) {
+		if len(parts) == 0 {
+			return "", errors.New("nothing to join")
+		}
+		return strings.Join(parts, sep), nil
+	})
+	if err != nil {
+		t.Fatalf("FromGo() ==> %v", err)
+	}
+	join := builtin.(*BuiltinFunction)
+
+	tests := []struct {
+		args     []Object
+		expected string
+	}{
+		{[]Object{String("-"), String("a"), String("b")}, `"a-b"`},
+		{[]Object{String("-")}, "ERROR: nothing to join"},
+		{[]Object{}, "ERROR: wrong number of arguments: want=at least 1, got=0"},
+		{[]Object{String("-"), Integer(1)}, "ERROR: cannot convert INTEGER to Go string at argument 2"},
+	}
+	for i, test := range tests {
+		value, interrupt := join.Fn(nil, test.args...)
+		actual := ""
+		if interrupt != nil {
+			actual = interrupt.Inspect()
+		} else {
+			actual = value.Inspect()
+		}
+		if test.expected != actual {
+			t.Errorf("test[%d] - Fn() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+
+	divmod, _ := FromGo(func(a, b int) (int, int) { return a / b, a % b })
+	if value, _ := divmod.(*BuiltinFunction).Fn(nil, Integer(7), Integer(2)); value.Inspect() != "[3, 1]" {
+		t.Errorf("Fn() ==> expected: <[3, 1]> but was: <%s>", value.Inspect())
+	}
+	nothing, _ := FromGo(func() {})
+	if value, _ := nothing.(*BuiltinFunction).Fn(nil); value != Nil {
+		t.Errorf("Fn() ==> expected: <null> but was: <%s>", value.Inspect())
+	}
+
+	var back func(string, ...string) (string, error)
+	if err := ToGo(join, &back); err != nil {
+		t.Fatalf("ToGo() ==> %v", err)
+	}
+	if s, err := back("+", "x", "y"); s != "x+y" || err != nil {
+		t.Errorf("back() ==> expected: <x+y> but was: <%s %v>", s, err)
+	}
+	if _, err := back("+"); err == nil || err.Error() != "nothing to join" {
+		t.Errorf("back() ==> expected: <nothing to join> but was: <%v>", err)
+	}
+}
@@ -3,29 +3,107 @@ package object
 type Environment struct {
 	Values    map[string]Object
 	Enclosing *Environment
+	Runtime   *Runtime
+
+	// Slots hold the variables of a resolved function call, named by the
+	// parallel Names of its ast.Scope. Values only holds names that were set
+	// dynamically.
+	Slots []Object
+	Names []string
 
 	Quoting bool
 }
 
 func NewEnvironment(enclosing *Environment) *Environment {
+	if enclosing == nil {
+		return NewEnvironmentWithLimits(DefaultLimits)
+	}
 	return &Environment{
 		Values:    make(map[string]Object),
 		Enclosing: enclosing,
+		Runtime:   enclosing.Runtime,
+	}
+}
+
+func NewEnvironmentWithLimits(limits Limits) *Environment {
+	return &Environment{
+		Values:  make(map[string]Object),
+		Runtime: NewRuntime(limits),
+	}
+}
+
+// NewFrameEnvironment creates the frame of a resolved function call whose
+// first slots are args, reusing the spare capacity of args when it has room
+// for every name.
+func NewFrameEnvironment(enclosing *Environment, names []string, args []Object) *Environment {
+	slots := args[:cap(args)]
+	if len(slots) < len(names) {
+		slots = make([]Object, len(names))
+		copy(slots, args)
+	}
+	clear(slots[len(args):])
+	return &Environment{
+		Enclosing: enclosing,
+		Runtime:   enclosing.Runtime,
+		Slots:     slots[:len(names)],
+		Names:     names,
 	}
 }
 
 func (env *Environment) Get(ident string) (Object, bool) {
-	value, found := env.Values[ident]
-	if !found && env.Enclosing != nil {
-		value, found = env.Enclosing.Get(ident)
+	for ; env != nil; env = env.Enclosing {
+		if value, found := env.Values[ident]; found {
+			return value, true
+		}
+		if slot := env.slot(ident); slot >= 0 && env.Slots[slot] != nil {
+			return env.Slots[slot], true
+		}
 	}
-	return value, found
+	return nil, false
 }
 
 func (env *Environment) Set(ident string, value Object) {
+	if slot := env.slot(ident); slot >= 0 {
+		env.Slots[slot] = value
+		return
+	}
+	if env.Values == nil {
+		env.Values = make(map[string]Object)
+	}
 	env.Values[ident] = value
 }
 
+// Assign replaces the value of ident in the nearest environment that holds
+// it and reports whether there was one.
+func (env *Environment) Assign(ident string, value Object) bool {
+	for ; env != nil; env = env.Enclosing {
+		if _, found := env.Values[ident]; found {
+			env.Values[ident] = value
+			return true
+		}
+		if slot := env.slot(ident); slot >= 0 && env.Slots[slot] != nil {
+			env.Slots[slot] = value
+			return true
+		}
+	}
+	return false
+}
+
 func (env *Environment) Length() int {
-	return len(env.Values)
+	length := len(env.Values)
+	for _, value := range env.Slots {
+		if value != nil {
+			length += 1
+		}
+	}
+	return length
+}
+
+func (env *Environment) slot(ident string) int {
+	for i := len(env.Names) - 1; i >= 0; i -= 1 {
+		if env.Names[i] == ident {
+			return i
+		}
+	}
+	return -1
 }
@@ -0,0 +1,178 @@
+package object
+
+import (
+	"runtime"
+)
+
+// Iterator produces the values of a sequence on demand. Next returns false
+// once the sequence is exhausted.
+type Iterator struct {
+	Next func(ctx *Environment) (Object, bool, Interruption)
+
+	generator *generator
+}
+
+func (it *Iterator) Type() ObjectType {
+	return ITERATOR
+}
+
+func (it *Iterator) Inspect() string {
+	return "<iterator>"
+}
+
+// NewIterator returns an iterator over the values next produces. Iterators
+// are shared rather than copied between tasks, so only the task running on
+// rt, which created it, can advance it.
+func NewIterator(rt *Runtime, next func(ctx *Environment) (Object, bool, Interruption)) *Iterator {
+	return &Iterator{Next: func(ctx *Environment) (Object, bool, Interruption) {
+		if ctx.Runtime != rt {
+			return nil, false, &Error{Message: "iterator belongs to another task"}
+		}
+		return next(ctx)
+	}}
+}
+
+// A generator runs the body of a generator function on a goroutine of its
+// own, suspended at each yield until the next value is asked for. The
+// caller and the body hand control to each other over resume and yields,
+// so only one of them runs at a time and they share the runtime of the
+// evaluation that called the function.
+//
+// A body that returns an iterator continues with its values, so generators
+// recurse in tail position; the body of a generator it returns that has not
+// started runs on the same goroutine in its place.
+type generator struct {
+	runtime *Runtime
+	frame   Frame
+	body    func() (Object, Interruption)
+
+	resume chan struct{}
+	yields chan yielded
+
+	started bool
+	running bool
+	done    bool
+}
+
+type yielded struct {
+	value     Object
+	done      bool
+	interrupt Interruption
+}
+
+// NewGenerator returns an iterator over the values body yields as it runs
+// within frame on rt. The goroutine of a generator that is dropped before
+// it finishes ends once its Next function is garbage collected.
+func NewGenerator(rt *Runtime, frame Frame, body func() (Object, Interruption)) *Iterator {
+	g := &generator{
+		runtime: rt,
+		frame:   frame,
+		body:    body,
+		resume:  make(chan struct{}),
+		yields:  make(chan yielded),
+	}
+	// The goroutine keeps g reachable, so the finalizer watches a handle
+	// that only Next holds.
+	h := &handle{g}
+	runtime.SetFinalizer(h, func(h *handle) { h.abandon() })
+	next := func(ctx *Environment) (Object, bool, Interruption) {
+		defer runtime.KeepAlive(h)
+		return h.next(ctx)
+	}
+	return &Iterator{Next: next, generator: g}
+}
+
+type handle struct {
+	*generator
+}
+
+func (g *generator) next(ctx *Environment) (Object, bool, Interruption) {
+	rt := ctx.Runtime
+	if rt != g.runtime {
+		return nil, false, &Error{Message: "generator belongs to another task"}
+	}
+	if g.running {
+		return nil, false, &Error{Message: "generator is already running"}
+	}
+	if g.done {
+		return Nil, false, nil
+	}
+
+	if err := rt.Push(g.frame); err != nil {
+		return nil, false, err
+	}
+	defer rt.Pop()
+
+	previous := rt.generator
+	rt.generator = g
+	g.running = true
+	if g.started {
+		g.resume <- struct{}{}
+	} else {
+		g.started = true
+		go g.run()
+	}
+	result := <-g.yields
+	g.running = false
+	rt.generator = previous
+
+	if result.done {
+		g.done = true
+		return Nil, false, result.interrupt
+	}
+	return result.value, true, nil
+}
+
+func (g *generator) run() {
+	body := g.body
+	for {
+		value, interrupt := body()
+		it, ok := value.(*Iterator)
+		if interrupt != nil || !ok {
+			g.yields <- yielded{done: true, interrupt: interrupt}
+			return
+		}
+
+		if inner := it.generator; inner != nil && inner.runtime == g.runtime && !inner.started {
+			inner.started, inner.done = true, true
+			body = inner.body
+			continue
+		}
+		body = func() (Object, Interruption) {
+			ctx := &Environment{Runtime: g.runtime}
+			for {
+				value, ok, interrupt := it.Next(ctx)
+				if interrupt != nil || !ok {
+					return nil, interrupt
+				}
+				if interrupt := g.runtime.Yield(value); interrupt != nil {
+					return nil, interrupt
+				}
+			}
+		}
+	}
+}
+
+// abandon ends the goroutine of a generator suspended at a yield.
+func (g *generator) abandon() {
+	if g.started && !g.done {
+		close(g.resume)
+	}
+}
+
+// Yield hands value to the caller of the generator running on rt and
+// suspends it until it is resumed.
+func (rt *Runtime) Yield(value Object) Interruption {
+	g := rt.generator
+	if g == nil {
+		return &Error{Message: "yield outside a generator"}
+	}
+
+	g.yields <- yielded{value: value}
+	if _, ok := <-g.resume; !ok {
+		// Nothing is left to resume the generator; the frames of its body
+		// belong to a caller that has moved on, so it must not unwind.
+		runtime.Goexit()
+	}
+	return nil
+}
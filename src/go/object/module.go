@@ -0,0 +1,28 @@
+package object
+
+import "strconv"
+
+// Module is the namespace of an imported source file: the values and
+// macros it exports, by name.
+type Module struct {
+	Path    string
+	Members map[string]Object
+}
+
+func (m *Module) Type() ObjectType {
+	return MODULE
+}
+
+func (m *Module) Inspect() string {
+	return "<module " + strconv.Quote(m.Path) + ">"
+}
+
+// Importer loads the modules that import statements name. ImportMacros
+// only defines the exported macros of a module, for macro expansion;
+// Import evaluates it on rt as well, the first time it is imported. Tasks
+// share the importer of the evaluation that spawned them, so it must be
+// safe for concurrent use.
+type Importer interface {
+	ImportMacros(path string) (*Module, Interruption)
+	Import(path string, rt *Runtime) (*Module, Interruption)
+}
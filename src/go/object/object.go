@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"math/big"
+	"strconv"
 	"strings"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
 )
 
 type ObjectType int
@@ -15,13 +19,21 @@ const (
 	FUNCTION ObjectType = iota
 	BUILTIN
 	MACRO
-	NUMBER
+	INTEGER
+	BIG_INTEGER
+	FLOAT
+	DECIMAL
 	BOOLEAN
 	STRING
 	ARRAY
 	HASH
 	NULL
 	QUOTE
+	COMPILED_FUNCTION
+	TASK
+	CHANNEL
+	ITERATOR
+	MODULE
 )
 
 type Object interface {
@@ -30,8 +42,12 @@ type Object interface {
 }
 
 type Function struct {
+	Name    string
 	Literal *ast.FunctionLiteral
 	Closure *Environment
+
+	Compiled *CompiledFunction
+	Free     []*Cell
 }
 
 func (f *Function) Type() ObjectType {
@@ -98,13 +114,18 @@ func (m *Macro) Inspect() string {
 }
 
 type (
-	Number  float64
+	Integer int64
+	Float   float64
 	Boolean bool
 	String  string
 )
 
-func (n Number) Type() ObjectType {
-	return NUMBER
+func (i Integer) Type() ObjectType {
+	return INTEGER
+}
+
+func (f Float) Type() ObjectType {
+	return FLOAT
 }
 
 func (b Boolean) Type() ObjectType {
@@ -115,8 +136,18 @@ func (s String) Type() ObjectType {
 	return STRING
 }
 
-func (n Number) Inspect() string {
-	return fmt.Sprintf("%g", float64(n))
+func (i Integer) Inspect() string {
+	return strconv.FormatInt(int64(i), 10)
+}
+
+// Inspect keeps a decimal point on whole floats so they read differently
+// from integers.
+func (f Float) Inspect() string {
+	s := strconv.FormatFloat(float64(f), 'g', -1, 64)
+	if strings.ContainsAny(s, ".eIN") {
+		return s
+	}
+	return s + ".0"
 }
 
 func (b Boolean) Inspect() string {
@@ -127,6 +158,76 @@ func (s String) Inspect() string {
 	return "\"" + string(s) + "\""
 }
 
+// BigInt holds the integers that do not fit in an Integer; arithmetic
+// results that fit again are turned back into an Integer by NewInteger.
+type BigInt struct {
+	Value *big.Int
+}
+
+func NewInteger(value *big.Int) Object {
+	if value.IsInt64() {
+		return Integer(value.Int64())
+	}
+	return &BigInt{Value: value}
+}
+
+func (bi *BigInt) Type() ObjectType {
+	return BIG_INTEGER
+}
+
+func (bi *BigInt) Inspect() string {
+	return bi.Value.String()
+}
+
+// Decimal is an exact rational number, written with a d suffix. It keeps no
+// scale, so 12.50d and 12.5d are the same decimal and both print as 12.5d.
+type Decimal struct {
+	Value *big.Rat
+}
+
+func (d *Decimal) Type() ObjectType {
+	return DECIMAL
+}
+
+func (d *Decimal) Inspect() string {
+	return d.String() + "d"
+}
+
+// String writes the decimal without its suffix or trailing zeros, exactly
+// when its expansion terminates and rounded to DecimalPlaces digits
+// otherwise. A rounded decimal such as 1d/3d does not read back as itself:
+// it prints as 0.33333333333333333333d, which is a different decimal.
+func (d *Decimal) String() string {
+	places, exact := decimalPlaces(d.Value.Denom())
+	if !exact {
+		return d.Value.FloatString(DecimalPlaces)
+	}
+	return d.Value.FloatString(places)
+}
+
+const DecimalPlaces = 20
+
+// decimalPlaces counts the digits a denominator of the form 2^a*5^b needs
+// after the decimal point.
+func decimalPlaces(denom *big.Int) (int, bool) {
+	rest := new(big.Int).Set(denom)
+	modulus := new(big.Int)
+	count := func(factor int64) int {
+		n := 0
+		f := big.NewInt(factor)
+		for {
+			quotient, _ := new(big.Int).QuoRem(rest, f, modulus)
+			if modulus.Sign() != 0 {
+				return n
+			}
+			rest = quotient
+			n += 1
+		}
+	}
+	twos, fives := count(2), count(5)
+	return max(twos, fives), rest.IsInt64() && rest.Int64() == 1
+}
+
 type Array struct {
 	Elements []Object
 }
@@ -160,8 +261,21 @@ type HashKey struct {
 	Value uint64
 }
 
-func (n Number) HashKey() HashKey {
-	return HashKey{n.Type(), uint64(n)}
+func (i Integer) HashKey() HashKey {
+	return HashKey{i.Type(), uint64(i)}
+}
+
+// HashKey gives whole floats the key of the equal integer, since the two
+// compare equal, and every other float a key of its own bits.
+func (f Float) HashKey() HashKey {
+	if f >= math.MinInt64 && f < math.MaxInt64 && f == Float(math.Trunc(float64(f))) {
+		return Integer(f).HashKey()
+	}
+	if !math.IsInf(float64(f), 0) && f == Float(math.Trunc(float64(f))) {
+		value, _ := big.NewFloat(float64(f)).Int(nil)
+		return NewInteger(value).(Hashable).HashKey()
+	}
+	return HashKey{f.Type(), math.Float64bits(float64(f))}
 }
 
 func (b Boolean) HashKey() HashKey {
@@ -180,6 +294,28 @@ func (s String) HashKey() HashKey {
 	return HashKey{s.Type(), h.Sum64()}
 }
 
+// HashKey gives big integers an FNV hash of their sign and magnitude. Big
+// integers never equal an Integer, so the keys cannot meet.
+func (bi *BigInt) HashKey() HashKey {
+	h := fnv.New64a()
+	if bi.Value.Sign() < 0 {
+		h.Write([]byte{'-'})
+	}
+	h.Write(bi.Value.Bytes())
+	return HashKey{bi.Type(), h.Sum64()}
+}
+
+// HashKey gives whole decimals the key of the equal integer and hashes the
+// exact fraction of every other decimal.
+func (d *Decimal) HashKey() HashKey {
+	if d.Value.IsInt() {
+		return NewInteger(d.Value.Num()).(Hashable).HashKey()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(d.Value.String()))
+	return HashKey{d.Type(), h.Sum64()}
+}
+
 type HashPair struct {
 	Key   Object
 	Value Object
@@ -210,6 +346,9 @@ func (h *Hash) Inspect() string {
 
 type Null struct{}
 
+// Nil is the null every evaluation shares; null is recognised by identity.
+var Nil = &Null{}
+
 func (n *Null) Type() ObjectType {
 	return NULL
 }
@@ -235,6 +374,7 @@ type InterruptionType int
 const (
 	RETURN_VALUE InterruptionType = iota
 	ERROR
+	TAIL_CALL
 )
 
 type Interruption interface {
@@ -255,7 +395,9 @@ func (rv *ReturnValue) Inspect() string {
 }
 
 type Error struct {
-	Message string
+	Message  string
+	Position token.Position
+	Frames   []Frame
 }
 
 func (e *Error) Type() InterruptionType {
@@ -266,17 +408,46 @@ func (e *Error) Inspect() string {
 	return "ERROR: " + e.Message
 }
 
+// Error makes an *Error a Go error for hosts embedding the evaluator.
+func (e *Error) Error() string {
+	return e.Message
+}
+
+type TailCall struct {
+	Function  *Function
+	Arguments []Object
+	Position  token.Position
+}
+
+func (tc *TailCall) Type() InterruptionType {
+	return TAIL_CALL
+}
+
+func (tc *TailCall) Inspect() string {
+	return "TAIL_CALL: " + tc.Function.Inspect()
+}
+
 var objects = [...]string{
 	FUNCTION: "FUNCTION",
 	BUILTIN:  "BUILTIN",
 	MACRO:    "MACRO",
-	NUMBER:   "INTEGER",
-	BOOLEAN:  "BOOLEAN",
-	STRING:   "STRING",
-	ARRAY:    "ARRAY",
-	HASH:     "HASH",
-	NULL:     "NULL",
-	QUOTE:    "QUOTE",
+	INTEGER:  "INTEGER",
+	FLOAT:    "FLOAT",
+	DECIMAL:  "DECIMAL",
+
+	BIG_INTEGER: "BIG_INTEGER",
+	BOOLEAN:     "BOOLEAN",
+	STRING:      "STRING",
+	ARRAY:       "ARRAY",
+	HASH:        "HASH",
+	NULL:        "NULL",
+	QUOTE:       "QUOTE",
+
+	COMPILED_FUNCTION: "COMPILED_FUNCTION",
+	TASK:              "TASK",
+	CHANNEL:           "CHANNEL",
+	ITERATOR:          "ITERATOR",
+	MODULE:            "MODULE",
 }
 
 func (ot ObjectType) String() string {
@@ -286,6 +457,7 @@ func (ot ObjectType) String() string {
 var interruptions = [...]string{
 	RETURN_VALUE: "RETURN_VALUE",
 	ERROR:        "ERROR",
+	TAIL_CALL:    "TAIL_CALL",
 }
 
 func (it InterruptionType) String() string {
@@ -1,13 +1,64 @@
 package object
 
-import "testing"
+import (
+	"math/big"
+	"testing"
+)
 
-func TestNumberHashKey(t *testing.T) {
-	expected := Number(1)
-	actual := Number(1)
+func TestIntegerHashKey(t *testing.T) {
+	expected := Integer(1)
+	actual := Integer(1)
 
 	if expected.HashKey() != actual.HashKey() {
-		t.Errorf("Number.HashKey() ==> expected: <%d> but was: <%d>", expected.HashKey(), actual.HashKey())
+		t.Errorf("Integer.HashKey() ==> expected: <%d> but was: <%d>", expected.HashKey(), actual.HashKey())
+	}
+}
+
+func TestNumberHashKey(t *testing.T) {
+	tests := []struct {
+		left  Hashable
+		right Hashable
+		equal bool
+	}{
+		{Float(1.5), Float(1.5), true},
+		{Float(1), Integer(1), true},
+		{Float(-0.0), Integer(0), true},
+		{Float(1.5), Integer(1), false},
+		{Integer(-1), Integer(1<<63 - 1), false},
+		{Integer(-1), Float(-1.5), false},
+		{Float(1e300), Float(2e300), false},
+		{Float(1e20), &BigInt{Value: big.NewInt(0).Exp(big.NewInt(10), big.NewInt(20), nil)}, true},
+		{&Decimal{Value: big.NewRat(4, 2)}, Integer(2), true},
+		{&Decimal{Value: big.NewRat(1, 2)}, &Decimal{Value: big.NewRat(2, 4)}, true},
+		{&Decimal{Value: big.NewRat(1, 2)}, Float(0.5), false},
+	}
+
+	for i, test := range tests {
+		if equal := test.left.HashKey() == test.right.HashKey(); equal != test.equal {
+			t.Errorf("test[%d] - %s.HashKey() == %s.HashKey() ==> expected: <%t> but was: <%t>", i, test.left.Inspect(), test.right.Inspect(), test.equal, equal)
+		}
+	}
+}
+
+func TestNumberInspect(t *testing.T) {
+	tests := []struct {
+		input    Object
+		expected string
+	}{
+		{Integer(9007199254740993), "9007199254740993"},
+		{Float(2), "2.0"},
+		{Float(2.5), "2.5"},
+		{Float(1e21), "1e+21"},
+		{&Decimal{Value: big.NewRat(1250, 100)}, "12.5d"},
+		{&Decimal{Value: big.NewRat(-1, 8)}, "-0.125d"},
+		{&Decimal{Value: big.NewRat(2, 3)}, "0.66666666666666666667d"},
+		{&BigInt{Value: big.NewInt(0).Lsh(big.NewInt(1), 64)}, "18446744073709551616"},
+	}
+
+	for i, test := range tests {
+		if actual := test.input.Inspect(); actual != test.expected {
+			t.Errorf("test[%d] - Inspect() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
 	}
 }
 
@@ -0,0 +1,134 @@
+package object
+
+import (
+	"sync"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+// An Observer is notified as an evaluation runs. It is installed on the
+// Runtime, and evaluating without one costs a nil check per node.
+//
+// Enter and Exit bracket the evaluation of every node; Exit receives what the
+// node evaluated to. Call and Return bracket every call of a Function, with
+// the environment of its frame; a tail call returns with the *TailCall that
+// replaces it. Raise is called once for every *Error, where it is raised.
+type Observer interface {
+	Enter(node ast.Node, env *Environment)
+	Exit(node ast.Node, env *Environment, value Object, interrupt Interruption)
+	Call(fn *Function, args []Object, position token.Position, env *Environment)
+	Return(fn *Function, value Object, interrupt Interruption)
+	CallBuiltin(name string, args []Object, position token.Position, env *Environment)
+	Raise(err *Error, env *Environment)
+}
+
+// BaseObserver ignores every event; embed it to observe only some of them.
+type BaseObserver struct{}
+
+func (BaseObserver) Enter(node ast.Node, env *Environment) {}
+
+func (BaseObserver) Exit(node ast.Node, env *Environment, value Object, interrupt Interruption) {}
+
+func (BaseObserver) Call(fn *Function, args []Object, position token.Position, env *Environment) {}
+
+func (BaseObserver) Return(fn *Function, value Object, interrupt Interruption) {}
+
+func (BaseObserver) CallBuiltin(name string, args []Object, position token.Position, env *Environment) {
+}
+
+func (BaseObserver) Raise(err *Error, env *Environment) {}
+
+// Observers notifies each of its observers in turn.
+type Observers []Observer
+
+func (o Observers) Enter(node ast.Node, env *Environment) {
+	for _, observer := range o {
+		observer.Enter(node, env)
+	}
+}
+
+func (o Observers) Exit(node ast.Node, env *Environment, value Object, interrupt Interruption) {
+	for _, observer := range o {
+		observer.Exit(node, env, value, interrupt)
+	}
+}
+
+func (o Observers) Call(fn *Function, args []Object, position token.Position, env *Environment) {
+	for _, observer := range o {
+		observer.Call(fn, args, position, env)
+	}
+}
+
+func (o Observers) Return(fn *Function, value Object, interrupt Interruption) {
+	for _, observer := range o {
+		observer.Return(fn, value, interrupt)
+	}
+}
+
+func (o Observers) CallBuiltin(name string, args []Object, position token.Position, env *Environment) {
+	for _, observer := range o {
+		observer.CallBuiltin(name, args, position, env)
+	}
+}
+
+func (o Observers) Raise(err *Error, env *Environment) {
+	for _, observer := range o {
+		observer.Raise(err, env)
+	}
+}
+
+// lockedObserver serialises the events of the tasks of an evaluation, which
+// share its observer, so that observers need not be safe for concurrent use.
+// The events of different tasks interleave.
+type lockedObserver struct {
+	mu       sync.Mutex
+	observer Observer
+}
+
+// share returns the observer of rt locked for sharing with the tasks it
+// spawns, installing it on rt as well so that rt takes turns with them.
+func share(rt *Runtime) Observer {
+	switch rt.Observer.(type) {
+	case nil, *lockedObserver:
+	default:
+		rt.Observer = &lockedObserver{observer: rt.Observer}
+	}
+	return rt.Observer
+}
+
+func (o *lockedObserver) Enter(node ast.Node, env *Environment) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.Enter(node, env)
+}
+
+func (o *lockedObserver) Exit(node ast.Node, env *Environment, value Object, interrupt Interruption) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.Exit(node, env, value, interrupt)
+}
+
+func (o *lockedObserver) Call(fn *Function, args []Object, position token.Position, env *Environment) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.Call(fn, args, position, env)
+}
+
+func (o *lockedObserver) Return(fn *Function, value Object, interrupt Interruption) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.Return(fn, value, interrupt)
+}
+
+func (o *lockedObserver) CallBuiltin(name string, args []Object, position token.Position, env *Environment) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.CallBuiltin(name, args, position, env)
+}
+
+func (o *lockedObserver) Raise(err *Error, env *Environment) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.observer.Raise(err, env)
+}
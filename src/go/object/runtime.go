@@ -0,0 +1,225 @@
+package object
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+const DefaultMaxDepth = 10000
+
+// Limits bounds a single evaluation; a zero field means no limit.
+type Limits struct {
+	MaxDepth        int
+	MaxSteps        int
+	MaxAllocations  int
+	MaxBytes        int
+	MaxStringLength int
+	MaxArraySize    int
+}
+
+var DefaultLimits = Limits{MaxDepth: DefaultMaxDepth}
+
+type Frame struct {
+	Function *Function
+	Position token.Position
+}
+
+func (f Frame) Name() string {
+	if f.Function == nil || f.Function.Name == "" {
+		return "<anonymous>"
+	}
+	return f.Function.Name
+}
+
+// Runtime holds the state shared by every environment of one evaluation.
+type Runtime struct {
+	Limits   Limits
+	Frames   []Frame
+	Context  context.Context
+	Observer Observer
+	Importer Importer
+
+	Steps       int
+	Allocations int
+	Bytes       int
+
+	scheduler *Scheduler
+	generator *generator
+}
+
+func NewRuntime(limits Limits) *Runtime {
+	return &Runtime{Limits: limits}
+}
+
+// Reset clears the budgets consumed so far, so a new evaluation starts with
+// the full limits.
+func (rt *Runtime) Reset() {
+	rt.Steps = 0
+	rt.Allocations = 0
+	rt.Bytes = 0
+}
+
+const checkContextEvery = 1024
+
+func (rt *Runtime) Step() *LimitError {
+	rt.Steps += 1
+	if rt.Limits.MaxSteps > 0 && rt.Steps > rt.Limits.MaxSteps {
+		return &LimitError{Limit: STEP_LIMIT, Max: rt.Limits.MaxSteps}
+	}
+	if rt.Context != nil && rt.Steps%checkContextEvery == 0 {
+		return rt.Cancelled()
+	}
+	return nil
+}
+
+func (rt *Runtime) Cancelled() *LimitError {
+	if rt.Context != nil {
+		if err := rt.Context.Err(); err != nil {
+			return &LimitError{Limit: CANCELLED, Err: err}
+		}
+	}
+	return nil
+}
+
+// Allocate charges a newly created object against the allocation budgets.
+// Integers, floats, booleans and null are values and are not charged.
+func (rt *Runtime) Allocate(o Object) *LimitError {
+	var size int
+	switch o := o.(type) {
+	case String:
+		if rt.Limits.MaxStringLength > 0 && len(o) > rt.Limits.MaxStringLength {
+			return &LimitError{Limit: STRING_LENGTH_LIMIT, Max: rt.Limits.MaxStringLength}
+		}
+		size = 16 + len(o)
+	case *Array:
+		if rt.Limits.MaxArraySize > 0 && len(o.Elements) > rt.Limits.MaxArraySize {
+			return &LimitError{Limit: ARRAY_SIZE_LIMIT, Max: rt.Limits.MaxArraySize}
+		}
+		size = 24 + 16*len(o.Elements)
+	case *Hash:
+		size = 48 + 48*len(o.Pairs)
+	case *Function:
+		size = 64
+	case *BigInt:
+		size = 32 + 8*len(o.Value.Bits())
+	case *Decimal:
+		size = 64 + 8*(len(o.Value.Num().Bits())+len(o.Value.Denom().Bits()))
+	default:
+		return nil
+	}
+
+	rt.Allocations += 1
+	rt.Bytes += size
+	if rt.Limits.MaxAllocations > 0 && rt.Allocations > rt.Limits.MaxAllocations {
+		return &LimitError{Limit: ALLOCATION_LIMIT, Max: rt.Limits.MaxAllocations}
+	}
+	if rt.Limits.MaxBytes > 0 && rt.Bytes > rt.Limits.MaxBytes {
+		return &LimitError{Limit: MEMORY_LIMIT, Max: rt.Limits.MaxBytes}
+	}
+	return nil
+}
+
+// Extend charges n elements about to be added to an existing array, or n
+// pairs about to be added to an existing hash. It is checked before anything
+// grows, so an index far past the end fails without allocating.
+func (rt *Runtime) Extend(o Object, n int) *LimitError {
+	var size int
+	switch o := o.(type) {
+	case *Array:
+		if rt.Limits.MaxArraySize > 0 && n > rt.Limits.MaxArraySize-len(o.Elements) {
+			return &LimitError{Limit: ARRAY_SIZE_LIMIT, Max: rt.Limits.MaxArraySize}
+		}
+		size = 16
+	case *Hash:
+		size = 48
+	default:
+		return nil
+	}
+
+	if rt.Limits.MaxBytes > 0 && n > (rt.Limits.MaxBytes-rt.Bytes)/size {
+		return &LimitError{Limit: MEMORY_LIMIT, Max: rt.Limits.MaxBytes}
+	}
+	rt.Bytes += size * n
+	return nil
+}
+
+func (rt *Runtime) Depth() int {
+	return len(rt.Frames)
+}
+
+func (rt *Runtime) Push(frame Frame) *Error {
+	if rt.Limits.MaxDepth > 0 && len(rt.Frames) >= rt.Limits.MaxDepth {
+		frames := make([]Frame, len(rt.Frames))
+		copy(frames, rt.Frames)
+		return &Error{
+			Message: fmt.Sprintf("maximum call depth %d exceeded", rt.Limits.MaxDepth),
+			Frames:  frames,
+		}
+	}
+	rt.Frames = append(rt.Frames, frame)
+	return nil
+}
+
+func (rt *Runtime) Pop() {
+	rt.Frames = rt.Frames[:len(rt.Frames)-1]
+}
+
+// Replace swaps the innermost frame, so tail calls do not grow the stack.
+func (rt *Runtime) Replace(frame Frame) {
+	rt.Frames[len(rt.Frames)-1] = frame
+}
+
+type Limit int
+
+const (
+	CANCELLED Limit = iota
+	STEP_LIMIT
+	ALLOCATION_LIMIT
+	MEMORY_LIMIT
+	STRING_LENGTH_LIMIT
+	ARRAY_SIZE_LIMIT
+)
+
+var limits = [...]string{
+	CANCELLED:           "cancelled",
+	STEP_LIMIT:          "step",
+	ALLOCATION_LIMIT:    "allocation",
+	MEMORY_LIMIT:        "memory",
+	STRING_LENGTH_LIMIT: "string length",
+	ARRAY_SIZE_LIMIT:    "array size",
+}
+
+func (l Limit) String() string {
+	return limits[l]
+}
+
+// LimitError aborts an evaluation that was cancelled or ran out of budget.
+// It is both an Interruption and a Go error, so hosts can detect it with a
+// type assertion or errors.As, and cancellation with errors.Is(err,
+// context.Canceled) or errors.Is(err, context.DeadlineExceeded).
+type LimitError struct {
+	Limit Limit
+	Max   int
+	Err   error
+}
+
+func (le *LimitError) Type() InterruptionType {
+	return ERROR
+}
+
+func (le *LimitError) Inspect() string {
+	return "ERROR: " + le.Error()
+}
+
+func (le *LimitError) Error() string {
+	if le.Limit == CANCELLED {
+		return "evaluation cancelled: " + le.Err.Error()
+	}
+	return fmt.Sprintf("%s limit %d exceeded", le.Limit, le.Max)
+}
+
+func (le *LimitError) Unwrap() error {
+	return le.Err
+}
@@ -0,0 +1,92 @@
+package object
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+func TestRuntimePush(t *testing.T) {
+	f := &Function{Name: "f", Literal: &ast.FunctionLiteral{}}
+
+	rt := NewRuntime(Limits{MaxDepth: 4})
+	for i := 0; i < 4; i += 1 {
+		if err := rt.Push(Frame{Function: f}); err != nil {
+			t.Fatalf("test[%d] - Push() ==> expected: <nil> but was: <%s>", i, err.Inspect())
+		}
+	}
+
+	err := rt.Push(Frame{Function: f})
+	if err == nil {
+		t.Fatalf("Push() ==> expected: <*Error> but was: <nil>")
+	}
+	if "maximum call depth 4 exceeded" != err.Message {
+		t.Errorf("Error.Message ==> expected: <%s> but was: <%s>", "maximum call depth 4 exceeded", err.Message)
+	}
+	if len(err.Frames) != 4 {
+		t.Errorf("len(Error.Frames) ==> expected: <4> but was: <%d>", len(err.Frames))
+	}
+}
+
+func TestTraceback(t *testing.T) {
+	at := func(line, column int) token.Position {
+		return token.Position{Line: line, Column: column}
+	}
+	f := &Function{Name: "f", Literal: &ast.FunctionLiteral{}}
+	g := &Function{Literal: &ast.FunctionLiteral{}}
+	source := "let f = fn(n) {\n    1 + g(n)\n};\nf(1);"
+
+	tests := []struct {
+		err      *Error
+		expected string
+	}{
+		{
+			&Error{Message: "oops", Position: at(4, 1)},
+			"Traceback (most recent call last):\n" +
+				"  line 4, column 1, in <main>\n" +
+				"    f(1);\n" +
+				"    ^\n" +
+				"ERROR: oops\n",
+		},
+		{
+			&Error{Message: "oops", Position: at(2, 7), Frames: []Frame{{f, at(4, 1)}, {g, at(2, 9)}}},
+			"Traceback (most recent call last):\n" +
+				"  line 4, column 1, in <main>\n" +
+				"    f(1);\n" +
+				"    ^\n" +
+				"  line 2, column 9, in f\n" +
+				"    1 + g(n)\n" +
+				"        ^\n" +
+				"  line 2, column 7, in <anonymous>\n" +
+				"    1 + g(n)\n" +
+				"      ^\n" +
+				"ERROR: oops\n",
+		},
+		{
+			&Error{Message: "deep", Position: at(2, 9), Frames: []Frame{{f, at(4, 1)}, {f, at(2, 9)}, {f, at(2, 9)}}},
+			"Traceback (most recent call last):\n" +
+				"  line 4, column 1, in <main>\n" +
+				"    f(1);\n" +
+				"    ^\n" +
+				"  line 2, column 9, in f\n" +
+				"    1 + g(n)\n" +
+				"        ^\n" +
+				"  [previous line repeated 2 more times]\n" +
+				"ERROR: deep\n",
+		},
+		{
+			&Error{Message: "compiled", Frames: []Frame{{f, token.Position{}}}},
+			"Traceback (most recent call last):\n" +
+				"  in <main>\n" +
+				"  in f\n" +
+				"ERROR: compiled\n",
+		},
+	}
+
+	for i, test := range tests {
+		if actual := test.err.Traceback(source); test.expected != actual {
+			t.Errorf("test[%d] - Traceback() ==> expected: <%q> but was: <%q>", i, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package object
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+type entry struct {
+	position token.Position
+	function string
+}
+
+// Traceback renders the error like a Python traceback, most recent call
+// last, quoting the offending lines of source. Runs of identical entries,
+// as left behind by deep recursion, are collapsed into a single line.
+func (e *Error) Traceback(source string) string {
+	entries := make([]entry, 0, len(e.Frames)+1)
+	caller := "<main>"
+	for _, frame := range e.Frames {
+		entries = append(entries, entry{frame.Position, caller})
+		caller = frame.Name()
+	}
+	entries = append(entries, entry{e.Position, caller})
+
+	lines := strings.Split(source, "\n")
+	var out strings.Builder
+	out.WriteString("Traceback (most recent call last):\n")
+	for i := 0; i < len(entries); {
+		current := entries[i]
+		writeEntry(&out, current, lines)
+
+		j := i + 1
+		for j < len(entries) && entries[j] == current {
+			j += 1
+		}
+		if repeated := j - i - 1; repeated > 0 {
+			fmt.Fprintf(&out, "  [previous line repeated %d more times]\n", repeated)
+		}
+		i = j
+	}
+	out.WriteString(e.Inspect() + "\n")
+	return out.String()
+}
+
+func writeEntry(out *strings.Builder, e entry, lines []string) {
+	if !e.position.IsValid() {
+		fmt.Fprintf(out, "  in %s\n", e.function)
+		return
+	}
+
+	fmt.Fprintf(out, "  line %d, column %d, in %s\n", e.position.Line, e.position.Column, e.function)
+	if snippet, ok := Snippet(lines, e.position); ok {
+		out.WriteString(snippet)
+	}
+}
+
+// Snippet quotes the source line at position with a caret under its column.
+func Snippet(lines []string, position token.Position) (string, bool) {
+	if position.Line > len(lines) {
+		return "", false
+	}
+
+	line := strings.TrimRight(lines[position.Line-1], " \t\r")
+	trimmed := strings.TrimLeft(line, " \t")
+	if trimmed == "" {
+		return "", false
+	}
+
+	column := position.Column - (len(line) - len(trimmed))
+	caret := ""
+	if column >= 1 && column <= len(trimmed) {
+		caret = "    " + strings.Repeat(" ", column-1) + "^\n"
+	}
+	return "    " + trimmed + "\n" + caret, true
+}
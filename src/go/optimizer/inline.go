@@ -0,0 +1,319 @@
+package optimizer
+
+import (
+	"slices"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+)
+
+// maxInlineSize is the largest body, in nodes, that is copied into callers.
+const maxInlineSize = 24
+
+type candidate struct {
+	index      int
+	parameters []string
+	body       ast.Expression
+}
+
+// inline replaces calls to small top-level functions with their bodies.
+// Only functions whose name always refers to the same function, whose body
+// is a single expression without side effects on its own scope, and whose
+// free variables can never be shadowed at a call site are candidates, and
+// only calls whose arguments cannot raise an error are replaced.
+//
+// An inlined call pushes no frame: it is missing from the frames of an
+// error raised in its body and does not count against the call depth.
+func inline(program *ast.Program) {
+	candidates := findCandidates(program)
+	if len(candidates) == 0 {
+		return
+	}
+
+	skip := protected(program)
+	declared := map[string]bool{}
+	for i, stmt := range program.Statements {
+		bound := boundArguments(stmt, declared)
+		program.Statements[i] = ast.Modify(stmt, func(node ast.Node) ast.Node {
+			call, ok := node.(*ast.CallExpression)
+			if !ok || skip[node] || !bound[node] {
+				return node
+			}
+			callee, ok := call.Callee.(*ast.Identifier)
+			if !ok {
+				return node
+			}
+			fn, ok := candidates[callee.Value]
+			if !ok || fn.index >= i || len(call.Arguments) != len(fn.parameters) {
+				return node
+			}
+			return substitute(fn, call.Arguments)
+		}).(ast.Statement)
+
+		switch stmt := program.Statements[i].(type) {
+		case *ast.LetDeclaration:
+			declared[stmt.Name.Value] = true
+		case *ast.ImportStatement:
+			declared[stmt.Name.Value] = true
+		}
+	}
+}
+
+// boundArguments finds the calls in stmt whose arguments are literals or
+// names bound wherever the call runs: parameters of a function around it, or
+// globals that an earlier statement declared. Evaluating such an argument
+// cannot raise an error, so an inlined body may evaluate it late or not at
+// all without a difference.
+func boundArguments(stmt ast.Statement, declared map[string]bool) map[ast.Node]bool {
+	bound := map[ast.Node]bool{}
+	ast.Walk(stmt, ast.WalkFuncs{
+		Pre: func(node ast.Node, path []ast.Node) bool {
+			call, ok := node.(*ast.CallExpression)
+			if !ok {
+				return true
+			}
+			for _, arg := range call.Arguments {
+				if !isAtom(arg) {
+					return true
+				}
+				if ident, ok := arg.(*ast.Identifier); ok && !declared[ident.Value] && !isParameter(ident.Value, path) {
+					return true
+				}
+			}
+			bound[call] = true
+			return true
+		},
+	})
+	return bound
+}
+
+func isParameter(name string, path []ast.Node) bool {
+	for _, ancestor := range path {
+		if fn, ok := ancestor.(*ast.FunctionLiteral); ok {
+			for _, param := range fn.Parameters {
+				if param.Value == name {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func substitute(fn *candidate, args []ast.Expression) ast.Expression {
+	body := ast.Clone(fn.body).(ast.Expression)
+	return ast.Modify(body, func(node ast.Node) ast.Node {
+		ident, ok := node.(*ast.Identifier)
+		if !ok {
+			return node
+		}
+		for i, parameter := range fn.parameters {
+			if ident.Value == parameter {
+				return ast.Clone(args[i])
+			}
+		}
+		return node
+	}).(ast.Expression)
+}
+
+func isAtom(node ast.Expression) bool {
+	switch node.(type) {
+	case *ast.Identifier, *ast.IntegerLiteral, *ast.FloatLiteral, *ast.DecimalLiteral, *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral:
+		return true
+	}
+	return false
+}
+
+func findCandidates(program *ast.Program) map[string]*candidate {
+	globals := map[string]int{}
+	locals := map[string]bool{}
+	assigned := map[string]bool{}
+	ast.Walk(program, ast.WalkFuncs{
+		Pre: func(node ast.Node, path []ast.Node) bool {
+			local := false
+			for _, ancestor := range path {
+				if _, ok := ancestor.(*ast.FunctionLiteral); ok {
+					local = true
+				}
+			}
+
+			switch node := node.(type) {
+			case *ast.LetDeclaration:
+				if local {
+					locals[node.Name.Value] = true
+				} else {
+					globals[node.Name.Value] += 1
+				}
+			case *ast.MacroStatement:
+				globals[node.Name.Value] += 1
+			case *ast.ImportStatement:
+				globals[node.Name.Value] += 1
+			case *ast.FunctionLiteral:
+				for _, param := range node.Parameters {
+					locals[param.Value] = true
+				}
+			case *ast.AssignmentExpression:
+				if ident, ok := node.LValue.(*ast.Identifier); ok {
+					assigned[ident.Value] = true
+					if local {
+						locals[ident.Value] = true
+					}
+				}
+			}
+			return true
+		},
+	})
+
+	candidates := map[string]*candidate{}
+	for i, stmt := range program.Statements {
+		let, ok := stmt.(*ast.LetDeclaration)
+		if !ok {
+			continue
+		}
+		name := let.Name.Value
+		if globals[name] != 1 || locals[name] || assigned[name] {
+			continue
+		}
+		if fn, ok := let.Value.(*ast.FunctionLiteral); ok {
+			if c, ok := toCandidate(fn, locals); ok {
+				c.index = i
+				candidates[name] = c
+			}
+		}
+	}
+
+	cyclic := []string{}
+	for name := range candidates {
+		if recursive(name, candidates) {
+			cyclic = append(cyclic, name)
+		}
+	}
+	for _, name := range cyclic {
+		delete(candidates, name)
+	}
+	return candidates
+}
+
+func toCandidate(fn *ast.FunctionLiteral, locals map[string]bool) (*candidate, bool) {
+	if len(fn.Body.Statements) != 1 {
+		return nil, false
+	}
+	var body ast.Expression
+	switch stmt := fn.Body.Statements[0].(type) {
+	case *ast.ExpressionStatement:
+		body = stmt.Expression
+	case *ast.ReturnStatement:
+		body = stmt.ReturnValue
+	}
+	if body == nil {
+		return nil, false
+	}
+	if size, ok := inlinable(body); !ok || size > maxInlineSize {
+		return nil, false
+	}
+
+	uses := map[string]int{}
+	ast.Inspect(body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Identifier); ok {
+			uses[ident.Value] += 1
+		}
+		return true
+	})
+
+	parameters := []string{}
+	for _, param := range fn.Parameters {
+		// an unused parameter would drop the evaluation of its argument
+		if uses[param.Value] == 0 || slices.Contains(parameters, param.Value) {
+			return nil, false
+		}
+		parameters = append(parameters, param.Value)
+	}
+	for name := range uses {
+		if !slices.Contains(parameters, name) && locals[name] {
+			return nil, false
+		}
+	}
+	return &candidate{parameters: parameters, body: body}, true
+}
+
+// inlinable reports the size of an expression that can be copied into any
+// scope: one that declares, assigns and captures nothing.
+func inlinable(node ast.Expression) (int, bool) {
+	size := 1
+	children := []ast.Expression{}
+	switch node := node.(type) {
+	case *ast.Identifier, *ast.IntegerLiteral, *ast.FloatLiteral, *ast.DecimalLiteral, *ast.StringLiteral, *ast.BooleanLiteral, *ast.NullLiteral:
+	case *ast.UnaryExpression:
+		children = append(children, node.Right)
+	case *ast.BinaryExpression:
+		children = append(children, node.Left, node.Right)
+	case *ast.LogicalExpression:
+		children = append(children, node.Left, node.Right)
+	case *ast.SubscriptExpression:
+		children = append(children, node.Base, node.Subscript)
+	case *ast.ArrayLiteral:
+		children = append(children, node.Elements...)
+	case *ast.HashLiteral:
+		for _, key := range node.Keys {
+			children = append(children, key, node.Pairs[key])
+		}
+	case *ast.CallExpression:
+		if isQuote(node) {
+			return 0, false
+		}
+		children = append(children, node.Callee)
+		children = append(children, node.Arguments...)
+	case *ast.ConditionalExpression:
+		children = append(children, node.Condition)
+		for _, block := range []*ast.BlockStatement{node.Consequence, node.Alternative} {
+			if block == nil {
+				continue
+			}
+			for _, stmt := range block.Statements {
+				es, ok := stmt.(*ast.ExpressionStatement)
+				if !ok {
+					return 0, false
+				}
+				children = append(children, es.Expression)
+			}
+		}
+	default:
+		return 0, false
+	}
+
+	for _, child := range children {
+		n, ok := inlinable(child)
+		if !ok {
+			return 0, false
+		}
+		size += n
+	}
+	return size, true
+}
+
+// recursive reports whether the body of start calls start again, directly or
+// through other candidates.
+func recursive(start string, candidates map[string]*candidate) bool {
+	visiting := map[string]bool{}
+	var reaches func(name string) bool
+	reaches = func(name string) bool {
+		if visiting[name] {
+			return false
+		}
+		visiting[name] = true
+		found := false
+		ast.Inspect(candidates[name].body, func(node ast.Node) bool {
+			ident, ok := node.(*ast.Identifier)
+			if !ok || found {
+				return !found
+			}
+			if ident.Value == start {
+				found = true
+			} else if _, ok := candidates[ident.Value]; ok {
+				found = reaches(ident.Value)
+			}
+			return !found
+		})
+		return found
+	}
+	return reaches(start)
+}
@@ -0,0 +1,341 @@
+package optimizer
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+// A Pass rewrites a program in place without changing what it evaluates to.
+// Inline is the one pass that changes how an error is reported: the calls
+// it replaces leave no frame in a traceback.
+type Pass struct {
+	Name string
+	Run  func(program *ast.Program)
+}
+
+var (
+	Fold        = Pass{Name: "fold", Run: fold}
+	Branches    = Pass{Name: "branches", Run: pruneBranches}
+	Unreachable = Pass{Name: "unreachable", Run: dropUnreachable}
+	Inline      = Pass{Name: "inline", Run: inline}
+)
+
+var Passes = []Pass{Fold, Branches, Unreachable, Inline}
+
+var levels = [...][]Pass{
+	0: {},
+	1: {Fold, Branches, Unreachable},
+	2: {Fold, Branches, Unreachable, Inline},
+}
+
+const MaxLevel = len(levels) - 1
+
+// rounds bounds how many times the passes are repeated to let one pass
+// expose work for another, e.g. folding the arguments of an inlined call.
+const rounds = 4
+
+func Level(level int) ([]Pass, error) {
+	if level < 0 || level > MaxLevel {
+		return nil, fmt.Errorf("unknown optimization level: %d (available: 0-%d)", level, MaxLevel)
+	}
+	return levels[level], nil
+}
+
+func Lookup(names ...string) ([]Pass, error) {
+	passes := []Pass{}
+	for _, name := range names {
+		found := false
+		for _, pass := range Passes {
+			if pass.Name == name {
+				passes = append(passes, pass)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown optimization pass: %q (available: %s)", name, strings.Join(Names(), ", "))
+		}
+	}
+	return passes, nil
+}
+
+func Names() []string {
+	names := []string{}
+	for _, pass := range Passes {
+		names = append(names, pass.Name)
+	}
+	return names
+}
+
+// Optimize returns a copy of program rewritten by passes until it stops
+// changing. The program should already have its macros expanded.
+func Optimize(program *ast.Program, passes ...Pass) *ast.Program {
+	optimized := ast.Clone(program).(*ast.Program)
+	if len(passes) == 0 {
+		return optimized
+	}
+
+	before := optimized.String()
+	for range rounds {
+		for _, pass := range passes {
+			pass.Run(optimized)
+		}
+		after := optimized.String()
+		if after == before {
+			break
+		}
+		before = after
+	}
+	return optimized
+}
+
+// protected collects the nodes that must be left as written: quoted code is
+// data, and macro bodies build code rather than run it.
+func protected(program *ast.Program) map[ast.Node]bool {
+	nodes := map[ast.Node]bool{}
+	protect := func(node ast.Node) bool {
+		nodes[node] = true
+		return true
+	}
+	ast.Inspect(program, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.MacroStatement:
+			ast.Inspect(node, protect)
+			return false
+		case *ast.CallExpression:
+			if isQuote(node) {
+				ast.Inspect(node, protect)
+				return false
+			}
+		}
+		return true
+	})
+	return nodes
+}
+
+func isQuote(node *ast.CallExpression) bool {
+	callee, ok := node.Callee.(*ast.Identifier)
+	return ok && (callee.Value == "quote" || callee.Value == "unquote")
+}
+
+func modify(program *ast.Program, modifier func(ast.Node) ast.Node) {
+	skip := protected(program)
+	ast.Modify(program, func(node ast.Node) ast.Node {
+		if skip[node] {
+			return node
+		}
+		return modifier(node)
+	})
+}
+
+func fold(program *ast.Program) {
+	modify(program, func(node ast.Node) ast.Node {
+		switch node := node.(type) {
+		case *ast.UnaryExpression:
+			right, ok := constant(node.Right)
+			if !ok {
+				return node
+			}
+			value, interrupt := evaluator.Unary(node.Operator, right)
+			if interrupt != nil {
+				return node
+			}
+			return literal(value, node)
+		case *ast.BinaryExpression:
+			left, ok := constant(node.Left)
+			if !ok {
+				return node
+			}
+			right, ok := constant(node.Right)
+			if !ok {
+				return node
+			}
+			value, interrupt := evaluator.Binary(node.Operator, left, right)
+			if interrupt != nil {
+				return node
+			}
+			return literal(value, node)
+		case *ast.LogicalExpression:
+			left, ok := constant(node.Left)
+			if !ok {
+				return node
+			}
+			switch {
+			case node.Operator == "or" && bool(evaluator.IsTruthy(left)):
+				return node.Left
+			case node.Operator == "and" && !bool(evaluator.IsTruthy(left)):
+				return node.Left
+			case node.Operator == "or" || node.Operator == "and":
+				return node.Right
+			}
+		}
+		return node
+	})
+}
+
+func constant(node ast.Expression) (object.Object, bool) {
+	switch node := node.(type) {
+	case *ast.IntegerLiteral:
+		if node.Big != nil {
+			return &object.BigInt{Value: node.Big}, true
+		}
+		return object.Integer(node.Value), true
+	case *ast.DecimalLiteral:
+		return &object.Decimal{Value: node.Value}, true
+	case *ast.FloatLiteral:
+		return object.Float(node.Value), true
+	case *ast.BooleanLiteral:
+		return object.Boolean(node.Value), true
+	case *ast.StringLiteral:
+		return object.String(node.Value), true
+	case *ast.NullLiteral:
+		return evaluator.NULL, true
+	}
+	return nil, false
+}
+
+// literal turns a folded value back into a node placed where the folded
+// expression was, or keeps the expression if the value cannot be written as
+// a literal.
+func literal(value object.Object, node ast.Expression) ast.Expression {
+	var result ast.Expression
+	switch value := value.(type) {
+	case object.Float:
+		if math.IsInf(float64(value), 0) || math.IsNaN(float64(value)) {
+			return node
+		}
+		result = evaluator.ToNode(value)
+	case object.Integer, *object.BigInt, *object.Decimal, object.Boolean, object.String:
+		result = evaluator.ToNode(value)
+	default:
+		return node
+	}
+
+	position := ast.Position(node)
+	switch result := result.(type) {
+	case *ast.IntegerLiteral:
+		result.Token.Position = position
+	case *ast.FloatLiteral:
+		result.Token.Position = position
+	case *ast.DecimalLiteral:
+		result.Token.Position = position
+	case *ast.BooleanLiteral:
+		result.Token.Position = position
+	case *ast.StringLiteral:
+		result.Token.Position = position
+	}
+	return result
+}
+
+func null(node ast.Expression) *ast.NullLiteral {
+	return &ast.NullLiteral{
+		Token: token.Token{
+			Type:     token.NULL,
+			Literal:  "null",
+			Position: ast.Position(node),
+		},
+	}
+}
+
+func pruneBranches(program *ast.Program) {
+	modify(program, func(node ast.Node) ast.Node {
+		switch node := node.(type) {
+		case *ast.Program:
+			node.Statements = pruneStatements(node.Statements)
+		case *ast.BlockStatement:
+			node.Statements = pruneStatements(node.Statements)
+		case *ast.ConditionalExpression:
+			chosen, ok := branch(node)
+			switch {
+			case !ok:
+			case chosen == nil:
+				return null(node)
+			case len(chosen.Statements) == 1:
+				if stmt, ok := chosen.Statements[0].(*ast.ExpressionStatement); ok {
+					return stmt.Expression
+				}
+			}
+		}
+		return node
+	})
+}
+
+// branch picks the block a conditional with a constant condition runs, or
+// nil if it runs neither.
+func branch(node *ast.ConditionalExpression) (*ast.BlockStatement, bool) {
+	condition, ok := constant(node.Condition)
+	if !ok {
+		return nil, false
+	}
+	if evaluator.IsTruthy(condition) {
+		return node.Consequence, true
+	}
+	return node.Alternative, true
+}
+
+// pruneStatements splices the chosen block of every constant conditional
+// statement into stmts and drops constants whose value is never used.
+// Blocks do not open a scope, so their declarations keep their meaning.
+func pruneStatements(stmts []ast.Statement) []ast.Statement {
+	pruned := make([]ast.Statement, 0, len(stmts))
+	for i, stmt := range stmts {
+		es, ok := stmt.(*ast.ExpressionStatement)
+		if !ok {
+			pruned = append(pruned, stmt)
+			continue
+		}
+		last := i == len(stmts)-1
+		if _, ok := constant(es.Expression); ok && !last {
+			continue
+		}
+		conditional, ok := es.Expression.(*ast.ConditionalExpression)
+		if !ok {
+			pruned = append(pruned, stmt)
+			continue
+		}
+		chosen, ok := branch(conditional)
+		switch {
+		case !ok:
+			pruned = append(pruned, stmt)
+		case chosen == nil && last:
+			pruned = append(pruned, &ast.ExpressionStatement{
+				Token:      es.Token,
+				Expression: null(conditional),
+			})
+		case chosen == nil:
+		case len(chosen.Statements) == 0 && last:
+			// an empty block evaluates to nothing, which no statement can
+			// stand in for
+			pruned = append(pruned, stmt)
+		default:
+			pruned = append(pruned, chosen.Statements...)
+		}
+	}
+	return pruned
+}
+
+func dropUnreachable(program *ast.Program) {
+	modify(program, func(node ast.Node) ast.Node {
+		switch node := node.(type) {
+		case *ast.Program:
+			node.Statements = truncate(node.Statements)
+		case *ast.BlockStatement:
+			node.Statements = truncate(node.Statements)
+		}
+		return node
+	})
+}
+
+func truncate(stmts []ast.Statement) []ast.Statement {
+	for i, stmt := range stmts {
+		if _, ok := stmt.(*ast.ReturnStatement); ok {
+			return stmts[:i+1]
+		}
+	}
+	return stmts
+}
@@ -0,0 +1,207 @@
+package optimizer
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+type OptimizerTest struct {
+	input    string
+	expected string
+}
+
+func TestFold(t *testing.T) {
+	tests := []OptimizerTest{
+		{`1 + 2 * 3`, `7;`},
+		{`-(4) + 10.0 / 4`, `-1.5;`},
+		{`7 / 2 + 7 % 2`, `4;`},
+		{`1.0 / 0`, `(1.0/0);`},
+		{`"mon" + "key"`, `"monkey";`},
+		{`1 < 2 == true`, `true;`},
+		{`"a" != "b"`, `true;`},
+		{`!0`, `true;`},
+		{`null == null`, `true;`},
+		{`x + 1 * 2`, `(x+2);`},
+		{`1 / 0`, `(1/0);`},
+		{`1 + true`, `(1+true);`},
+		{`-"a"`, `(-"a");`},
+		{`fn(x) { x * (2 + 3) }`, `fn(x){(x*5);};`},
+		{`quote(1 + 2)`, `quote((1+2));`},
+		{`macro m(a) { quote(unquote(a) + (1 + 1)) }`, `macro m(a){quote((unquote(a)+(1+1)));}`},
+	}
+
+	testOptimize(t, tests, Fold)
+}
+
+func TestBranches(t *testing.T) {
+	tests := []OptimizerTest{
+		{`if (true) { 1 } else { 2 }`, `1;`},
+		{`if (0) { 1 } else { 2 }`, `2;`},
+		{`let x = if (false) { 1 }`, `let x=null;`},
+		{`if (false) { 1 }; 2`, `2;`},
+		{`if (false) { 1 }`, `null;`},
+		{`if (true) { let a = 1; a }`, `let a=1;a;`},
+		{`if ("") { 1 } else { let b = 2; b }; b`, `let b=2;b;b;`},
+		{`if (x) { 1 } else { 2 }`, `if x {1;} else {2;};`},
+		{`1; "a"; x`, `x;`},
+		{`fn() { if (true) { return 1 }; 2 }`, `fn(){return 1;2;};`},
+		{`if (true) { }`, `if true {};`},
+	}
+
+	testOptimize(t, tests, Branches)
+}
+
+func TestUnreachable(t *testing.T) {
+	tests := []OptimizerTest{
+		{`return 1; puts(2)`, `return 1;`},
+		{`fn() { let a = 1; return a; a = 2; a }`, `fn(){let a=1;return a;};`},
+		{`if (x) { return 1; 2 } else { 3 }; 4`, `if x {return 1;} else {3;};4;`},
+		{`let a = 1; a`, `let a=1;a;`},
+	}
+
+	testOptimize(t, tests, Unreachable)
+}
+
+func TestInline(t *testing.T) {
+	tests := []OptimizerTest{
+		{
+			`let sq = fn(x) { x * x }; let y = 3; sq(y) + sq(2)`,
+			`let sq=fn(x){(x*x);};let y=3;((y*y)+(2*2));`,
+		},
+		{
+			`let add = fn(a, b) { return a + b + k }; let k = 1; add(1, 2)`,
+			`let add=fn(a,b){return ((a+b)+k);};let k=1;((1+2)+k);`,
+		},
+		{
+			`let first = fn(xs) { xs[0] }; fn(ys) { first(ys) }`,
+			`let first=fn(xs){(xs[0]);};fn(ys){(ys[0]);};`,
+		},
+		// recursive
+		{
+			`let f = fn(n) { if (n < 1) { 0 } else { f(n - 1) } }; f(3)`,
+			`let f=fn(n){if (n<1) {0;} else {f((n-1));};};f(3);`,
+		},
+		// mutually recursive
+		{
+			`let f = fn(n) { g(n) }; let g = fn(n) { f(n) }; f(1)`,
+			`let f=fn(n){g(n);};let g=fn(n){f(n);};f(1);`,
+		},
+		// called before it is declared
+		{
+			`f(1); let f = fn(x) { x }`,
+			`f(1);let f=fn(x){x;};`,
+		},
+		// arguments that are not atoms
+		{
+			`let id = fn(x) { x }; id(puts(1))`,
+			`let id=fn(x){x;};id(puts(1));`,
+		},
+		// wrong number of arguments
+		{
+			`let id = fn(x) { x }; id()`,
+			`let id=fn(x){x;};id();`,
+		},
+		// argument that may be unbound
+		{
+			`let pick = fn(c, x) { if (c) { x } else { 0 } }; pick(false, nope)`,
+			`let pick=fn(c,x){if c {x;} else {0;};};pick(false,nope);`,
+		},
+		// argument declared after the call
+		{
+			`let id = fn(x) { x }; let g = fn() { id(y) }; let y = 1;`,
+			`let id=fn(x){x;};let g=fn(){id(y);};let y=1;`,
+		},
+		// unused parameter
+		{
+			`let k = fn(x, y) { x }; k(1, z)`,
+			`let k=fn(x,y){x;};k(1,z);`,
+		},
+		// free variable shadowed by a local
+		{
+			`let f = fn(x) { x + n }; let g = fn(n) { f(1) }`,
+			`let f=fn(x){(x+n);};let g=fn(n){f(1);};`,
+		},
+		// name rebound
+		{
+			`let f = fn(x) { x }; f = fn(x) { 0 }; f(1)`,
+			`let f=fn(x){x;};(f=fn(x){0;});f(1);`,
+		},
+		// body with statements
+		{
+			`let f = fn(x) { let y = x; y }; f(1)`,
+			`let f=fn(x){let y=x;y;};f(1);`,
+		},
+		// body with a closure
+		{
+			`let f = fn(x) { fn() { x } }; f(1)`,
+			`let f=fn(x){fn(){x;};};f(1);`,
+		},
+	}
+
+	testOptimize(t, tests, Inline)
+}
+
+func TestOptimizeLevels(t *testing.T) {
+	input := `
+		let sq = fn(x) { x * x };
+		let debug = false;
+		if (debug) { puts("squaring") };
+		sq(2 + 1) + sq(2)`
+	tests := []struct {
+		level    int
+		expected string
+	}{
+		{0, `let sq=fn(x){(x*x);};let debug=false;if debug {puts("squaring");};(sq((2+1))+sq(2));`},
+		{1, `let sq=fn(x){(x*x);};let debug=false;if debug {puts("squaring");};(sq(3)+sq(2));`},
+		{2, `let sq=fn(x){(x*x);};let debug=false;if debug {puts("squaring");};13;`},
+	}
+
+	for i, test := range tests {
+		passes, err := Level(test.level)
+		if err != nil {
+			t.Fatalf("test[%d] - Level(%d) ==> unexpected error: %s", i, test.level, err)
+		}
+		actual := Optimize(parse(t, input), passes...).String()
+		if actual != test.expected {
+			t.Errorf("test[%d] - Optimize() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+
+	if _, err := Level(MaxLevel + 1); err == nil {
+		t.Errorf("Level(%d) ==> expected an error", MaxLevel+1)
+	}
+	if _, err := Lookup("fold", "vectorize"); err == nil {
+		t.Errorf("Lookup(\"vectorize\") ==> expected an error")
+	}
+}
+
+func TestOptimizeIsNonDestructive(t *testing.T) {
+	program := parse(t, `let f = fn(x) { x + 1 }; if (true) { f(1 + 2) }`)
+	expected := program.String()
+
+	Optimize(program, Passes...)
+
+	if actual := program.String(); actual != expected {
+		t.Errorf("program.String() ==> expected: <%s> but was: <%s>", expected, actual)
+	}
+}
+
+func testOptimize(t *testing.T, tests []OptimizerTest, passes ...Pass) {
+	for i, test := range tests {
+		actual := Optimize(parse(t, test.input), passes...).String()
+		if actual != test.expected {
+			t.Errorf("test[%d] - Optimize() ==> expected: <%s> but was: <%s>", i, test.expected, actual)
+		}
+	}
+}
+
+func parse(t *testing.T, input string) *ast.Program {
+	p := parser.NewParser(input, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("parser errors for %q: %v", input, p.Errors())
+	}
+	return program
+}
@@ -2,8 +2,10 @@ package parser
 
 import (
 	"fmt"
+	"math/big"
 	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/lexer"
@@ -18,6 +20,9 @@ type Parser struct {
 	tok    token.Token
 	errors []string
 
+	// depth counts the blocks enclosing the current token.
+	depth int
+
 	rules map[token.TokenType]ParserRule
 }
 
@@ -50,7 +55,11 @@ type ParserRule struct {
 }
 
 func NewParser(input string, trace bool) *Parser {
-	l := lexer.NewLexer(input)
+	return NewParserAtLine(input, 1, trace)
+}
+
+func NewParserAtLine(input string, line int, trace bool) *Parser {
+	l := lexer.NewLexerAtLine(input, line)
 	p := &Parser{
 		l:      l,
 		errors: []string{},
@@ -62,7 +71,9 @@ func NewParser(input string, trace bool) *Parser {
 		token.ILLEGAL: {p.reportIllegalToken, nil, NONE},
 		token.EOF:     {nil, nil, NONE},
 		token.IDENT:   {p.parseIdentifier, nil, NONE},
-		token.NUMBER:  {p.parseNumberLiteral, nil, NONE},
+		token.INT:     {p.parseIntegerLiteral, nil, NONE},
+		token.FLOAT:   {p.parseFloatLiteral, nil, NONE},
+		token.DECIMAL: {p.parseDecimalLiteral, nil, NONE},
 		token.STRING:  {p.parseStringLiteral, nil, NONE},
 		token.ASSIGN:  {nil, p.parseAssignmentExpression, ASSIGNMENT},
 		token.PLUS:    {nil, p.parseBinaryExpression, TERM},
@@ -70,6 +81,7 @@ func NewParser(input string, trace bool) *Parser {
 		token.BANG:    {p.parseUnaryExpression, nil, NONE},
 		token.STAR:    {nil, p.parseBinaryExpression, FACTOR},
 		token.SLASH:   {nil, p.parseBinaryExpression, FACTOR},
+		token.PERCENT: {nil, p.parseBinaryExpression, FACTOR},
 		token.LT:      {nil, p.parseBinaryExpression, COMPARISON},
 		token.GT:      {nil, p.parseBinaryExpression, COMPARISON},
 		token.EQ:      {nil, p.parseBinaryExpression, EQUALITY},
@@ -94,6 +106,9 @@ func NewParser(input string, trace bool) *Parser {
 		token.OR:      {nil, p.parseLogicalExpression, OR},
 		token.AND:     {nil, p.parseLogicalExpression, AND},
 		token.MACRO:   {nil, nil, NONE},
+		token.YIELD:   {p.parseYieldExpression, nil, NONE},
+		token.IMPORT:  {nil, nil, NONE},
+		token.EXPORT:  {nil, nil, NONE},
 	}
 
 	return p
@@ -120,6 +135,10 @@ func (p *Parser) Errors() []string {
 	return p.errors
 }
 
+func (p *Parser) Comments() []token.Token {
+	return p.l.Comments()
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	if p.trace {
 		defer un(trace("ParseStatement"))
@@ -132,6 +151,10 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.MACRO:
 		return p.parseMacroStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	case token.SEMI:
 		p.skip(token.SEMI)
 		return nil
@@ -206,6 +229,9 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 	block := &ast.BlockStatement{Token: p.tok}
 
+	p.depth += 1
+	defer func() { p.depth -= 1 }()
+
 	p.next()
 
 	statements := []ast.Statement{}
@@ -255,6 +281,70 @@ func (p *Parser) parseMacroStatement() *ast.MacroStatement {
 	return stmt
 }
 
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	if p.trace {
+		defer un(trace("ParseImportStatement"))
+	}
+
+	stmt := &ast.ImportStatement{Token: p.tok}
+	if !p.expect(token.STRING) {
+		return nil
+	}
+
+	stmt.Path = p.parseStringLiteral().(*ast.StringLiteral)
+
+	// as is only a keyword here, so it stays free for identifiers.
+	if next := p.peek1(); next.Type != token.IDENT || next.Literal != "as" {
+		p.error("expected next token to be <as> but was <%s>", next.Type)
+		return nil
+	}
+	p.next()
+
+	if !p.expect(token.IDENT) {
+		return nil
+	}
+
+	stmt.Name = p.parseIdentifier().(*ast.Identifier)
+
+	if p.peek1().Type == token.SEMI {
+		p.next()
+	}
+
+	return stmt
+}
+
+func (p *Parser) parseExportStatement() ast.Statement {
+	if p.trace {
+		defer un(trace("ParseExportStatement"))
+	}
+
+	if p.depth > 0 {
+		p.error("export is only allowed at the top level of a program")
+	}
+
+	switch p.peek1().Type {
+	case token.LET:
+		p.next()
+		stmt := p.parseLetStatement()
+		if stmt == nil {
+			return nil
+		}
+		stmt.Exported = true
+		return stmt
+	case token.MACRO:
+		p.next()
+		stmt := p.parseMacroStatement()
+		if stmt == nil {
+			return nil
+		}
+		stmt.Exported = true
+		return stmt
+	default:
+		p.error("expected next token to be <LET> or <MACRO> but was <%s>", p.peek1().Type)
+		return nil
+	}
+}
+
 func (p *Parser) parseExpression(rightPrecedence precedence) ast.Expression {
 	if p.trace {
 		defer un(trace("ParseExpression"))
@@ -308,6 +398,20 @@ func (p *Parser) parseUnaryExpression() ast.Expression {
 	return expr
 }
 
+func (p *Parser) parseYieldExpression() ast.Expression {
+	if p.trace {
+		defer un(trace("ParseYieldExpression"))
+	}
+
+	expr := &ast.YieldExpression{Token: p.tok}
+
+	p.next()
+
+	expr.Value = p.parseExpression(ASSIGNMENT - 1) // right associativity
+
+	return expr
+}
+
 func (p *Parser) parseBinaryExpression(left ast.Expression) ast.Expression {
 	if p.trace {
 		defer un(trace("ParseBinaryExpression"))
@@ -480,9 +584,25 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.tok, Value: p.tok.Literal}
 }
 
-func (p *Parser) parseNumberLiteral() ast.Expression {
+func (p *Parser) parseIntegerLiteral() ast.Expression {
+	if p.trace {
+		defer un(trace("ParseIntegerLiteral"))
+	}
+
+	value, ok := new(big.Int).SetString(p.tok.Literal, 10)
+	if !ok {
+		p.error("cannot parse integer %q", p.tok.Literal)
+		return nil
+	}
+	if !value.IsInt64() {
+		return &ast.IntegerLiteral{Token: p.tok, Big: value}
+	}
+	return &ast.IntegerLiteral{Token: p.tok, Value: value.Int64()}
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
 	if p.trace {
-		defer un(trace("ParseNumberLiteral"))
+		defer un(trace("ParseFloatLiteral"))
 	}
 
 	value, err := strconv.ParseFloat(p.tok.Literal, 64)
@@ -490,7 +610,20 @@ func (p *Parser) parseNumberLiteral() ast.Expression {
 		p.error("cannot parse float %q", p.tok.Literal)
 		return nil
 	}
-	return &ast.NumberLiteral{Token: p.tok, Value: value}
+	return &ast.FloatLiteral{Token: p.tok, Value: value}
+}
+
+func (p *Parser) parseDecimalLiteral() ast.Expression {
+	if p.trace {
+		defer un(trace("ParseDecimalLiteral"))
+	}
+
+	value, ok := new(big.Rat).SetString(strings.TrimSuffix(p.tok.Literal, "d"))
+	if !ok {
+		p.error("cannot parse decimal %q", p.tok.Literal)
+		return nil
+	}
+	return &ast.DecimalLiteral{Token: p.tok, Value: value}
 }
 
 func (p *Parser) parseStringLiteral() ast.Expression {
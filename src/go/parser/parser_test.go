@@ -25,6 +25,7 @@ func (rs ReturnStatementTest) node()       {}
 func (es ExpressionStatementTest) node()   {}
 func (bs BlockStatementTest) node()        {}
 func (ms MacroStatementTest) node()        {}
+func (is ImportStatementTest) node()       {}
 func (ue UnaryExpressionTest) node()       {}
 func (be BinaryExpressionTest) node()      {}
 func (ce ConditionalExpressionTest) node() {}
@@ -33,11 +34,13 @@ func (ce CallExpressionTest) node()        {}
 func (ae AssignmentExpressionTest) node()  {}
 func (se SubscriptExpressionTest) node()   {}
 func (i IdentifierTest) node()             {}
-func (nl NumberLiteralTest) node()         {}
+func (il IntegerLiteralTest) node()        {}
+func (fl FloatLiteralTest) node()          {}
 func (bl BooleanLiteralTest) node()        {}
 func (sl StringLiteralTest) node()         {}
 func (al ArrayLiteralTest) node()          {}
 func (hl HashLiteralTest) node()           {}
+func (ye YieldExpressionTest) node()       {}
 
 type ProgramTest struct {
 	Statements []StatementTest
@@ -53,6 +56,7 @@ func (rs ReturnStatementTest) statementNode()     {}
 func (es ExpressionStatementTest) statementNode() {}
 func (bs BlockStatementTest) statementNode()      {}
 func (ms MacroStatementTest) statementNode()      {}
+func (is ImportStatementTest) statementNode()     {}
 
 type LetDeclarationTest struct {
 	Name   IdentifierTest
@@ -80,6 +84,12 @@ type MacroStatementTest struct {
 	Body       BlockStatementTest
 }
 
+type ImportStatementTest struct {
+	Path   string
+	Name   IdentifierTest
+	String string
+}
+
 type ExpressionTest interface {
 	NodeTest
 	expressionNode()
@@ -93,11 +103,13 @@ func (ce CallExpressionTest) expressionNode()        {}
 func (ae AssignmentExpressionTest) expressionNode()  {}
 func (se SubscriptExpressionTest) expressionNode()   {}
 func (i IdentifierTest) expressionNode()             {}
-func (nl NumberLiteralTest) expressionNode()         {}
+func (il IntegerLiteralTest) expressionNode()        {}
+func (fl FloatLiteralTest) expressionNode()          {}
 func (nl BooleanLiteralTest) expressionNode()        {}
 func (sl StringLiteralTest) expressionNode()         {}
 func (al ArrayLiteralTest) expressionNode()          {}
 func (hl HashLiteralTest) expressionNode()           {}
+func (ye YieldExpressionTest) expressionNode()       {}
 
 type UnaryExpressionTest struct {
 	Operator string
@@ -138,7 +150,8 @@ type SubscriptExpressionTest struct {
 
 type (
 	IdentifierTest     string
-	NumberLiteralTest  float64
+	IntegerLiteralTest int64
+	FloatLiteralTest   float64
 	BooleanLiteralTest bool
 	StringLiteralTest  string
 )
@@ -152,6 +165,10 @@ type HashLiteralTest struct {
 	Pairs map[ExpressionTest]ExpressionTest
 }
 
+type YieldExpressionTest struct {
+	Value ExpressionTest
+}
+
 func TestLetStatement(t *testing.T) {
 	r := assert.GetTestReporter(t)
 
@@ -164,15 +181,15 @@ func TestLetStatement(t *testing.T) {
 			program: ProgramTest{
 				[]StatementTest{
 					LetDeclarationTest{
-						IdentifierTest("x"), NumberLiteralTest(5),
+						IdentifierTest("x"), IntegerLiteralTest(5),
 						"let x=5;",
 					},
 					LetDeclarationTest{
-						IdentifierTest("y"), NumberLiteralTest(10),
+						IdentifierTest("y"), IntegerLiteralTest(10),
 						"let y=10;",
 					},
 					LetDeclarationTest{
-						IdentifierTest("foobar"), NumberLiteralTest(838383),
+						IdentifierTest("foobar"), IntegerLiteralTest(838383),
 						"let foobar=838383;",
 					},
 				},
@@ -184,9 +201,9 @@ func TestLetStatement(t *testing.T) {
 			let y 10;
 			let 838383;`,
 			errors: []string{
-				"expected next token to be <ASSIGN> but was <NUMBER>",
-				"expected next token to be <ASSIGN> but was <NUMBER>",
-				"expected next token to be <IDENT> but was <NUMBER>",
+				"expected next token to be <ASSIGN> but was <INT>",
+				"expected next token to be <ASSIGN> but was <INT>",
+				"expected next token to be <IDENT> but was <INT>",
 			},
 		},
 	}
@@ -208,15 +225,15 @@ func TestReturnStatement(t *testing.T) {
 			program: ProgramTest{
 				[]StatementTest{
 					ReturnStatementTest{
-						NumberLiteralTest(5),
+						IntegerLiteralTest(5),
 						"return 5;",
 					},
 					ReturnStatementTest{
-						NumberLiteralTest(10),
+						IntegerLiteralTest(10),
 						"return 10;",
 					},
 					ReturnStatementTest{
-						NumberLiteralTest(993322),
+						IntegerLiteralTest(993322),
 						"return 993322;",
 					},
 				},
@@ -260,12 +277,34 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								NumberLiteralTest(5),
+								IntegerLiteralTest(5),
 								"5;",
 							},
 						},
 					},
 				},
+				{
+					input: `2.5;`,
+					program: ProgramTest{
+						[]StatementTest{
+							ExpressionStatementTest{
+								FloatLiteralTest(2.5),
+								"2.5;",
+							},
+						},
+					},
+				},
+				{
+					input: `7 % 2;`,
+					program: ProgramTest{
+						[]StatementTest{
+							ExpressionStatementTest{
+								BinaryExpressionTest{IntegerLiteralTest(7), "%", IntegerLiteralTest(2)},
+								"(7%2);",
+							},
+						},
+					},
+				},
 			},
 		},
 		{
@@ -276,7 +315,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								UnaryExpressionTest{"!", NumberLiteralTest(5)},
+								UnaryExpressionTest{"!", IntegerLiteralTest(5)},
 								"(!5);",
 							},
 						},
@@ -287,7 +326,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								UnaryExpressionTest{"-", NumberLiteralTest(15)},
+								UnaryExpressionTest{"-", IntegerLiteralTest(15)},
 								"(-15);",
 							},
 						},
@@ -359,9 +398,9 @@ func TestExpressionStatement(t *testing.T) {
 								UnaryExpressionTest{
 									"-",
 									BinaryExpressionTest{
-										NumberLiteralTest(5),
+										IntegerLiteralTest(5),
 										"+",
-										NumberLiteralTest(5),
+										IntegerLiteralTest(5),
 									},
 								},
 								"(-(5+5));",
@@ -397,7 +436,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "+", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "+", IntegerLiteralTest(5)},
 								"(5+5);",
 							},
 						},
@@ -408,7 +447,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "-", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "-", IntegerLiteralTest(5)},
 								"(5-5);",
 							},
 						},
@@ -419,7 +458,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "*", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "*", IntegerLiteralTest(5)},
 								"(5*5);",
 							},
 						},
@@ -430,7 +469,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "/", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "/", IntegerLiteralTest(5)},
 								"(5/5);",
 							},
 						},
@@ -441,7 +480,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), ">", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), ">", IntegerLiteralTest(5)},
 								"(5>5);",
 							},
 						},
@@ -452,7 +491,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "<", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "<", IntegerLiteralTest(5)},
 								"(5<5);",
 							},
 						},
@@ -463,7 +502,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "==", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "==", IntegerLiteralTest(5)},
 								"(5==5);",
 							},
 						},
@@ -474,7 +513,7 @@ func TestExpressionStatement(t *testing.T) {
 					program: ProgramTest{
 						[]StatementTest{
 							ExpressionStatementTest{
-								BinaryExpressionTest{NumberLiteralTest(5), "!=", NumberLiteralTest(5)},
+								BinaryExpressionTest{IntegerLiteralTest(5), "!=", IntegerLiteralTest(5)},
 								"(5!=5);",
 							},
 						},
@@ -720,17 +759,17 @@ func TestExpressionStatement(t *testing.T) {
 						[]StatementTest{
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									NumberLiteralTest(3),
+									IntegerLiteralTest(3),
 									"+",
-									NumberLiteralTest(4),
+									IntegerLiteralTest(4),
 								},
 								"(3+4);",
 							},
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									UnaryExpressionTest{"-", NumberLiteralTest(5)},
+									UnaryExpressionTest{"-", IntegerLiteralTest(5)},
 									"*",
-									NumberLiteralTest(5),
+									IntegerLiteralTest(5),
 								},
 								"((-5)*5);",
 							},
@@ -743,9 +782,9 @@ func TestExpressionStatement(t *testing.T) {
 						[]StatementTest{
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									BinaryExpressionTest{NumberLiteralTest(5), ">", NumberLiteralTest(4)},
+									BinaryExpressionTest{IntegerLiteralTest(5), ">", IntegerLiteralTest(4)},
 									"==",
-									BinaryExpressionTest{NumberLiteralTest(3), "<", NumberLiteralTest(4)},
+									BinaryExpressionTest{IntegerLiteralTest(3), "<", IntegerLiteralTest(4)},
 								},
 								"((5>4)==(3<4));",
 							},
@@ -758,9 +797,9 @@ func TestExpressionStatement(t *testing.T) {
 						[]StatementTest{
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									BinaryExpressionTest{NumberLiteralTest(5), "<", NumberLiteralTest(4)},
+									BinaryExpressionTest{IntegerLiteralTest(5), "<", IntegerLiteralTest(4)},
 									"!=",
-									BinaryExpressionTest{NumberLiteralTest(3), ">", NumberLiteralTest(4)},
+									BinaryExpressionTest{IntegerLiteralTest(3), ">", IntegerLiteralTest(4)},
 								},
 								"((5<4)!=(3>4));",
 							},
@@ -774,15 +813,15 @@ func TestExpressionStatement(t *testing.T) {
 							ExpressionStatementTest{
 								BinaryExpressionTest{
 									BinaryExpressionTest{
-										NumberLiteralTest(3),
+										IntegerLiteralTest(3),
 										"+",
-										BinaryExpressionTest{NumberLiteralTest(4), "*", NumberLiteralTest(5)},
+										BinaryExpressionTest{IntegerLiteralTest(4), "*", IntegerLiteralTest(5)},
 									},
 									"==",
 									BinaryExpressionTest{
-										BinaryExpressionTest{NumberLiteralTest(3), "*", NumberLiteralTest(1)},
+										BinaryExpressionTest{IntegerLiteralTest(3), "*", IntegerLiteralTest(1)},
 										"+",
-										BinaryExpressionTest{NumberLiteralTest(4), "*", NumberLiteralTest(5)},
+										BinaryExpressionTest{IntegerLiteralTest(4), "*", IntegerLiteralTest(5)},
 									},
 								},
 								"((3+(4*5))==((3*1)+(4*5)));",
@@ -796,7 +835,7 @@ func TestExpressionStatement(t *testing.T) {
 						[]StatementTest{
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									BinaryExpressionTest{NumberLiteralTest(3), ">", NumberLiteralTest(5)},
+									BinaryExpressionTest{IntegerLiteralTest(3), ">", IntegerLiteralTest(5)},
 									"==",
 									BooleanLiteralTest(false),
 								},
@@ -811,7 +850,7 @@ func TestExpressionStatement(t *testing.T) {
 						[]StatementTest{
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									BinaryExpressionTest{NumberLiteralTest(3), "<", NumberLiteralTest(5)},
+									BinaryExpressionTest{IntegerLiteralTest(3), "<", IntegerLiteralTest(5)},
 									"==",
 									BooleanLiteralTest(true),
 								},
@@ -827,12 +866,12 @@ func TestExpressionStatement(t *testing.T) {
 							ExpressionStatementTest{
 								BinaryExpressionTest{
 									BinaryExpressionTest{
-										NumberLiteralTest(1),
+										IntegerLiteralTest(1),
 										"+",
-										BinaryExpressionTest{NumberLiteralTest(2), "+", NumberLiteralTest(3)},
+										BinaryExpressionTest{IntegerLiteralTest(2), "+", IntegerLiteralTest(3)},
 									},
 									"+",
-									NumberLiteralTest(4),
+									IntegerLiteralTest(4),
 								},
 								"((1+(2+3))+4);",
 							},
@@ -846,12 +885,12 @@ func TestExpressionStatement(t *testing.T) {
 							ExpressionStatementTest{
 								BinaryExpressionTest{
 									BinaryExpressionTest{
-										NumberLiteralTest(5),
+										IntegerLiteralTest(5),
 										"+",
-										NumberLiteralTest(5),
+										IntegerLiteralTest(5),
 									},
 									"*",
-									NumberLiteralTest(2),
+									IntegerLiteralTest(2),
 								},
 								"((5+5)*2);",
 							},
@@ -864,12 +903,12 @@ func TestExpressionStatement(t *testing.T) {
 						[]StatementTest{
 							ExpressionStatementTest{
 								BinaryExpressionTest{
-									NumberLiteralTest(2),
+									IntegerLiteralTest(2),
 									"/",
 									BinaryExpressionTest{
-										NumberLiteralTest(5),
+										IntegerLiteralTest(5),
 										"+",
-										NumberLiteralTest(5),
+										IntegerLiteralTest(5),
 									},
 								},
 								"(2/(5+5));",
@@ -913,10 +952,10 @@ func TestExpressionStatement(t *testing.T) {
 										SubscriptExpressionTest{
 											ArrayLiteralTest{
 												[]ExpressionTest{
-													NumberLiteralTest(1),
-													NumberLiteralTest(2),
-													NumberLiteralTest(3),
-													NumberLiteralTest(4),
+													IntegerLiteralTest(1),
+													IntegerLiteralTest(2),
+													IntegerLiteralTest(3),
+													IntegerLiteralTest(4),
 												},
 											},
 											BinaryExpressionTest{
@@ -1127,7 +1166,7 @@ func TestExpressionStatement(t *testing.T) {
 								CallExpressionTest{
 									IdentifierTest("add"),
 									[]ExpressionTest{
-										NumberLiteralTest(1),
+										IntegerLiteralTest(1),
 									},
 								},
 								"add(1);",
@@ -1143,9 +1182,9 @@ func TestExpressionStatement(t *testing.T) {
 								CallExpressionTest{
 									IdentifierTest("add"),
 									[]ExpressionTest{
-										NumberLiteralTest(1),
-										BinaryExpressionTest{NumberLiteralTest(2), "*", NumberLiteralTest(3)},
-										BinaryExpressionTest{NumberLiteralTest(4), "+", NumberLiteralTest(5)},
+										IntegerLiteralTest(1),
+										BinaryExpressionTest{IntegerLiteralTest(2), "*", IntegerLiteralTest(3)},
+										BinaryExpressionTest{IntegerLiteralTest(4), "+", IntegerLiteralTest(5)},
 									},
 								},
 								"add(1,(2*3),(4+5));",
@@ -1163,14 +1202,14 @@ func TestExpressionStatement(t *testing.T) {
 									[]ExpressionTest{
 										IdentifierTest("a"),
 										IdentifierTest("b"),
-										NumberLiteralTest(1),
-										BinaryExpressionTest{NumberLiteralTest(2), "*", NumberLiteralTest(3)},
-										BinaryExpressionTest{NumberLiteralTest(4), "+", NumberLiteralTest(5)},
+										IntegerLiteralTest(1),
+										BinaryExpressionTest{IntegerLiteralTest(2), "*", IntegerLiteralTest(3)},
+										BinaryExpressionTest{IntegerLiteralTest(4), "+", IntegerLiteralTest(5)},
 										CallExpressionTest{
 											IdentifierTest("add"),
 											[]ExpressionTest{
-												NumberLiteralTest(6),
-												BinaryExpressionTest{NumberLiteralTest(7), "*", NumberLiteralTest(8)},
+												IntegerLiteralTest(6),
+												BinaryExpressionTest{IntegerLiteralTest(7), "*", IntegerLiteralTest(8)},
 											},
 										},
 									},
@@ -1221,24 +1260,24 @@ func TestExpressionStatement(t *testing.T) {
 											"*",
 											SubscriptExpressionTest{
 												IdentifierTest("b"),
-												NumberLiteralTest(2),
+												IntegerLiteralTest(2),
 											},
 										},
 										SubscriptExpressionTest{
 											IdentifierTest("b"),
-											NumberLiteralTest(1),
+											IntegerLiteralTest(1),
 										},
 										BinaryExpressionTest{
-											NumberLiteralTest(2),
+											IntegerLiteralTest(2),
 											"*",
 											SubscriptExpressionTest{
 												ArrayLiteralTest{
 													[]ExpressionTest{
-														NumberLiteralTest(1),
-														NumberLiteralTest(2),
+														IntegerLiteralTest(1),
+														IntegerLiteralTest(2),
 													},
 												},
-												NumberLiteralTest(1),
+												IntegerLiteralTest(1),
 											},
 										},
 									},
@@ -1276,16 +1315,16 @@ func TestExpressionStatement(t *testing.T) {
 							ExpressionStatementTest{
 								ArrayLiteralTest{
 									[]ExpressionTest{
-										NumberLiteralTest(1),
+										IntegerLiteralTest(1),
 										BinaryExpressionTest{
-											NumberLiteralTest(2),
+											IntegerLiteralTest(2),
 											"*",
-											NumberLiteralTest(2),
+											IntegerLiteralTest(2),
 										},
 										BinaryExpressionTest{
-											NumberLiteralTest(3),
+											IntegerLiteralTest(3),
 											"+",
-											NumberLiteralTest(3),
+											IntegerLiteralTest(3),
 										},
 									},
 								},
@@ -1315,9 +1354,9 @@ func TestExpressionStatement(t *testing.T) {
 								SubscriptExpressionTest{
 									IdentifierTest("array"),
 									BinaryExpressionTest{
-										NumberLiteralTest(1),
+										IntegerLiteralTest(1),
 										"+",
-										NumberLiteralTest(1),
+										IntegerLiteralTest(1),
 									},
 								},
 								"(array[(1+1)]);",
@@ -1327,6 +1366,33 @@ func TestExpressionStatement(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TestYieldExpression",
+			tests: []ParserTest{
+				{
+					input: `yield x + 1;`,
+					program: ProgramTest{
+						[]StatementTest{
+							ExpressionStatementTest{
+								YieldExpressionTest{BinaryExpressionTest{IdentifierTest("x"), "+", IntegerLiteralTest(1)}},
+								"(yield (x+1));",
+							},
+						},
+					},
+				},
+				{
+					input: `yield yield x;`,
+					program: ProgramTest{
+						[]StatementTest{
+							ExpressionStatementTest{
+								YieldExpressionTest{YieldExpressionTest{IdentifierTest("x")}},
+								"(yield (yield x));",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "TestHashLiteral",
 			tests: []ParserTest{
@@ -1342,9 +1408,9 @@ func TestExpressionStatement(t *testing.T) {
 										StringLiteralTest("three"),
 									},
 									map[ExpressionTest]ExpressionTest{
-										StringLiteralTest("one"):   NumberLiteralTest(1),
-										StringLiteralTest("two"):   NumberLiteralTest(2),
-										StringLiteralTest("three"): NumberLiteralTest(3),
+										StringLiteralTest("one"):   IntegerLiteralTest(1),
+										StringLiteralTest("two"):   IntegerLiteralTest(2),
+										StringLiteralTest("three"): IntegerLiteralTest(3),
 									},
 								},
 								"{\"one\":1,\"two\":2,\"three\":3};",
@@ -1374,8 +1440,8 @@ func TestExpressionStatement(t *testing.T) {
 										BooleanLiteralTest(false),
 									},
 									map[ExpressionTest]ExpressionTest{
-										BooleanLiteralTest(true):  NumberLiteralTest(1),
-										BooleanLiteralTest(false): NumberLiteralTest(2),
+										BooleanLiteralTest(true):  IntegerLiteralTest(1),
+										BooleanLiteralTest(false): IntegerLiteralTest(2),
 									},
 								},
 								"{true:1,false:2};",
@@ -1390,14 +1456,14 @@ func TestExpressionStatement(t *testing.T) {
 							ExpressionStatementTest{
 								HashLiteralTest{
 									[]ExpressionTest{
-										NumberLiteralTest(1),
-										NumberLiteralTest(2),
-										NumberLiteralTest(3),
+										IntegerLiteralTest(1),
+										IntegerLiteralTest(2),
+										IntegerLiteralTest(3),
 									},
 									map[ExpressionTest]ExpressionTest{
-										NumberLiteralTest(1): NumberLiteralTest(1),
-										NumberLiteralTest(2): NumberLiteralTest(2),
-										NumberLiteralTest(3): NumberLiteralTest(3),
+										IntegerLiteralTest(1): IntegerLiteralTest(1),
+										IntegerLiteralTest(2): IntegerLiteralTest(2),
+										IntegerLiteralTest(3): IntegerLiteralTest(3),
 									},
 								},
 								"{1:1,2:2,3:3};",
@@ -1418,19 +1484,19 @@ func TestExpressionStatement(t *testing.T) {
 									},
 									map[ExpressionTest]ExpressionTest{
 										StringLiteralTest("one"): BinaryExpressionTest{
-											NumberLiteralTest(0),
+											IntegerLiteralTest(0),
 											"+",
-											NumberLiteralTest(1),
+											IntegerLiteralTest(1),
 										},
 										StringLiteralTest("two"): BinaryExpressionTest{
-											NumberLiteralTest(10),
+											IntegerLiteralTest(10),
 											"-",
-											NumberLiteralTest(8),
+											IntegerLiteralTest(8),
 										},
 										StringLiteralTest("three"): BinaryExpressionTest{
-											NumberLiteralTest(15),
+											IntegerLiteralTest(15),
 											"/",
-											NumberLiteralTest(5),
+											IntegerLiteralTest(5),
 										},
 									},
 								},
@@ -1489,6 +1555,102 @@ func TestMacroStatement(t *testing.T) {
 	}
 }
 
+func TestImportStatement(t *testing.T) {
+	r := assert.GetTestReporter(t)
+
+	tests := []ParserTest{
+		{
+			input: `
+			import "lib/strings.mk" as s;
+			import "math.mk" as as`,
+			program: ProgramTest{
+				[]StatementTest{
+					ImportStatementTest{"lib/strings.mk", IdentifierTest("s"), `import "lib/strings.mk" as s;`},
+					ImportStatementTest{"math.mk", IdentifierTest("as"), `import "math.mk" as as;`},
+				},
+			},
+		},
+		{
+			input: `
+			import lib as s;
+			import "lib.mk" s;
+			import "lib.mk" as 5;`,
+			errors: []string{
+				"expected next token to be <STRING> but was <IDENT>",
+				"expected next token to be <as> but was <IDENT>",
+				"expected next token to be <IDENT> but was <INT>",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		testParser(t, r, i, test)
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	r := assert.GetTestReporter(t)
+
+	tests := []ParserTest{
+		{
+			input: `
+			export let x = 5;
+			export macro id(x) { x };`,
+			program: ProgramTest{
+				[]StatementTest{
+					LetDeclarationTest{IdentifierTest("x"), IntegerLiteralTest(5), "export let x=5;"},
+					MacroStatementTest{
+						IdentifierTest("id"),
+						[]IdentifierTest{IdentifierTest("x")},
+						BlockStatementTest{
+							[]StatementTest{
+								ExpressionStatementTest{IdentifierTest("x"), "x;"},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			input: `export fn() {};`,
+			errors: []string{
+				"expected next token to be <LET> or <MACRO> but was <FN>",
+			},
+		},
+		{
+			input: `let f = fn() { export let x = 5; x };`,
+			errors: []string{
+				"export is only allowed at the top level of a program",
+			},
+		},
+		{
+			input: `if (true) { export macro id(x) { x } }`,
+			errors: []string{
+				"export is only allowed at the top level of a program",
+			},
+		},
+	}
+
+	for i, test := range tests {
+		testParser(t, r, i, test)
+	}
+
+	p := NewParser(`export let x = 5; let y = 6; export macro id(x) { x };`, false)
+	program := p.ParseProgram()
+	for i, expected := range []bool{true, false, true} {
+		var actual bool
+		switch stmt := program.Statements[i].(type) {
+		case *ast.LetDeclaration:
+			actual = stmt.Exported
+		case *ast.MacroStatement:
+			actual = stmt.Exported
+		}
+		if expected != actual {
+			t.Errorf("test[%d] - Exported ==> expected: <%t> but was: <%t>", i, expected, actual)
+		}
+	}
+}
+
 func testParser(t *testing.T, r assert.Reporter, i int, test ParserTest) {
 	t.Helper()
 
@@ -1558,6 +1720,10 @@ func testStatement(t *testing.T, r assert.Reporter, i, j int, expected Statement
 		if !testMacroStatement(t, r, i, j, expected, actual) {
 			return false
 		}
+	case ImportStatementTest:
+		if !testImportStatement(t, r, i, j, expected, actual) {
+			return false
+		}
 	default:
 		t.Fatalf("test[%d][%d] - unexpected type <%T>", i, j, expected)
 	}
@@ -1725,12 +1891,20 @@ func testExpression(t *testing.T, r assert.Reporter, i, j int, expected Expressi
 		if !testSubscriptExpression(t, r, i, j, expected, actual) {
 			return false
 		}
+	case YieldExpressionTest:
+		if !testYieldExpression(t, r, i, j, expected, actual) {
+			return false
+		}
 	case IdentifierTest:
 		if !testIdentifier(t, r, i, j, expected, actual) {
 			return false
 		}
-	case NumberLiteralTest:
-		if !testNumberLiteral(t, r, i, j, expected, actual) {
+	case IntegerLiteralTest:
+		if !testIntegerLiteral(t, r, i, j, expected, actual) {
+			return false
+		}
+	case FloatLiteralTest:
+		if !testFloatLiteral(t, r, i, j, expected, actual) {
 			return false
 		}
 	case BooleanLiteralTest:
@@ -1774,6 +1948,16 @@ func testUnaryExpression(t *testing.T, r assert.Reporter, i, j int, expected Una
 	return true
 }
 
+func testYieldExpression(t *testing.T, r assert.Reporter, i, j int, expected YieldExpressionTest, actual ast.Expression) bool {
+	expr, ok := actual.(*ast.YieldExpression)
+	if !ok {
+		t.Errorf("test[%d][%d] - actual.(*ast.YieldExpression) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.YieldExpression{}, actual)
+		return false
+	}
+
+	return testExpression(t, r, i, j, expected.Value, expr.Value)
+}
+
 func testBinaryExpression(t *testing.T, r assert.Reporter, i, j int, expected BinaryExpressionTest, actual ast.Expression) bool {
 	if expected.Operator != actual.TokenLiteral() {
 		t.Errorf("test[%d][%d] - *ast.InfixExpression.TokenLiteral() ==> expected: <%s> but was: <%s>", i, j, expected.Operator, actual.TokenLiteral())
@@ -1930,6 +2114,30 @@ func testSubscriptExpression(t *testing.T, r assert.Reporter, i, j int, expected
 	return true
 }
 
+func testImportStatement(t *testing.T, r assert.Reporter, i, j int, expected ImportStatementTest, actual ast.Statement) bool {
+	stmt, ok := actual.(*ast.ImportStatement)
+	if !ok {
+		t.Errorf("test[%d][%d] - actual.(*ast.ImportStatement) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.ImportStatement{}, actual)
+		return false
+	}
+
+	if expected.Path != stmt.Path.Value {
+		t.Errorf("test[%d][%d] - *ast.ImportStatement.Path ==> expected: <%s> but was: <%s>", i, j, expected.Path, stmt.Path.Value)
+		return false
+	}
+
+	if !testIdentifier(t, r, i, j, expected.Name, stmt.Name) {
+		return false
+	}
+
+	if expected.String != actual.String() {
+		t.Errorf("test[%d][%d] - *ast.ImportStatement.String() ==> expected: <%s> but was: <%s>", i, j, expected.String, actual.String())
+		return false
+	}
+
+	return true
+}
+
 func testIdentifier(t *testing.T, r assert.Reporter, i, j int, expected IdentifierTest, actual ast.Expression) bool {
 	if string(expected) != actual.TokenLiteral() {
 		t.Errorf("test[%d][%d] - *ast.Identifier.TokenLiteral() ==> expected: <%s> but was: <%s>", i, j, string(expected), actual.TokenLiteral())
@@ -1950,20 +2158,35 @@ func testIdentifier(t *testing.T, r assert.Reporter, i, j int, expected Identifi
 	return true
 }
 
-func testNumberLiteral(t *testing.T, r assert.Reporter, i, j int, expected NumberLiteralTest, actual ast.Expression) bool {
-	if fmt.Sprintf("%g", expected) != actual.TokenLiteral() {
-		t.Errorf("test[%d][%d] - *ast.NumberLiteral.TokenLiteral ==> expected: <%s> but was: <%s>", i, j, fmt.Sprintf("%g", expected), actual.TokenLiteral())
+func testIntegerLiteral(t *testing.T, r assert.Reporter, i, j int, expected IntegerLiteralTest, actual ast.Expression) bool {
+	if fmt.Sprintf("%d", expected) != actual.TokenLiteral() {
+		t.Errorf("test[%d][%d] - *ast.IntegerLiteral.TokenLiteral ==> expected: <%s> but was: <%s>", i, j, fmt.Sprintf("%d", expected), actual.TokenLiteral())
+		return false
+	}
+
+	expr, ok := actual.(*ast.IntegerLiteral)
+	if !ok {
+		t.Errorf("test[%d][%d] - actual.(*ast.IntegerLiteral) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.IntegerLiteral{}, actual)
 		return false
 	}
 
-	expr, ok := actual.(*ast.NumberLiteral)
+	if int64(expected) != expr.Value {
+		t.Errorf("test[%d][%d] - *ast.IntegerLiteral.Value ==> expected: <%d> but was: <%d>", i, j, int64(expected), expr.Value)
+		return false
+	}
+
+	return true
+}
+
+func testFloatLiteral(t *testing.T, r assert.Reporter, i, j int, expected FloatLiteralTest, actual ast.Expression) bool {
+	expr, ok := actual.(*ast.FloatLiteral)
 	if !ok {
-		t.Errorf("test[%d][%d] - actual.(*ast.NumberLiteral) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.NumberLiteral{}, actual)
+		t.Errorf("test[%d][%d] - actual.(*ast.FloatLiteral) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.FloatLiteral{}, actual)
 		return false
 	}
 
 	if float64(expected) != expr.Value {
-		t.Errorf("test[%d][%d] - *ast.NumberLiteral.Value ==> expected: <%f> but was: <%f>", i, j, float64(expected), expr.Value)
+		t.Errorf("test[%d][%d] - *ast.FloatLiteral.Value ==> expected: <%f> but was: <%f>", i, j, float64(expected), expr.Value)
 		return false
 	}
 
@@ -1978,7 +2201,7 @@ func testBooleanLiteral(t *testing.T, r assert.Reporter, i, j int, expected Bool
 
 	expr, ok := actual.(*ast.BooleanLiteral)
 	if !ok {
-		t.Errorf("test[%d][%d] - actual.(*ast.BooleanLiteral) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.NumberLiteral{}, actual)
+		t.Errorf("test[%d][%d] - actual.(*ast.BooleanLiteral) ==> unexpected type, expected: <%T> but was: <%T>", i, j, &ast.BooleanLiteral{}, actual)
 		return false
 	}
 
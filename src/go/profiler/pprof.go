@@ -0,0 +1,203 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+	"strings"
+)
+
+// The field numbers of the messages of profile.proto, from
+// https://github.com/google/pprof/blob/main/proto/profile.proto.
+const (
+	profileSampleType        = 1
+	profileSample            = 2
+	profileLocation          = 4
+	profileFunction          = 5
+	profileStringTable       = 6
+	profileTimeNanos         = 9
+	profileDurationNanos     = 10
+	profilePeriodType        = 11
+	profilePeriod            = 12
+	profileDefaultSampleType = 14
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID         = 1
+	functionName       = 2
+	functionSystemName = 3
+	functionFilename   = 4
+	functionStartLine  = 5
+)
+
+// WriteProfile writes what was measured as a gzipped pprof profile, with
+// filename as the source file of every function. Its samples hold the calls,
+// allocations and nanoseconds spent in each call stack, excluding callees.
+func (p *Profiler) WriteProfile(w io.Writer, filename string) error {
+	e := &encoder{strings: map[string]int64{"": 0}, table: []string{""}}
+	profile := &buffer{}
+
+	for _, valueType := range [][2]string{{"calls", "count"}, {"allocations", "count"}, {"time", "nanoseconds"}} {
+		message := &buffer{}
+		message.int64(valueTypeType, e.string(valueType[0]))
+		message.int64(valueTypeUnit, e.string(valueType[1]))
+		profile.message(profileSampleType, message)
+	}
+
+	functions := map[*Function]uint64{}
+	for _, function := range p.Functions() {
+		id := uint64(len(functions) + 1)
+		functions[function] = id
+
+		message := &buffer{}
+		message.uint64(functionID, id)
+		message.int64(functionName, e.string(symbol(function.Name)))
+		message.int64(functionSystemName, e.string(symbol(function.Name)))
+		message.int64(functionFilename, e.string(filename))
+		message.int64(functionStartLine, int64(function.Position.Line))
+		profile.message(profileFunction, message)
+	}
+
+	keys := []string{}
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	locations := map[location]uint64{}
+	for _, key := range keys {
+		s := p.samples[key]
+		ids := []uint64{}
+		for _, l := range s.stack {
+			id, ok := locations[l]
+			if !ok {
+				id = uint64(len(locations) + 1)
+				locations[l] = id
+
+				line := &buffer{}
+				line.uint64(lineFunctionID, functions[l.function])
+				line.int64(lineLine, int64(l.line))
+				message := &buffer{}
+				message.uint64(locationID, id)
+				message.message(locationLine, line)
+				profile.message(profileLocation, message)
+			}
+			ids = append(ids, id)
+		}
+
+		message := &buffer{}
+		message.packedUint64(sampleLocationID, ids)
+		message.packedInt64(sampleValue, []int64{s.calls, s.allocations, int64(s.time)})
+		profile.message(profileSample, message)
+	}
+
+	period := &buffer{}
+	period.int64(valueTypeType, e.string("time"))
+	period.int64(valueTypeUnit, e.string("nanoseconds"))
+	profile.message(profilePeriodType, period)
+	profile.int64(profilePeriod, 1)
+	profile.int64(profileTimeNanos, p.started.UnixNano())
+	profile.int64(profileDurationNanos, int64(p.duration))
+	profile.int64(profileDefaultSampleType, e.string("time"))
+	for _, s := range e.table {
+		profile.bytes(profileStringTable, []byte(s))
+	}
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.data); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// symbol writes <main> and <anonymous> as (main) and (anonymous), which
+// pprof does not mistake for C++ template arguments and drop.
+func symbol(name string) string {
+	return strings.NewReplacer("<", "(", ">", ")").Replace(name)
+}
+
+type encoder struct {
+	strings map[string]int64
+	table   []string
+}
+
+// string interns s in the string table, whose first entry must be "".
+func (e *encoder) string(s string) int64 {
+	if index, ok := e.strings[s]; ok {
+		return index
+	}
+	index := int64(len(e.table))
+	e.strings[s] = index
+	e.table = append(e.table, s)
+	return index
+}
+
+// buffer encodes a protocol buffer message.
+type buffer struct {
+	data []byte
+}
+
+const (
+	varintType = 0
+	bytesType  = 2
+)
+
+func (b *buffer) varint(v uint64) {
+	for v >= 0x80 {
+		b.data = append(b.data, byte(v)|0x80)
+		v >>= 7
+	}
+	b.data = append(b.data, byte(v))
+}
+
+func (b *buffer) tag(field int, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+func (b *buffer) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.tag(field, varintType)
+	b.varint(v)
+}
+
+func (b *buffer) int64(field int, v int64) {
+	b.uint64(field, uint64(v))
+}
+
+func (b *buffer) bytes(field int, data []byte) {
+	b.tag(field, bytesType)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *buffer) message(field int, message *buffer) {
+	b.bytes(field, message.data)
+}
+
+func (b *buffer) packedUint64(field int, values []uint64) {
+	packed := &buffer{}
+	for _, v := range values {
+		packed.varint(v)
+	}
+	b.bytes(field, packed.data)
+}
+
+func (b *buffer) packedInt64(field int, values []int64) {
+	packed := &buffer{}
+	for _, v := range values {
+		packed.varint(uint64(v))
+	}
+	b.bytes(field, packed.data)
+}
@@ -0,0 +1,199 @@
+package profiler
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+// Function holds what was measured of one function. Inclusive time counts
+// every call once, however deeply it recursed.
+type Function struct {
+	Name     string
+	Position token.Position
+
+	Calls       int64
+	Inclusive   time.Duration
+	Exclusive   time.Duration
+	Allocations int64
+
+	active int
+}
+
+// A sample is what was measured of one call stack, leaf first.
+type sample struct {
+	stack       []location
+	calls       int64
+	time        time.Duration
+	allocations int64
+}
+
+// A location is a function and the line it was running, either the line it
+// starts on or the line of the call it was making.
+type location struct {
+	function *Function
+	line     int
+}
+
+type frame struct {
+	function *Function
+	call     token.Position
+	start    time.Time
+	children time.Duration
+
+	allocations int
+	childAllocs int
+}
+
+// Profiler observes an evaluation and measures the calls, time and
+// allocations of each function, attributing time spent outside any function
+// to <main>.
+type Profiler struct {
+	object.BaseObserver
+
+	runtime   *object.Runtime
+	main      *Function
+	functions map[*ast.FunctionLiteral]*Function
+	frames    []*frame
+	samples   map[string]*sample
+
+	started  time.Time
+	duration time.Duration
+}
+
+func New() *Profiler {
+	return &Profiler{
+		main:      &Function{Name: "<main>", Position: token.Position{Line: 1, Column: 1}},
+		functions: map[*ast.FunctionLiteral]*Function{},
+		samples:   map[string]*sample{},
+	}
+}
+
+// Start begins measuring <main>; the allocations counted are those of the
+// runtime of the first node the profiler observes.
+func (p *Profiler) Start() {
+	p.started = time.Now()
+	p.main.active += 1
+	p.frames = []*frame{{function: p.main, start: p.started}}
+}
+
+// Stop finishes every call still measured, as when an error ended the
+// evaluation, and then <main>.
+func (p *Profiler) Stop() {
+	for len(p.frames) != 0 {
+		p.pop()
+	}
+	p.duration = time.Since(p.started)
+}
+
+// Functions returns what was measured of each function, <main> first and
+// the others in source order.
+func (p *Profiler) Functions() []*Function {
+	functions := []*Function{p.main}
+	for _, function := range p.functions {
+		functions = append(functions, function)
+	}
+	slices.SortStableFunc(functions[1:], func(a, b *Function) int {
+		if a.Position.Offset != b.Position.Offset {
+			return a.Position.Offset - b.Position.Offset
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return functions
+}
+
+func (p *Profiler) Enter(node ast.Node, env *object.Environment) {
+	if p.runtime == nil && len(p.frames) != 0 {
+		p.runtime = env.Runtime
+		p.frames[0].allocations = p.runtime.Allocations
+	}
+}
+
+func (p *Profiler) Call(fn *object.Function, args []object.Object, position token.Position, env *object.Environment) {
+	if len(p.frames) == 0 {
+		return
+	}
+	if p.runtime == nil {
+		p.runtime = env.Runtime
+	}
+
+	function, ok := p.functions[fn.Literal]
+	if !ok {
+		function = &Function{Name: object.Frame{Function: fn}.Name(), Position: ast.Position(fn.Literal)}
+		p.functions[fn.Literal] = function
+	}
+	function.active += 1
+	p.frames = append(p.frames, &frame{
+		function:    function,
+		call:        position,
+		start:       time.Now(),
+		allocations: p.runtime.Allocations,
+	})
+}
+
+func (p *Profiler) Return(fn *object.Function, value object.Object, interrupt object.Interruption) {
+	if len(p.frames) > 1 {
+		p.pop()
+	}
+}
+
+func (p *Profiler) pop() {
+	current := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+
+	elapsed := time.Since(current.start)
+	allocations := 0
+	if p.runtime != nil {
+		allocations = p.runtime.Allocations - current.allocations
+	}
+
+	function := current.function
+	function.Calls += 1
+	function.Exclusive += elapsed - current.children
+	function.Allocations += int64(allocations - current.childAllocs)
+	function.active -= 1
+	if function.active == 0 {
+		function.Inclusive += elapsed
+	}
+
+	stack := []location{{function: function, line: function.Position.Line}}
+	call := current.call
+	for i := len(p.frames) - 1; i >= 0; i -= 1 {
+		stack = append(stack, location{function: p.frames[i].function, line: call.Line})
+		call = p.frames[i].call
+	}
+	s := p.sample(stack)
+	s.calls += 1
+	s.time += elapsed - current.children
+	s.allocations += int64(allocations - current.childAllocs)
+
+	if len(p.frames) != 0 {
+		parent := p.frames[len(p.frames)-1]
+		parent.children += elapsed
+		parent.childAllocs += allocations
+	}
+}
+
+func (p *Profiler) sample(stack []location) *sample {
+	key := strings.Builder{}
+	for _, location := range stack {
+		key.WriteString(location.function.Name)
+		key.WriteString("@")
+		key.WriteString(location.function.Position.String())
+		key.WriteString(":")
+		key.WriteString(strconv.Itoa(location.line))
+		key.WriteString(";")
+	}
+
+	s, ok := p.samples[key.String()]
+	if !ok {
+		s = &sample{stack: stack}
+		p.samples[key.String()] = s
+	}
+	return s
+}
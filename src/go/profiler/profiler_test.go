@@ -0,0 +1,177 @@
+package profiler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+const source = `let fib = fn(n) {
+  if (n < 2) { return n; }
+  fib(n - 1) + fib(n - 2)
+};
+let build = fn(n) { [n, str(n)] };
+let xs = build(3);
+let apply = fn(f, x) { f(x) };
+apply(fn(x) { x }, 1);
+fib(10);`
+
+func profile(t *testing.T, input string) *Profiler {
+	p := parser.NewParser(input, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("p.Errors() ==> %v", p.Errors())
+	}
+
+	prof := New()
+	e := engine.NewEvaluator()
+	e.SetObserver(prof)
+	prof.Start()
+	e.Run(program)
+	prof.Stop()
+	return prof
+}
+
+func TestProfilerFunctions(t *testing.T) {
+	prof := profile(t, source)
+
+	tests := []struct {
+		name        string
+		line        int
+		calls       int64
+		allocations int64
+	}{
+		{"<main>", 1, 1, 4},
+		{"fib", 1, 177, 0},
+		{"build", 5, 1, 2},
+		{"apply", 7, 1, 0},
+		{"<anonymous>", 8, 1, 0},
+	}
+
+	functions := prof.Functions()
+	if len(functions) != len(tests) {
+		t.Fatalf("len(Functions()) ==> expected: <%d> but was: <%d>", len(tests), len(functions))
+	}
+	for i, test := range tests {
+		function := functions[i]
+		if test.name != function.Name || test.line != function.Position.Line {
+			t.Errorf("test[%d] - Function ==> expected: <%s:%d> but was: <%s:%d>", i, test.name, test.line, function.Name, function.Position.Line)
+		}
+		if test.calls != function.Calls {
+			t.Errorf("test[%d] - Function.Calls ==> expected: <%d> but was: <%d>", i, test.calls, function.Calls)
+		}
+		if test.allocations != function.Allocations {
+			t.Errorf("test[%d] - Function.Allocations ==> expected: <%d> but was: <%d>", i, test.allocations, function.Allocations)
+		}
+		if function.Exclusive > function.Inclusive || function.Inclusive > functions[0].Inclusive {
+			t.Errorf("test[%d] - Function ==> expected: <exclusive <= inclusive <= total> but was: <%s <= %s <= %s>", i, function.Exclusive, function.Inclusive, functions[0].Inclusive)
+		}
+	}
+}
+
+func TestProfilerStopsAfterErrors(t *testing.T) {
+	prof := profile(t, "let f = fn(n) { n + true };\nlet g = fn() { f(1) };\ng();")
+
+	calls := []int64{}
+	for _, function := range prof.Functions() {
+		calls = append(calls, function.Calls)
+	}
+	if !slices.Equal([]int64{1, 1, 1}, calls) {
+		t.Errorf("Function.Calls ==> expected: <[1 1 1]> but was: <%v>", calls)
+	}
+}
+
+func TestWriteProfile(t *testing.T) {
+	prof := profile(t, source)
+
+	out := &bytes.Buffer{}
+	if err := prof.WriteProfile(out, "fib.mk"); err != nil {
+		t.Fatalf("WriteProfile() ==> %v", err)
+	}
+	gz, err := gzip.NewReader(out)
+	if err != nil {
+		t.Fatalf("gzip.NewReader() ==> %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("io.ReadAll() ==> %v", err)
+	}
+
+	fields := decode(t, data)
+	strings := []string{}
+	for _, s := range fields[profileStringTable] {
+		strings = append(strings, string(s))
+	}
+	if len(strings) == 0 || strings[0] != "" {
+		t.Fatalf("string_table ==> expected to start with <\"\"> but was: <%q>", strings)
+	}
+	for _, expected := range []string{"calls", "allocations", "time", "nanoseconds", "(main)", "fib", "(anonymous)", "fib.mk"} {
+		if !slices.Contains(strings, expected) {
+			t.Errorf("string_table ==> expected to contain: <%s> but was: <%q>", expected, strings)
+		}
+	}
+
+	if len(fields[profileSampleType]) != 3 {
+		t.Errorf("len(sample_type) ==> expected: <3> but was: <%d>", len(fields[profileSampleType]))
+	}
+	if len(fields[profileFunction]) != 5 {
+		t.Errorf("len(function) ==> expected: <5> but was: <%d>", len(fields[profileFunction]))
+	}
+	if len(fields[profileSample]) != len(prof.samples) || len(fields[profileLocation]) == 0 {
+		t.Errorf("len(sample) ==> expected: <%d> but was: <%d>", len(prof.samples), len(fields[profileSample]))
+	}
+	for i, function := range fields[profileFunction] {
+		if name := decode(t, function)[functionName]; len(name) != 1 || strings[varint(t, name[0])] == "" {
+			t.Errorf("test[%d] - function.name ==> expected a name but was: <%v>", i, name)
+		}
+	}
+}
+
+// decode splits a protocol buffer message into the raw values of its fields,
+// with varints re-encoded so every value is bytes.
+func decode(t *testing.T, data []byte) map[int][][]byte {
+	fields := map[int][][]byte{}
+	for len(data) != 0 {
+		key, n := uvarint(data)
+		data = data[n:]
+		field, wire := int(key>>3), key&7
+		switch wire {
+		case varintType:
+			_, n := uvarint(data)
+			fields[field] = append(fields[field], data[:n])
+			data = data[n:]
+		case bytesType:
+			length, n := uvarint(data)
+			data = data[n:]
+			fields[field] = append(fields[field], data[:length])
+			data = data[length:]
+		default:
+			t.Fatalf("unexpected wire type: %d", wire)
+		}
+	}
+	return fields
+}
+
+func varint(t *testing.T, data []byte) int {
+	v, n := uvarint(data)
+	if n != len(data) {
+		t.Fatalf("invalid varint: %v", data)
+	}
+	return int(v)
+}
+
+func uvarint(data []byte) (uint64, int) {
+	var v uint64
+	for i, b := range data {
+		v |= uint64(b&0x7f) << (7 * i)
+		if b < 0x80 {
+			return v, i + 1
+		}
+	}
+	return v, len(data)
+}
@@ -0,0 +1,108 @@
+package repl
+
+import (
+	"bufio"
+	"io"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/debugger"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+type session struct {
+	engine  engine.Engine
+	out     io.Writer
+	scanner *bufio.Scanner
+	history []string
+
+	debugger *debugger.Debugger
+	console  *debugger.Console
+}
+
+var commands = map[string]func(s *session, args string){
+	"ast":    (*session).ast,
+	"debug":  (*session).debug,
+	"engine": (*session).switchEngine,
+	"help":   (*session).help,
+}
+
+func (s *session) command(line string) {
+	name, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+	command, ok := commands[name]
+	if !ok {
+		io.WriteString(s.out, "unknown command: :"+name+" (try :help)\n")
+		return
+	}
+	command(s, strings.TrimSpace(args))
+}
+
+func (s *session) help(args string) {
+	io.WriteString(s.out, ":ast [--format=sexpr|dot] <code>\tprint the macro-expanded syntax tree of <code>\n")
+	io.WriteString(s.out, ":debug\t\t\t\t\ttoggle stepping through each line in the debugger (eval only)\n")
+	io.WriteString(s.out, ":engine [eval|vm]\t\t\tshow or switch the execution engine (resets all bindings)\n")
+	io.WriteString(s.out, ":help\t\t\t\t\tlist the available commands\n")
+}
+
+func (s *session) ast(args string) {
+	format := "sexpr"
+	if rest, ok := strings.CutPrefix(args, "--format="); ok {
+		format, args, _ = strings.Cut(rest, " ")
+	}
+
+	p := parser.NewParser(args, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		printParserErrors(s.out, p.Errors())
+		return
+	}
+
+	macros := s.engine.Macros()
+	expanded, interrupt := evaluator.ExpandMacros(evaluator.DefineMacros(program, macros), macros)
+	if interrupt != nil {
+		io.WriteString(s.out, interrupt.Inspect()+"\n")
+		return
+	}
+	switch format {
+	case "sexpr":
+		io.WriteString(s.out, ast.EncodeSExpr(expanded))
+	case "dot":
+		io.WriteString(s.out, ast.EncodeDOT(expanded))
+	default:
+		io.WriteString(s.out, "unknown format: "+format+"\n")
+	}
+}
+
+func (s *session) switchEngine(args string) {
+	if args == "" {
+		io.WriteString(s.out, "engine: "+s.engine.Name()+"\n")
+		return
+	}
+
+	e, err := engine.New(args)
+	if err != nil {
+		io.WriteString(s.out, err.Error()+"\n")
+		return
+	}
+	s.engine = e
+	io.WriteString(s.out, "switched to engine: "+e.Name()+"\n")
+}
+
+func (s *session) debug(args string) {
+	if s.debugger != nil {
+		s.debugger, s.console = nil, nil
+		io.WriteString(s.out, "debugger off\n")
+		return
+	}
+
+	if _, ok := s.engine.(*engine.Evaluator); !ok {
+		io.WriteString(s.out, "the debugger needs the eval engine (try :engine eval)\n")
+		return
+	}
+	s.console = debugger.NewConsole(s.scanner, s.out)
+	s.debugger = debugger.New(s.console)
+	s.debugger.StopOnEntry = true
+	io.WriteString(s.out, "debugger on: each line stops before it runs (type help when stopped)\n")
+}
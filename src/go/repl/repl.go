@@ -2,11 +2,15 @@ package repl
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/ast"
-	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/debugger"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
 	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
 )
@@ -28,9 +32,12 @@ const MONKEY_FACE = `
 const PROMPT = "> "
 
 func Start(in io.Reader, out io.Writer) {
+	StartWithEngine(in, out, engine.NewEvaluator())
+}
+
+func StartWithEngine(in io.Reader, out io.Writer, e engine.Engine) {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment(nil)
-	macros := object.NewEnvironment(nil)
+	s := &session{engine: e, out: out, scanner: scanner}
 
 	for {
 		fmt.Fprintf(out, PROMPT)
@@ -40,27 +47,30 @@ func Start(in io.Reader, out io.Writer) {
 		}
 
 		line := scanner.Text()
-		p := parser.NewParser(line, false)
-
-		program := p.ParseProgram()
+		if strings.HasPrefix(line, ":") {
+			s.command(line[1:])
+			continue
+		}
 
-		expanded := evaluator.ExpandMacros(
-			evaluator.DefineMacros(program, macros), macros,
-		).(*ast.Program)
+		s.history = append(s.history, line)
+		p := parser.NewParserAtLine(line, len(s.history), false)
 
+		program := p.ParseProgram()
 		if len(p.Errors()) != 0 {
-			io.WriteString(out, MONKEY_FACE)
-			io.WriteString(out, "Woops! We ran into some monkey business here!\n")
-			io.WriteString(out, "parser errors:\n")
-			for _, msg := range p.Errors() {
-				io.WriteString(out, "\t"+msg+"\n")
-			}
+			printParserErrors(out, p.Errors())
 			continue
 		}
 
-		value, error := evaluator.Evaluate(expanded, env)
+		value, error := s.run(program)
 		if error != nil {
-			io.WriteString(out, error.Inspect()+"\n")
+			if error, ok := error.(*object.LimitError); ok && errors.Is(error, debugger.ErrQuit) {
+				continue
+			}
+			if error, ok := error.(*object.Error); ok && len(error.Frames) != 0 {
+				io.WriteString(out, error.Traceback(strings.Join(s.history, "\n")))
+			} else {
+				io.WriteString(out, error.Inspect()+"\n")
+			}
 			continue
 		}
 
@@ -69,3 +79,22 @@ func Start(in io.Reader, out io.Writer) {
 		}
 	}
 }
+
+// run runs program with the engine, under the debugger when it is on.
+func (s *session) run(program *ast.Program) (object.Object, object.Interruption) {
+	e, ok := s.engine.(*engine.Evaluator)
+	if !ok || s.debugger == nil {
+		return s.engine.Run(program)
+	}
+	s.console.Source = strings.Join(s.history, "\n")
+	return s.debugger.Run(context.Background(), e, program)
+}
+
+func printParserErrors(out io.Writer, errors []string) {
+	io.WriteString(out, MONKEY_FACE)
+	io.WriteString(out, "Woops! We ran into some monkey business here!\n")
+	io.WriteString(out, "parser errors:\n")
+	for _, msg := range errors {
+		io.WriteString(out, "\t"+msg+"\n")
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/coverage"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/optimizer"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/profiler"
+)
+
+func run(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	name := flags.String("engine", engine.Default, "execution engine: eval or vm")
+	timeout := flags.Duration("timeout", 0, "abort the script after this long (0 for no timeout)")
+	limits := object.DefaultLimits
+	flags.IntVar(&limits.MaxDepth, "max-depth", limits.MaxDepth, "maximum call depth (0 for no limit)")
+	flags.IntVar(&limits.MaxSteps, "max-steps", 0, "maximum evaluation steps (0 for no limit)")
+	flags.IntVar(&limits.MaxAllocations, "max-allocs", 0, "maximum allocated objects (0 for no limit)")
+	flags.IntVar(&limits.MaxBytes, "max-bytes", 0, "maximum allocated bytes (0 for no limit)")
+	flags.IntVar(&limits.MaxStringLength, "max-string", 0, "maximum string length (0 for no limit)")
+	flags.IntVar(&limits.MaxArraySize, "max-array", 0, "maximum array size (0 for no limit)")
+	level := flags.Int("O", 0, fmt.Sprintf("optimization level: 0-%d; at 2, inlined calls are left out of tracebacks", optimizer.MaxLevel))
+	names := flags.String("passes", "", "comma-separated optimization passes to run instead of a level: "+strings.Join(optimizer.Names(), ", "))
+	path := flags.String("path", os.Getenv("MONKEYPATH"), "module search path, searched after the directory of the script (eval only)")
+	profile := flags.String("profile", "", "write a pprof profile of the script's functions to this file (eval only)")
+	cover := &coverFlags{}
+	cover.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: monkey run [-engine=eval|vm] [-timeout=d] [-max-depth=n] [-max-steps=n] [-max-allocs=n] [-max-bytes=n] [-max-string=n] [-max-array=n] [-O=n] [-passes=p,...] [-path=dirs] [-profile=out.pprof] [-cover] [-coverprofile=out.lcov] [-coverhtml=out.html] file.mk")
+		return 2
+	}
+
+	e, err := engine.New(*name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	e.SetLimits(limits)
+	if eval, ok := e.(*engine.Evaluator); ok {
+		eval.SetModulePath(modulePath(flags.Arg(0), *path))
+	}
+
+	passes, err := selectPasses(*level, *names)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	e.SetPasses(passes)
+
+	src, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		fmt.Fprintln(os.Stderr, "parser errors:")
+		for _, msg := range p.Errors() {
+			fmt.Fprintln(os.Stderr, "\t"+msg)
+		}
+		return 1
+	}
+
+	if errs := evaluator.Check(program); len(errs) != 0 {
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "%s:%s: %s\n", flags.Arg(0), err.Position, err.Message)
+		}
+		return 1
+	}
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	var prof *profiler.Profiler
+	var covered *coverage.Profile
+	if *profile != "" || cover.enabled() {
+		eval, ok := e.(*engine.Evaluator)
+		if !ok {
+			fmt.Fprintln(os.Stderr, "profiling and coverage need the eval engine")
+			return 2
+		}
+		observers := object.Observers{}
+		if *profile != "" {
+			prof = profiler.New()
+			observers = append(observers, prof)
+		}
+		if cover.enabled() {
+			covered = coverage.New(flags.Arg(0), string(src), program)
+			observers = append(observers, covered)
+		}
+		eval.SetObserver(observers)
+		if prof != nil {
+			prof.Start()
+		}
+	}
+
+	_, interrupt := e.RunContext(ctx, program)
+	if prof != nil {
+		prof.Stop()
+		if err := writeProfile(prof, *profile, flags.Arg(0)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if covered != nil {
+		if err := cover.report(os.Stderr, []*coverage.Profile{covered}); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+
+	if interrupt != nil {
+		if err, ok := interrupt.(*object.Error); ok {
+			fmt.Fprint(os.Stderr, err.Traceback(string(src)))
+		} else {
+			fmt.Fprintln(os.Stderr, interrupt.Inspect())
+		}
+		return 1
+	}
+	return 0
+}
+
+// modulePath searches the directory of script for modules before the
+// directories of list.
+func modulePath(script string, list string) []string {
+	return append([]string{filepath.Dir(script)}, evaluator.SearchPath(list)...)
+}
+
+func writeProfile(p *profiler.Profiler, path string, filename string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteProfile(f, filename); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func selectPasses(level int, names string) ([]optimizer.Pass, error) {
+	if names != "" {
+		return optimizer.Lookup(strings.Split(names, ",")...)
+	}
+	return optimizer.Level(level)
+}
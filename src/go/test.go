@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/coverage"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/engine"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+// test runs each test script in a fresh engine; a script passes when it
+// runs to the end without an error.
+func test(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	name := flags.String("engine", engine.Default, "execution engine: eval or vm")
+	timeout := flags.Duration("timeout", 0, "abort each script after this long (0 for no timeout)")
+	path := flags.String("path", os.Getenv("MONKEYPATH"), "module search path, searched after the directory of each script (eval only)")
+	cover := &coverFlags{}
+	cover.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if _, err := engine.New(*name); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	files, err := testFiles(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: monkey test [-engine=eval|vm] [-timeout=d] [-path=dirs] [-cover] [-coverprofile=out.lcov] [-coverhtml=out.html] [file.mk | dir]...")
+		fmt.Fprintln(os.Stderr, "no *_test.mk files found")
+		return 2
+	}
+	if cover.enabled() && *name != "eval" {
+		fmt.Fprintln(os.Stderr, "coverage needs the eval engine")
+		return 2
+	}
+
+	status := 0
+	profiles := []*coverage.Profile{}
+	for _, file := range files {
+		start := time.Now()
+		profile, err := runTest(file, *name, *timeout, *path, cover.enabled())
+		if profile != nil {
+			profiles = append(profiles, profile)
+		}
+		if err != "" {
+			status = 1
+			fmt.Printf("FAIL\t%s\t%.3fs\n", file, time.Since(start).Seconds())
+			fmt.Print(indent(err))
+			continue
+		}
+		fmt.Printf("ok  \t%s\t%.3fs\n", file, time.Since(start).Seconds())
+	}
+
+	if cover.enabled() {
+		if err := cover.report(os.Stdout, profiles); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return status
+}
+
+// testFiles expands directories into the *_test.mk files beneath them;
+// files named explicitly are run whatever their name.
+func testFiles(paths []string) ([]string, error) {
+	files := []string{}
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), "_test.mk") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// runTest runs file and returns its coverage when cover is set, and what
+// went wrong when it fails.
+func runTest(file string, name string, timeout time.Duration, path string, cover bool) (*coverage.Profile, string) {
+	src, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err.Error() + "\n"
+	}
+
+	p := parser.NewParser(string(src), false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		return nil, "parser errors:\n\t" + strings.Join(p.Errors(), "\n\t") + "\n"
+	}
+
+	if errs := evaluator.Check(program); len(errs) != 0 {
+		out := strings.Builder{}
+		for _, err := range errs {
+			fmt.Fprintf(&out, "%s:%s: %s\n", file, err.Position, err.Message)
+		}
+		return nil, out.String()
+	}
+
+	e, err := engine.New(name)
+	if err != nil {
+		return nil, err.Error() + "\n"
+	}
+	if eval, ok := e.(*engine.Evaluator); ok {
+		eval.SetModulePath(modulePath(file, path))
+	}
+
+	var profile *coverage.Profile
+	if cover {
+		profile = coverage.New(file, string(src), program)
+		e.(*engine.Evaluator).SetObserver(profile)
+	}
+
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	_, interrupt := e.RunContext(ctx, program)
+	if interrupt != nil {
+		if err, ok := interrupt.(*object.Error); ok {
+			return profile, err.Traceback(string(src))
+		}
+		return profile, interrupt.Inspect() + "\n"
+	}
+	return profile, ""
+}
+
+func indent(s string) string {
+	lines := strings.SplitAfter(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+	return strings.Join(lines, "")
+}
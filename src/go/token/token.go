@@ -1,20 +1,43 @@
 package token
 
+import "fmt"
+
 type TokenType int
 
 type Token struct {
-	Type    TokenType
-	Literal string
+	Type     TokenType
+	Literal  string
+	Position Position
+}
+
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
 }
 
 const (
 	ILLEGAL TokenType = iota
 
 	EOF
+	COMMENT
 
 	// Identifiers + literals
-	IDENT  // add, foobar, x, y, ...
-	NUMBER // 1343456
+	IDENT   // add, foobar, x, y, ...
+	INT     // 1343456
+	FLOAT   // 3.14
+	DECIMAL // 12.50d
 	STRING
 
 	// Operators
@@ -24,6 +47,7 @@ const (
 	BANG
 	STAR
 	SLASH
+	PERCENT
 	LT
 	GT
 
@@ -53,13 +77,19 @@ const (
 	AND
 	OR
 	MACRO
+	YIELD
+	IMPORT
+	EXPORT
 )
 
 var tokens = [...]string{
 	ILLEGAL: "ILLEGAL",
 	EOF:     "EOF",
+	COMMENT: "COMMENT",
 	IDENT:   "IDENT",
-	NUMBER:  "NUMBER",
+	INT:     "INT",
+	FLOAT:   "FLOAT",
+	DECIMAL: "DECIMAL",
 	STRING:  "STRING",
 	ASSIGN:  "ASSIGN",
 	PLUS:    "PLUS",
@@ -67,6 +97,7 @@ var tokens = [...]string{
 	BANG:    "BANG",
 	STAR:    "STAR",
 	SLASH:   "SLASH",
+	PERCENT: "PERCENT",
 	LT:      "LT",
 	GT:      "GT",
 	EQ:      "EQ",
@@ -91,6 +122,9 @@ var tokens = [...]string{
 	OR:      "OR",
 	AND:     "AND",
 	MACRO:   "MACRO",
+	YIELD:   "YIELD",
+	IMPORT:  "IMPORT",
+	EXPORT:  "EXPORT",
 }
 
 var keywords = map[string]TokenType{
@@ -105,6 +139,9 @@ var keywords = map[string]TokenType{
 	"or":     OR,
 	"and":    AND,
 	"macro":  MACRO,
+	"yield":  YIELD,
+	"import": IMPORT,
+	"export": EXPORT,
 }
 
 func LookupIdent(ident string) TokenType {
@@ -114,6 +151,15 @@ func LookupIdent(ident string) TokenType {
 	return IDENT
 }
 
+func Lookup(name string) (TokenType, bool) {
+	for tt, tok := range tokens {
+		if tok == name {
+			return TokenType(tt), true
+		}
+	}
+	return ILLEGAL, false
+}
+
 func (tt TokenType) String() string {
 	return tokens[tt]
 }
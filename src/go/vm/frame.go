@@ -0,0 +1,35 @@
+package vm
+
+import (
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/code"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/token"
+)
+
+type Frame struct {
+	fn          *object.Function
+	cells       []*object.Cell
+	ip          int
+	basePointer int
+}
+
+func NewFrame(fn *object.Function, basePointer int) *Frame {
+	frame := &Frame{fn: fn, ip: -1, basePointer: basePointer}
+	if n := fn.Compiled.NumCells; n > 0 {
+		frame.cells = make([]*object.Cell, n)
+		for i := range frame.cells {
+			frame.cells[i] = &object.Cell{}
+		}
+	}
+	return frame
+}
+
+func (f *Frame) Instructions() code.Instructions {
+	return f.fn.Compiled.Instructions
+}
+
+// Position returns where the instruction the frame is executing was
+// compiled from.
+func (f *Frame) Position() token.Position {
+	return f.fn.Compiled.SourceMap.Lookup(f.ip)
+}
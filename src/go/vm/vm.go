@@ -0,0 +1,476 @@
+package vm
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/code"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/compiler"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+)
+
+const (
+	StackSize    = 2048
+	MaxStackSize = 1 << 20
+	GlobalsSize  = 65536
+)
+
+type VM struct {
+	constants   []object.Object
+	globals     []object.Object
+	globalNames []string
+
+	stack []object.Object
+	sp    int
+
+	frames      []*Frame
+	framesIndex int
+
+	env  *object.Environment
+	last object.Object
+}
+
+func New(bytecode *compiler.Bytecode) *VM {
+	return NewWithGlobalsStore(bytecode, make([]object.Object, GlobalsSize))
+}
+
+func NewWithGlobalsStore(bytecode *compiler.Bytecode, globals []object.Object) *VM {
+	main := &object.Function{
+		Compiled: &object.CompiledFunction{Instructions: bytecode.Instructions, SourceMap: bytecode.SourceMap},
+	}
+
+	frames := make([]*Frame, 1, 64)
+	frames[0] = NewFrame(main, 0)
+
+	return &VM{
+		constants:   bytecode.Constants,
+		globals:     globals,
+		globalNames: bytecode.Globals,
+		stack:       make([]object.Object, StackSize),
+		frames:      frames,
+		framesIndex: 1,
+		env:         object.NewEnvironment(nil),
+	}
+}
+
+func (vm *VM) SetLimits(limits object.Limits) {
+	vm.env.Runtime.Limits = limits
+}
+
+func (vm *VM) LastPoppedStackElem() object.Object {
+	return vm.last
+}
+
+func (vm *VM) Run() (object.Object, object.Interruption) {
+	return vm.RunContext(context.Background())
+}
+
+func (vm *VM) RunContext(ctx context.Context) (object.Object, object.Interruption) {
+	runtime := vm.env.Runtime
+	runtime.Context = ctx
+	runtime.Reset()
+	if err := runtime.Cancelled(); err != nil {
+		return nil, err
+	}
+
+	value, interrupt := vm.run(runtime)
+	if err, ok := interrupt.(*object.Error); ok {
+		if !err.Position.IsValid() {
+			err.Position = vm.currentFrame().Position()
+		}
+		if err.Frames == nil {
+			err.Frames = vm.callFrames()
+		}
+	}
+	return value, interrupt
+}
+
+func (vm *VM) run(runtime *object.Runtime) (object.Object, object.Interruption) {
+	for {
+		if err := runtime.Step(); err != nil {
+			return nil, err
+		}
+
+		frame := vm.currentFrame()
+		ins := frame.Instructions()
+		if frame.ip >= len(ins)-1 {
+			return vm.last, nil
+		}
+
+		frame.ip += 1
+		ip := frame.ip
+		op := code.Opcode(ins[ip])
+
+		var interrupt object.Interruption
+		switch op {
+		case code.OpConstant:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			interrupt = vm.push(vm.constants[index])
+		case code.OpPop:
+			vm.last = vm.pop()
+		case code.OpDup:
+			interrupt = vm.push(vm.stack[vm.sp-1])
+		case code.OpTrue:
+			interrupt = vm.push(evaluator.TRUE)
+		case code.OpFalse:
+			interrupt = vm.push(evaluator.FALSE)
+		case code.OpNull:
+			interrupt = vm.push(evaluator.NULL)
+		case code.OpAdd, code.OpSub, code.OpMul, code.OpDiv, code.OpEqual, code.OpNotEqual, code.OpGreaterThan, code.OpLessThan:
+			interrupt = vm.executeBinary(op)
+		case code.OpBinary:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			right := vm.pop()
+			left := vm.pop()
+			interrupt = vm.allocate(evaluator.Binary(string(vm.constants[index].(object.String)), left, right))
+		case code.OpMinus:
+			right := vm.pop()
+			if number, ok := right.(object.Integer); ok && number != math.MinInt64 {
+				interrupt = vm.push(-number)
+			} else {
+				interrupt = vm.result(evaluator.Unary("-", right))
+			}
+		case code.OpBang:
+			if evaluator.IsTruthy(vm.pop()) {
+				interrupt = vm.push(evaluator.FALSE)
+			} else {
+				interrupt = vm.push(evaluator.TRUE)
+			}
+		case code.OpJump:
+			frame.ip = int(code.ReadUint16(ins[ip+1:])) - 1
+		case code.OpJumpNotTruthy:
+			target := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			if !evaluator.IsTruthy(vm.pop()) {
+				frame.ip = target - 1
+			}
+		case code.OpJumpTruthy, code.OpJumpFalsy:
+			target := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			if bool(evaluator.IsTruthy(vm.stack[vm.sp-1])) == (op == code.OpJumpTruthy) {
+				frame.ip = target - 1
+			} else {
+				vm.pop()
+			}
+		case code.OpGetGlobal:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			value := vm.globals[index]
+			if value == nil {
+				builtin, found := evaluator.LookupBuiltin(vm.globalNames[index])
+				if !found {
+					return nil, unknownIdentifier(vm.globalNames[index])
+				}
+				value = builtin
+			}
+			interrupt = vm.push(value)
+		case code.OpSetGlobal:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			vm.globals[index] = vm.pop()
+		case code.OpAssignGlobal:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			if vm.globals[index] == nil {
+				return nil, unknownIdentifier(vm.globalNames[index])
+			}
+			vm.globals[index] = vm.pop()
+		case code.OpGetLocal:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			value := vm.stack[frame.basePointer+int(index)]
+			if value == nil {
+				return nil, unknownIdentifier(frame.fn.Compiled.Locals[index])
+			}
+			interrupt = vm.push(value)
+		case code.OpSetLocal:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			vm.stack[frame.basePointer+int(index)] = vm.pop()
+		case code.OpGetCell:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			value := frame.cells[index].Value
+			if value == nil {
+				return nil, unknownIdentifier(frame.fn.Compiled.Cells[index])
+			}
+			interrupt = vm.push(value)
+		case code.OpSetCell:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			frame.cells[index].Value = vm.pop()
+		case code.OpGetFree:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			value := frame.fn.Free[index].Value
+			if value == nil {
+				return nil, unknownIdentifier(frame.fn.Compiled.Captures[index].Name)
+			}
+			interrupt = vm.push(value)
+		case code.OpSetFree:
+			index := code.ReadUint8(ins[ip+1:])
+			frame.ip += 1
+			frame.fn.Free[index].Value = vm.pop()
+		case code.OpArray:
+			n := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			elements := make([]object.Object, n)
+			copy(elements, vm.stack[vm.sp-n:vm.sp])
+			vm.sp -= n
+			interrupt = vm.allocate(&object.Array{Elements: elements}, nil)
+		case code.OpHash:
+			n := int(code.ReadUint16(ins[ip+1:]))
+			frame.ip += 2
+			hash, err := vm.buildHash(vm.sp-2*n, vm.sp)
+			if err != nil {
+				return nil, err
+			}
+			vm.sp -= 2 * n
+			interrupt = vm.allocate(hash, nil)
+		case code.OpIndex:
+			subscript := vm.pop()
+			base := vm.pop()
+			interrupt = vm.result(evaluator.Index(base, subscript))
+		case code.OpSetIndex:
+			subscript := vm.pop()
+			base := vm.pop()
+			value := vm.pop()
+			interrupt = vm.result(evaluator.SetIndex(vm.env.Runtime, base, subscript, value))
+		case code.OpClosure:
+			index := code.ReadUint16(ins[ip+1:])
+			frame.ip += 2
+			interrupt = vm.allocate(vm.closure(frame, vm.constants[index].(*object.CompiledFunction)), nil)
+		case code.OpCall:
+			n := int(code.ReadUint8(ins[ip+1:]))
+			frame.ip += 1
+			interrupt = vm.call(n)
+		case code.OpReturnValue:
+			value := vm.pop()
+			frame := vm.popFrame()
+			if vm.framesIndex == 0 {
+				return value, nil
+			}
+			vm.sp = frame.basePointer - 1
+			interrupt = vm.push(value)
+		case code.OpQuote:
+			index := code.ReadUint16(ins[ip+1:])
+			n := int(code.ReadUint8(ins[ip+3:]))
+			frame.ip += 3
+			template := vm.constants[index].(*compiler.Template)
+			node := template.Fill(vm.stack[vm.sp-n:vm.sp], evaluator.ToNode)
+			vm.sp -= n
+			interrupt = vm.push(&object.Quote{Node: node})
+		case code.OpError:
+			index := code.ReadUint16(ins[ip+1:])
+			return nil, &object.Error{Message: string(vm.constants[index].(object.String))}
+		default:
+			panic(fmt.Errorf("unexpected opcode: %d", op))
+		}
+
+		if interrupt != nil {
+			return nil, interrupt
+		}
+	}
+}
+
+func (vm *VM) executeBinary(op code.Opcode) object.Interruption {
+	right := vm.pop()
+	left := vm.pop()
+
+	// integers that cannot overflow take a fast path; everything else goes
+	// through the evaluator's rules
+	if l, ok := left.(object.Integer); ok {
+		if r, ok := right.(object.Integer); ok {
+			switch op {
+			case code.OpAdd:
+				if sum := l + r; (sum > l) == (r > 0) {
+					return vm.push(sum)
+				}
+			case code.OpSub:
+				if difference := l - r; (difference < l) == (r > 0) {
+					return vm.push(difference)
+				}
+			case code.OpEqual:
+				return vm.push(toBoolean(l == r))
+			case code.OpNotEqual:
+				return vm.push(toBoolean(l != r))
+			case code.OpGreaterThan:
+				return vm.push(toBoolean(l > r))
+			case code.OpLessThan:
+				return vm.push(toBoolean(l < r))
+			}
+		}
+	}
+
+	return vm.allocate(evaluator.Binary(operators[op], left, right))
+}
+
+var operators = map[code.Opcode]string{
+	code.OpAdd:         "+",
+	code.OpSub:         "-",
+	code.OpMul:         "*",
+	code.OpDiv:         "/",
+	code.OpEqual:       "==",
+	code.OpNotEqual:    "!=",
+	code.OpGreaterThan: ">",
+	code.OpLessThan:    "<",
+}
+
+func (vm *VM) buildHash(start, end int) (object.Object, object.Interruption) {
+	hash := &object.Hash{Pairs: map[object.HashKey]object.HashPair{}}
+	for i := start; i < end; i += 2 {
+		key := vm.stack[i]
+		hashable, ok := key.(object.Hashable)
+		if !ok {
+			return nil, &object.Error{Message: fmt.Sprintf("unknown operator: HASH[%s]", key.Type())}
+		}
+		hash.Pairs[hashable.HashKey()] = object.HashPair{Key: key, Value: vm.stack[i+1]}
+	}
+	return hash, nil
+}
+
+func (vm *VM) closure(frame *Frame, compiled *object.CompiledFunction) *object.Function {
+	free := make([]*object.Cell, len(compiled.Captures))
+	for i, capture := range compiled.Captures {
+		if capture.Cell {
+			free[i] = frame.cells[capture.Index]
+		} else {
+			free[i] = frame.fn.Free[capture.Index]
+		}
+	}
+	return &object.Function{Name: compiled.Name, Literal: compiled.Literal, Compiled: compiled, Free: free}
+}
+
+func (vm *VM) call(n int) object.Interruption {
+	switch callee := vm.stack[vm.sp-1-n].(type) {
+	case *object.Function:
+		if callee.Compiled == nil {
+			return &object.Error{Message: fmt.Sprintf("unknown operator: %s()", callee.Type())}
+		}
+
+		compiled := callee.Compiled
+		if n < compiled.NumParameters {
+			return &object.Error{Message: fmt.Sprintf("wrong number of arguments: want=%d, got=%d", compiled.NumParameters, n)}
+		}
+		if limit := vm.env.Runtime.Limits.MaxDepth; limit > 0 && vm.framesIndex > limit {
+			return vm.depthExceeded(limit)
+		}
+
+		basePointer := vm.sp - n
+		top := basePointer + compiled.NumLocals
+		if !vm.reserve(top) {
+			return &object.Error{Message: "stack overflow"}
+		}
+		for i := basePointer + compiled.NumParameters; i < top; i += 1 {
+			vm.stack[i] = nil
+		}
+
+		vm.pushFrame(NewFrame(callee, basePointer))
+		vm.sp = top
+		return nil
+	case *object.BuiltinFunction:
+		args := make([]object.Object, n)
+		copy(args, vm.stack[vm.sp-n:vm.sp])
+		vm.sp -= n + 1
+		return vm.result(callee.Fn(vm.env, args...))
+	case *object.BuiltinMacro:
+		args := make([]object.Object, n)
+		copy(args, vm.stack[vm.sp-n:vm.sp])
+		vm.sp -= n + 1
+		return vm.result(callee.Fn(object.NewEnvironment(nil), args...))
+	default:
+		return &object.Error{Message: fmt.Sprintf("unknown operator: %s()", callee.Type())}
+	}
+}
+
+func (vm *VM) result(value object.Object, interrupt object.Interruption) object.Interruption {
+	if interrupt != nil {
+		return interrupt
+	}
+	return vm.push(value)
+}
+
+func (vm *VM) allocate(value object.Object, interrupt object.Interruption) object.Interruption {
+	if interrupt != nil {
+		return interrupt
+	}
+	if err := vm.env.Runtime.Allocate(value); err != nil {
+		return err
+	}
+	return vm.push(value)
+}
+
+func (vm *VM) push(o object.Object) object.Interruption {
+	if !vm.reserve(vm.sp + 1) {
+		return &object.Error{Message: "stack overflow"}
+	}
+	vm.stack[vm.sp] = o
+	vm.sp += 1
+	return nil
+}
+
+func (vm *VM) reserve(size int) bool {
+	if size <= len(vm.stack) {
+		return true
+	}
+	if size > MaxStackSize {
+		return false
+	}
+	stack := make([]object.Object, min(max(2*len(vm.stack), size), MaxStackSize))
+	copy(stack, vm.stack[:vm.sp])
+	vm.stack = stack
+	return true
+}
+
+func (vm *VM) depthExceeded(limit int) *object.Error {
+	return &object.Error{Message: fmt.Sprintf("maximum call depth %d exceeded", limit), Frames: vm.callFrames()}
+}
+
+// callFrames lists the functions being called, outermost first, each with
+// the position of the call in its caller.
+func (vm *VM) callFrames() []object.Frame {
+	frames := make([]object.Frame, 0, vm.framesIndex-1)
+	for i, frame := range vm.frames[1:vm.framesIndex] {
+		frames = append(frames, object.Frame{Function: frame.fn, Position: vm.frames[i].Position()})
+	}
+	return frames
+}
+
+func (vm *VM) pop() object.Object {
+	o := vm.stack[vm.sp-1]
+	vm.sp -= 1
+	return o
+}
+
+func (vm *VM) currentFrame() *Frame {
+	return vm.frames[vm.framesIndex-1]
+}
+
+func (vm *VM) pushFrame(f *Frame) {
+	if vm.framesIndex < len(vm.frames) {
+		vm.frames[vm.framesIndex] = f
+	} else {
+		vm.frames = append(vm.frames, f)
+	}
+	vm.framesIndex += 1
+}
+
+func (vm *VM) popFrame() *Frame {
+	vm.framesIndex -= 1
+	return vm.frames[vm.framesIndex]
+}
+
+func toBoolean(value bool) object.Boolean {
+	if value {
+		return evaluator.TRUE
+	}
+	return evaluator.FALSE
+}
+
+func unknownIdentifier(name string) *object.Error {
+	return &object.Error{Message: "unknown identifier: " + name}
+}
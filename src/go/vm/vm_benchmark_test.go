@@ -0,0 +1,30 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+const fibonacci = `
+	let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } };
+	fib(20);`
+
+func BenchmarkRun(b *testing.B) {
+	for b.Loop() {
+		if _, interrupt := run(b, fibonacci); interrupt != nil {
+			b.Fatalf("Run() ==> unexpected interrupt: %s", interrupt.Inspect())
+		}
+	}
+}
+
+func BenchmarkEvaluate(b *testing.B) {
+	program := parser.NewParser(fibonacci, false).ParseProgram()
+	for b.Loop() {
+		if _, interrupt := evaluator.Evaluate(program, object.NewEnvironment(nil)); interrupt != nil {
+			b.Fatalf("Evaluate() ==> unexpected interrupt: %s", interrupt.Inspect())
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package vm
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/compiler"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/evaluator"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/object"
+	"github.com/ixione-projects/writing-an-interpreter-in-go/src/go/parser"
+)
+
+func run(tb testing.TB, input string) (object.Object, object.Interruption) {
+	p := parser.NewParser(input, false)
+	program := p.ParseProgram()
+	if len(p.Errors()) != 0 {
+		tb.Fatalf("len(p.Errors()) ==> expected: <0> but was: <%d> (%v)", len(p.Errors()), p.Errors())
+	}
+
+	c := compiler.New()
+	if err := c.Compile(program); err != nil {
+		tb.Fatalf("Compile() ==> unexpected error: %s", err)
+	}
+	return New(c.Bytecode()).Run()
+}
+
+func inspect(value object.Object, interrupt object.Interruption) string {
+	if interrupt != nil {
+		return interrupt.Inspect()
+	}
+	if value == nil {
+		return "<nil>"
+	}
+	return value.Inspect()
+}
+
+func TestRunMatchesEvaluator(t *testing.T) {
+	tests := []string{
+		`let x = 1; let f = fn() { x }; let x = 2; f()`,
+		`let f = fn() { g() }; let g = fn() { 42 }; f()`,
+		`let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(15)`,
+		`let outer = fn() { let fib = fn(n) { if (n < 2) { n } else { fib(n - 1) + fib(n - 2) } }; fib(10) }; outer()`,
+		`let f = fn() { let h = fn() { y }; let y = 2; h() }; f()`,
+		`let counter = fn() { let n = 0; let inc = fn() { n }; n = 5; inc() }; counter()`,
+		`let adder = fn(a) { fn(b) { fn(c) { a + b + c } } }; adder(1)(2)(3)`,
+		`let xs = [1, 2]; xs[3] = 4; xs`,
+		`let h = {}; h["a"] = 1; h["a"] + 1`,
+		`let x = 1; x = x + 1; x`,
+		`y = 1`,
+		`let f = fn(a) { let a = a * 2; a }; f(4)`,
+		`if (true) { let z = 3 }`,
+		`let x = 5;`,
+		`1; 2; let z = 3;`,
+		`true or false`,
+		`len`,
+		`let len = fn(x) { 0 }; len("abc")`,
+		`"a" == "a"`,
+		`[1] == [1]`,
+		`null == null`,
+		`!0`,
+		`!""`,
+		`-"a"`,
+		`quote(unquote(1, 2))`,
+		`let f = fn(x) { x * 2 }; quote(unquote(f(3)) + unquote(f))`,
+		`unquote(1)`,
+		`quote()`,
+		`{[1]: 2}`,
+	}
+
+	for i, input := range tests {
+		p := parser.NewParser(input, false)
+		program := p.ParseProgram()
+		expected := inspect(evaluator.Evaluate(program, object.NewEnvironment(nil)))
+		actual := inspect(run(t, input))
+		if expected != actual {
+			t.Errorf("test[%d] - Run() ==> expected: <%s> but was: <%s>", i, expected, actual)
+		}
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{`let f = fn(x, y) { x }; f(1)`, "wrong number of arguments: want=2, got=1"},
+		{`let f = fn() { f() }; f()`, "maximum call depth 10000 exceeded"},
+		{`1()`, "unknown operator: INTEGER()"},
+	}
+
+	for i, test := range tests {
+		_, interrupt := run(t, test.input)
+		err, ok := interrupt.(*object.Error)
+		if !ok {
+			t.Fatalf("test[%d] - Run() ==> expected: <*object.Error> but was: <%T>", i, interrupt)
+		}
+		if test.message != err.Message {
+			t.Errorf("test[%d] - Run() ==> expected: <%s> but was: <%s>", i, test.message, err.Message)
+		}
+	}
+}
+
+func TestGlobalsStore(t *testing.T) {
+	globals := make([]object.Object, GlobalsSize)
+	symbols := compiler.NewSymbolTable()
+	constants := []object.Object{}
+
+	var result object.Object
+	for _, line := range []string{`let x = 2;`, `let double = fn(n) { n * x };`, `double(21)`} {
+		c := compiler.NewWithState(symbols, constants)
+		if err := c.Compile(parser.NewParser(line, false).ParseProgram()); err != nil {
+			t.Fatalf("Compile() ==> unexpected error: %s", err)
+		}
+		bytecode := c.Bytecode()
+		constants = bytecode.Constants
+
+		value, interrupt := NewWithGlobalsStore(bytecode, globals).Run()
+		if interrupt != nil {
+			t.Fatalf("Run() ==> unexpected interrupt: %s", interrupt.Inspect())
+		}
+		result = value
+	}
+
+	if result.Inspect() != "42" {
+		t.Fatalf("Run() ==> expected: <42> but was: <%s>", result.Inspect())
+	}
+}
+
+func TestRunContextLimits(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		input  string
+		ctx    context.Context
+		limits object.Limits
+		limit  object.Limit
+	}{
+		{"let f = fn() { f() }; f();", cancelled, object.Limits{}, object.CANCELLED},
+		{"let f = fn() { f() }; f();", context.Background(), object.Limits{MaxSteps: 1000}, object.STEP_LIMIT},
+		{"let f = fn(a) { f([a]) }; f(1);", context.Background(), object.Limits{MaxAllocations: 100}, object.ALLOCATION_LIMIT},
+		{"let f = fn(s) { f(s + s) }; f(\"a\");", context.Background(), object.Limits{MaxBytes: 1 << 16}, object.MEMORY_LIMIT},
+		{"let f = fn(s) { f(s + s) }; f(\"a\");", context.Background(), object.Limits{MaxStringLength: 1000}, object.STRING_LENGTH_LIMIT},
+		{"let f = fn(a) { f(push(a, 1)) }; f([]);", context.Background(), object.Limits{MaxArraySize: 100}, object.ARRAY_SIZE_LIMIT},
+		{"let a = []; a[5000000] = 1;", context.Background(), object.Limits{MaxArraySize: 10}, object.ARRAY_SIZE_LIMIT},
+		{"let a = []; a[5000000] = 1;", context.Background(), object.Limits{MaxBytes: 1000}, object.MEMORY_LIMIT},
+		{"let h = {}; let f = fn(n) { h[n] = n; f(n + 1) }; f(0);", context.Background(), object.Limits{MaxBytes: 1 << 12}, object.MEMORY_LIMIT},
+	}
+
+	for i, test := range tests {
+		c := compiler.New()
+		if err := c.Compile(parser.NewParser(test.input, false).ParseProgram()); err != nil {
+			t.Fatalf("test[%d] - Compile() ==> unexpected error: %s", i, err)
+		}
+
+		machine := New(c.Bytecode())
+		machine.SetLimits(test.limits)
+		_, interrupt := machine.RunContext(test.ctx)
+
+		err, ok := interrupt.(*object.LimitError)
+		if !ok {
+			t.Errorf("test[%d] - RunContext() ==> expected: <*object.LimitError> but was: <%T>", i, interrupt)
+			continue
+		}
+		if test.limit != err.Limit {
+			t.Errorf("test[%d] - LimitError.Limit ==> expected: <%s> but was: <%s>", i, test.limit, err.Limit)
+		}
+	}
+}
+
+func TestRunErrorFrames(t *testing.T) {
+	tests := []struct {
+		input    string
+		frames   []string
+		calls    []string
+		position string
+	}{
+		{"1 + true", []string{}, []string{}, "1:3"},
+		{"let f = fn(x) { len(x) }; let g = fn(x) { 1 + f(x) }; g(1);", []string{"g", "f"}, []string{"1:55", "1:47"}, "1:17"},
+		{"let apply = fn(g) { 1 + g() }; apply(fn() { 1 + true });", []string{"apply", "<anonymous>"}, []string{"1:32", "1:25"}, "1:47"},
+		{"let f = fn() {\n  nope\n};\nf();", []string{"f"}, []string{"4:1"}, "2:3"},
+	}
+
+	for i, test := range tests {
+		_, interrupt := run(t, test.input)
+		err, ok := interrupt.(*object.Error)
+		if !ok {
+			t.Fatalf("test[%d] - Run() ==> expected: <*object.Error> but was: <%T>", i, interrupt)
+		}
+
+		frames := []string{}
+		calls := []string{}
+		for _, frame := range err.Frames {
+			frames = append(frames, frame.Name())
+			calls = append(calls, frame.Position.String())
+		}
+		if !slices.Equal(test.frames, frames) {
+			t.Errorf("test[%d] - Error.Frames ==> expected: <%v> but was: <%v>", i, test.frames, frames)
+		}
+		if !slices.Equal(test.calls, calls) {
+			t.Errorf("test[%d] - Frame.Position ==> expected: <%v> but was: <%v>", i, test.calls, calls)
+		}
+		if test.position != err.Position.String() {
+			t.Errorf("test[%d] - Error.Position ==> expected: <%s> but was: <%s>", i, test.position, err.Position)
+		}
+	}
+}